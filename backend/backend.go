@@ -16,10 +16,14 @@
 package backend
 
 import (
+	"crypto/sha256"
+	"errors"
+	"hash"
 	"io"
 	"os"
 
 	"github.com/consensys/gnark/backend/hint"
+	"golang.org/x/crypto/sha3"
 )
 
 // ID represent a unique ID for a proving scheme
@@ -48,6 +52,38 @@ func (id ID) String() string {
 	}
 }
 
+// HashID identifies the hash function used to derive the Fiat-Shamir challenges of a proof
+type HashID uint8
+
+const (
+	SHA256    HashID = iota // default
+	KECCAK256               // legacy keccak256, as computed by the EVM opcode
+)
+
+// New returns a new hash.Hash computing the hash function id, or nil if id is unknown
+func (id HashID) New() hash.Hash {
+	switch id {
+	case SHA256:
+		return sha256.New()
+	case KECCAK256:
+		return sha3.NewLegacyKeccak256()
+	default:
+		return nil
+	}
+}
+
+// String returns the string representation of a hash function
+func (id HashID) String() string {
+	switch id {
+	case SHA256:
+		return "sha256"
+	case KECCAK256:
+		return "keccak256"
+	default:
+		return "unknown"
+	}
+}
+
 // NewProverOption returns a default ProverOption with given options applied
 func NewProverOption(opts ...func(opt *ProverOption) error) (ProverOption, error) {
 	opt := ProverOption{LoggerOut: os.Stdout, HintFunctions: hint.GetAll()}
@@ -92,3 +128,32 @@ func WithOutput(w io.Writer) func(opt *ProverOption) error {
 		return nil
 	}
 }
+
+// NewSetupOption returns a default SetupOption with given options applied
+func NewSetupOption(opts ...func(opt *SetupOption) error) (SetupOption, error) {
+	opt := SetupOption{TranscriptHash: SHA256}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupOption{}, err
+		}
+	}
+	return opt, nil
+}
+
+// SetupOption parametrizes calls to plonk.Setup(...)
+type SetupOption struct {
+	TranscriptHash HashID // default to SHA256
+}
+
+// WithTranscriptHash is a Setup option that specifies the hash function used by the prover and the
+// verifier to derive the challenges. It is recorded in the verifying key, so that the proofs are
+// verified with the hash they were produced with. The solidity verifier of PlonK requires KECCAK256.
+func WithTranscriptHash(id HashID) func(opt *SetupOption) error {
+	return func(opt *SetupOption) error {
+		if id.New() == nil {
+			return errors.New("unknown transcript hash")
+		}
+		opt.TranscriptHash = id
+		return nil
+	}
+}
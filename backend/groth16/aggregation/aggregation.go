@@ -0,0 +1,249 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregation implements the aggregation of Groth16 proofs of the same circuit
+// into a proof of logarithmic size (SnarkPack).
+//
+// It is implemented for BN254 and BLS12-381.
+//
+// See also
+//
+// https://eprint.iacr.org/2021/529.pdf
+package aggregation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+
+	curve_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	curve_bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+
+	aggregation_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16/aggregation"
+	aggregation_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16/aggregation"
+
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+)
+
+var (
+	errUnsupportedCurve = errors.New("aggregation is only implemented for BN254 and BLS12-381")
+	errMixedCurves      = errors.New("powers of tau of different curves")
+)
+
+type aggregationObject interface {
+	gnarkio.WriterRawTo
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// SRS represents the structured reference string of the aggregation scheme
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type SRS interface {
+	aggregationObject
+}
+
+// VerifierSRS represents the part of the SRS needed by Verify (see NewVerifierSRS)
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type VerifierSRS interface {
+	aggregationObject
+}
+
+// Proof represents an aggregated proof
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type Proof interface {
+	aggregationObject
+}
+
+// NewSRS returns a new SRS supporting the aggregation of up to size proofs, using a and b as secrets
+//
+// it is meant for test purposes, a and b must be discarded (toxic waste); see NewSRSFromPowersOfTau
+func NewSRS(curveID ecc.ID, size uint64, a, b *big.Int) (SRS, error) {
+	switch curveID {
+	case ecc.BN254:
+		return aggregation_bn254.NewSRS(size, a, b)
+	case ecc.BLS12_381:
+		return aggregation_bls12381.NewSRS(size, a, b)
+	default:
+		return nil, errUnsupportedCurve
+	}
+}
+
+// NewSRSFromPowersOfTau returns the SRS made of the powers of two secrets a and b, output by two
+// independent powers of tau ceremonies: g1A[i] = [aⁱ]1, g2A[i] = [aⁱ]2, and likewise for b.
+//
+// The points are []bn254.G1Affine and []bn254.G2Affine for BN254, []bls12381.G1Affine and
+// []bls12381.G2Affine for BLS12-381. The SRS supports the aggregation of up to n proofs, n being the
+// largest power of 2 such that there are 2n powers in G1 and n in G2.
+func NewSRSFromPowersOfTau(g1A, g1B, g2A, g2B interface{}) (SRS, error) {
+	switch _g1A := g1A.(type) {
+	case []curve_bn254.G1Affine:
+		_g1B, ok1 := g1B.([]curve_bn254.G1Affine)
+		_g2A, ok2 := g2A.([]curve_bn254.G2Affine)
+		_g2B, ok3 := g2B.([]curve_bn254.G2Affine)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errMixedCurves
+		}
+		return aggregation_bn254.NewSRSFromPowersOfTau(_g1A, _g1B, _g2A, _g2B)
+	case []curve_bls12381.G1Affine:
+		_g1B, ok1 := g1B.([]curve_bls12381.G1Affine)
+		_g2A, ok2 := g2A.([]curve_bls12381.G2Affine)
+		_g2B, ok3 := g2B.([]curve_bls12381.G2Affine)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, errMixedCurves
+		}
+		return aggregation_bls12381.NewSRSFromPowersOfTau(_g1A, _g1B, _g2A, _g2B)
+	default:
+		return nil, errUnsupportedCurve
+	}
+}
+
+// NewVerifierSRS returns the part of srs needed by Verify
+func NewVerifierSRS(srs SRS) (VerifierSRS, error) {
+	switch _srs := srs.(type) {
+	case *aggregation_bn254.SRS:
+		return _srs.VerifierSRS(), nil
+	case *aggregation_bls12381.SRS:
+		return _srs.VerifierSRS(), nil
+	default:
+		return nil, errUnsupportedCurve
+	}
+}
+
+// NewEmptySRS instantiates a curve-typed SRS and returns an interface
+// This function exists for serialization purposes
+func NewEmptySRS(curveID ecc.ID) SRS {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregation_bn254.SRS{}
+	case ecc.BLS12_381:
+		return &aggregation_bls12381.SRS{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewEmptyVerifierSRS instantiates a curve-typed VerifierSRS and returns an interface
+// This function exists for serialization purposes
+func NewEmptyVerifierSRS(curveID ecc.ID) VerifierSRS {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregation_bn254.VerifierSRS{}
+	case ecc.BLS12_381:
+		return &aggregation_bls12381.VerifierSRS{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewProof instantiates a curve-typed Proof and returns an interface
+// This function exists for serialization purposes
+func NewProof(curveID ecc.ID) Proof {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregation_bn254.Proof{}
+	case ecc.BLS12_381:
+		return &aggregation_bls12381.Proof{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// Aggregate aggregates Groth16 proofs of the circuit of vk; publicWitnesses[i] is the public
+// witness of proofs[i]. The statement (vk and the public witnesses) is bound to the transcript,
+// so Verify must be given the same one.
+//
+// If the number of proofs is not a power of 2, the last proof is repeated; Verify pads the public
+// witnesses the same way.
+func Aggregate(srs SRS, vk groth16.VerifyingKey, proofs []groth16.Proof, publicWitnesses []frontend.Circuit) (Proof, error) {
+	switch _srs := srs.(type) {
+	case *aggregation_bn254.SRS:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			_proofs[i] = proofs[i].(*groth16_bn254.Proof)
+		}
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := 0; i < len(publicWitnesses); i++ {
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return nil, err
+			}
+		}
+		return aggregation_bn254.Aggregate(_srs, vk.(*groth16_bn254.VerifyingKey), _proofs, w)
+	case *aggregation_bls12381.SRS:
+		_proofs := make([]*groth16_bls12381.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			_proofs[i] = proofs[i].(*groth16_bls12381.Proof)
+		}
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := 0; i < len(publicWitnesses); i++ {
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return nil, err
+			}
+		}
+		return aggregation_bls12381.Aggregate(_srs, vk.(*groth16_bls12381.VerifyingKey), _proofs, w)
+	default:
+		return nil, errUnsupportedCurve
+	}
+}
+
+// Verify verifies an aggregated proof of Groth16 proofs of the circuit of vk; publicWitnesses[i] is
+// the public witness of the i-th aggregated proof. srs is the SRS used by Aggregate, or the part of
+// it needed by the verifier (see NewVerifierSRS).
+func Verify(srs VerifierSRS, vk groth16.VerifyingKey, proof Proof, publicWitnesses []frontend.Circuit) error {
+	switch _srs := srs.(type) {
+	case *aggregation_bn254.SRS:
+		return Verify(_srs.VerifierSRS(), vk, proof, publicWitnesses)
+	case *aggregation_bls12381.SRS:
+		return Verify(_srs.VerifierSRS(), vk, proof, publicWitnesses)
+	case *aggregation_bn254.VerifierSRS:
+		_proof, ok := proof.(*aggregation_bn254.Proof)
+		if !ok {
+			return fmt.Errorf("unexpected proof type %T", proof)
+		}
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := 0; i < len(publicWitnesses); i++ {
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return err
+			}
+		}
+		return aggregation_bn254.Verify(_srs, vk.(*groth16_bn254.VerifyingKey), _proof, w)
+	case *aggregation_bls12381.VerifierSRS:
+		_proof, ok := proof.(*aggregation_bls12381.Proof)
+		if !ok {
+			return fmt.Errorf("unexpected proof type %T", proof)
+		}
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := 0; i < len(publicWitnesses); i++ {
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return err
+			}
+		}
+		return aggregation_bls12381.Verify(_srs, vk.(*groth16_bls12381.VerifyingKey), _proof, w)
+	default:
+		return errUnsupportedCurve
+	}
+}
@@ -20,6 +20,8 @@
 package groth16
 
 import (
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -87,6 +89,21 @@ type ProvingKey interface {
 	IsDifferent(interface{}) bool
 }
 
+// MappedProvingKey represents a Groth16 ProvingKey memory-mapped from a file (see OpenProvingKey),
+// whose points are decoded while proving
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type MappedProvingKey interface {
+	io.Closer
+	CurveID() ecc.ID
+
+	// NbG1 returns the number of G1 elements in the ProvingKey
+	NbG1() int
+
+	// NbG2 returns the number of G2 elements in the ProvingKey
+	NbG2() int
+}
+
 // VerifyingKey represents a Groth16 VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
@@ -107,6 +124,8 @@ type VerifyingKey interface {
 
 	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
 	// this will return an error if not supported on the CurveID()
+	//
+	// On BN254, the arguments of the verifyProof method are encoded with MarshalSolidity.
 	ExportSolidity(w io.Writer) error
 
 	IsDifferent(interface{}) bool
@@ -157,6 +176,100 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness frontend.Circuit) error
 	}
 }
 
+// BatchVerify verifies several proofs of the same circuit at once; publicWitnesses[i] is the public
+// witness of proofs[i].
+//
+// The verification equations are combined with random coefficients into a single multi-pairing.
+// If the batch check fails, the returned error identifies the first invalid proof.
+func BatchVerify(proofs []Proof, vk VerifyingKey, publicWitnesses []frontend.Circuit) error {
+	if len(proofs) != len(publicWitnesses) {
+		return errors.New("number of proofs and public witnesses don't match")
+	}
+
+	switch _vk := vk.(type) {
+	case *groth16_bls12377.VerifyingKey:
+		_proofs := make([]*groth16_bls12377.Proof, len(proofs))
+		w := make([]witness_bls12377.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls12377.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bls12377.BatchVerify(_proofs, _vk, w)
+	case *groth16_bls12381.VerifyingKey:
+		_proofs := make([]*groth16_bls12381.Proof, len(proofs))
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls12381.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bls12381.BatchVerify(_proofs, _vk, w)
+	case *groth16_bn254.VerifyingKey:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bn254.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bn254.BatchVerify(_proofs, _vk, w)
+	case *groth16_bw6761.VerifyingKey:
+		_proofs := make([]*groth16_bw6761.Proof, len(proofs))
+		w := make([]witness_bw6761.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bw6761.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bw6761.BatchVerify(_proofs, _vk, w)
+	case *groth16_bls24315.VerifyingKey:
+		_proofs := make([]*groth16_bls24315.Proof, len(proofs))
+		w := make([]witness_bls24315.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls24315.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bls24315.BatchVerify(_proofs, _vk, w)
+	case *groth16_bw6633.VerifyingKey:
+		_proofs := make([]*groth16_bw6633.Proof, len(proofs))
+		w := make([]witness_bw6633.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bw6633.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return groth16_bw6633.BatchVerify(_proofs, _vk, w)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
 // ReadAndVerify behaves like Verify, except witness is read from a io.Reader
 // witness must be encoded following the binary serialization protocol described in
 // gnark/backend/witness package
@@ -204,6 +317,23 @@ func ReadAndVerify(proof Proof, vk VerifyingKey, publicWitness io.Reader) error
 	}
 }
 
+// MarshalSolidity returns the ABI encoding of the arguments (a, b, c, input) of the verifyProof
+// method of the solidity verifier (see VerifyingKey.ExportSolidity). The calldata of a call
+// to verifyProof is the method selector followed by these bytes.
+//
+// It is implemented for BN254 and will return an error with other curves.
+func MarshalSolidity(proof Proof, publicWitness frontend.Circuit) ([]byte, error) {
+	_proof, ok := proof.(*groth16_bn254.Proof)
+	if !ok {
+		return nil, errors.New("solidity encoding is only implemented for BN254")
+	}
+	w := witness_bn254.Witness{}
+	if err := w.FromPublicAssignment(publicWitness); err != nil {
+		return nil, err
+	}
+	return append(_proof.MarshalSolidity(), w.MarshalSolidity()...), nil
+}
+
 // Prove runs the groth16.Prove algorithm.
 //
 // if the force flag is set:
@@ -316,6 +446,105 @@ func ReadAndProve(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witness
 	}
 }
 
+// OpenProvingKey memory-maps the file at path, holding a ProvingKey encoded with WriteRawTo
+// for the curve curveID. Only the small elements of the key are decoded: the points are decoded
+// by chunks while proving with ProveMapped, so that the whole key is never held in memory.
+//
+// The returned key must be closed once it is no longer used.
+func OpenProvingKey(curveID ecc.ID, path string) (MappedProvingKey, error) {
+	switch curveID {
+	case ecc.BN254:
+		pk, err := groth16_bn254.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS12_377:
+		pk, err := groth16_bls12377.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS12_381:
+		pk, err := groth16_bls12381.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_761:
+		pk, err := groth16_bw6761.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS24_315:
+		pk, err := groth16_bls24315.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_633:
+		pk, err := groth16_bw6633.OpenMappedProvingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// ProveMapped behaves like Prove, with a proving key opened with OpenProvingKey
+func ProveMapped(r1cs frontend.CompiledConstraintSystem, pk MappedProvingKey, witness frontend.Circuit, opts ...func(opt *backend.ProverOption) error) (Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverOption(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		w := witness_bls12377.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bls12377.ProveMapped(_r1cs, pk.(*groth16_bls12377.MappedProvingKey), w, opt)
+	case *backend_bls12381.R1CS:
+		w := witness_bls12381.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bls12381.ProveMapped(_r1cs, pk.(*groth16_bls12381.MappedProvingKey), w, opt)
+	case *backend_bn254.R1CS:
+		w := witness_bn254.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bn254.ProveMapped(_r1cs, pk.(*groth16_bn254.MappedProvingKey), w, opt)
+	case *backend_bw6761.R1CS:
+		w := witness_bw6761.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bw6761.ProveMapped(_r1cs, pk.(*groth16_bw6761.MappedProvingKey), w, opt)
+	case *backend_bls24315.R1CS:
+		w := witness_bls24315.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bls24315.ProveMapped(_r1cs, pk.(*groth16_bls24315.MappedProvingKey), w, opt)
+	case *backend_bw6633.R1CS:
+		w := witness_bw6633.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		return groth16_bw6633.ProveMapped(_r1cs, pk.(*groth16_bw6633.MappedProvingKey), w, opt)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
 // Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
 //
 // Note that careful consideration must be given to this step in production environment.
@@ -0,0 +1,20 @@
+package groth16
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchVerifyMixedCurves(t *testing.T) {
+	assert := require.New(t)
+
+	// a BLS12-381 proof in a BN254 batch is reported, not asserted
+	proofs := []Proof{&groth16_bls12381.Proof{}}
+	err := BatchVerify(proofs, &groth16_bn254.VerifyingKey{}, []frontend.Circuit{&solidityCircuit{Y: 9}})
+	assert.EqualError(err, "proof 0: unexpected proof type *groth16.Proof")
+}
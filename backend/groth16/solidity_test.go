@@ -0,0 +1,52 @@
+package groth16
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+
+	"github.com/stretchr/testify/require"
+)
+
+type solidityCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *solidityCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestMarshalSolidity(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &solidityCircuit{})
+		assert.NoError(err)
+		pk, _, err := Setup(ccs)
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, &solidityCircuit{X: 3, Y: 9})
+		assert.NoError(err)
+
+		encoded, err := MarshalSolidity(proof, &solidityCircuit{Y: 9})
+		if curve != ecc.BN254 {
+			assert.EqualError(err, "solidity encoding is only implemented for BN254")
+			assert.Nil(encoded)
+			continue
+		}
+		assert.NoError(err)
+
+		// the proof words, followed by the public witness
+		var w witness_bn254.Witness
+		assert.NoError(w.FromPublicAssignment(&solidityCircuit{Y: 9}))
+		expected := append(proof.(*groth16_bn254.Proof).MarshalSolidity(), w.MarshalSolidity()...)
+		assert.Equal(expected, encoded)
+		assert.Len(encoded, (8+1)*32)
+		assert.EqualValues(9, encoded[len(encoded)-1])
+	}
+}
@@ -15,6 +15,8 @@ func init() {
 		Register(IsZero)
 		IthBit = NewStaticHint(builtinIthBit, 2, 1)
 		Register(IthBit)
+		DivMod = NewStaticHint(builtinDivMod, 2, 2)
+		Register(DivMod)
 	})
 }
 
@@ -30,6 +32,11 @@ var (
 	// integer inputs i and n, takes the little-endian bit representation of n and
 	// returns its i-th bit.
 	IthBit Function
+
+	// DivMod returns the quotient and the remainder of the integer division of a
+	// by b. The function expects exactly two integer inputs a and b, and returns
+	// 0, 0 if b == 0.
+	DivMod Function
 )
 
 func builtinIsZero(curveID ecc.ID, inputs []*big.Int, results []*big.Int) error {
@@ -63,3 +70,13 @@ func builtinIthBit(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
 	result.SetUint64(uint64(inputs[0].Bit(int(inputs[1].Uint64()))))
 	return nil
 }
+
+func builtinDivMod(_ ecc.ID, inputs []*big.Int, results []*big.Int) error {
+	if inputs[1].Sign() == 0 {
+		results[0].SetUint64(0)
+		results[1].SetUint64(0)
+		return nil
+	}
+	results[0].DivMod(inputs[0], inputs[1], results[1])
+	return nil
+}
@@ -20,6 +20,8 @@
 package plonk
 
 import (
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -82,29 +84,66 @@ type ProvingKey interface {
 // VerifyingKey represents a plonk VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+//
+// ExportSolidity is implemented for BN254 and will return an error with other curves
 type VerifyingKey interface {
 	io.WriterTo
 	io.ReaderFrom
 	InitKZG(srs kzg.SRS) error
 	NbPublicWitness() int // number of elements expected in the public witness
+
+	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
+	// this will return an error if not supported on the CurveID()
+	//
+	// On BN254, the contract expects the proof encoded with MarshalSolidity, and the keys
+	// must be set up with backend.WithTranscriptHash(backend.KECCAK256).
+	ExportSolidity(w io.Writer) error
 }
 
 // Setup prepares the public data associated to a circuit + public inputs.
-func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS) (ProvingKey, VerifyingKey, error) {
+//
+// The challenges of the proofs are derived with sha256, unless another hash is given with
+// backend.WithTranscriptHash. The hash is recorded in the verifying key, so Prove and Verify
+// always use the one chosen at setup. The solidity verifier (see VerifyingKey.ExportSolidity)
+// requires backend.WithTranscriptHash(backend.KECCAK256).
+func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, opts ...func(opt *backend.SetupOption) error) (ProvingKey, VerifyingKey, error) {
 
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
-		return plonk_bn254.Setup(tccs, kzgSRS.(*kzg_bn254.SRS))
+		return plonk_bn254.Setup(tccs, kzgSRS.(*kzg_bn254.SRS), opts...)
 	case *cs_bls12381.SparseR1CS:
-		return plonk_bls12381.Setup(tccs, kzgSRS.(*kzg_bls12381.SRS))
+		return plonk_bls12381.Setup(tccs, kzgSRS.(*kzg_bls12381.SRS), opts...)
 	case *cs_bls12377.SparseR1CS:
-		return plonk_bls12377.Setup(tccs, kzgSRS.(*kzg_bls12377.SRS))
+		return plonk_bls12377.Setup(tccs, kzgSRS.(*kzg_bls12377.SRS), opts...)
 	case *cs_bw6761.SparseR1CS:
-		return plonk_bw6761.Setup(tccs, kzgSRS.(*kzg_bw6761.SRS))
+		return plonk_bw6761.Setup(tccs, kzgSRS.(*kzg_bw6761.SRS), opts...)
 	case *cs_bls24315.SparseR1CS:
-		return plonk_bls24315.Setup(tccs, kzgSRS.(*kzg_bls24315.SRS))
+		return plonk_bls24315.Setup(tccs, kzgSRS.(*kzg_bls24315.SRS), opts...)
 	case *cs_bw6633.SparseR1CS:
-		return plonk_bw6633.Setup(tccs, kzgSRS.(*kzg_bw6633.SRS))
+		return plonk_bw6633.Setup(tccs, kzgSRS.(*kzg_bw6633.SRS), opts...)
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+
+}
+
+// DomainSize returns the size of the fft domain of the circuit, including the rows needed
+// by the lookup tables. The kzg SRS given to Setup must have at least DomainSize(ccs)+3 points.
+func DomainSize(ccs frontend.CompiledConstraintSystem) uint64 {
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return plonk_bn254.DomainSize(tccs)
+	case *cs_bls12381.SparseR1CS:
+		return plonk_bls12381.DomainSize(tccs)
+	case *cs_bls12377.SparseR1CS:
+		return plonk_bls12377.DomainSize(tccs)
+	case *cs_bw6761.SparseR1CS:
+		return plonk_bw6761.DomainSize(tccs)
+	case *cs_bls24315.SparseR1CS:
+		return plonk_bls24315.DomainSize(tccs)
+	case *cs_bw6633.SparseR1CS:
+		return plonk_bw6633.DomainSize(tccs)
 	default:
 		panic("unrecognized SparseR1CS curve type")
 	}
@@ -224,6 +263,134 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness frontend.Circuit) error
 	}
 }
 
+// BatchVerify verifies several proofs at once; proofs[i] is verified against vks[i] and publicWitnesses[i].
+// The verifying keys may belong to different circuits, but must share the same KZG SRS.
+//
+// The final pairing checks of all the proofs are folded into a single pairing equation.
+// If it fails, the returned error identifies the first invalid proof.
+func BatchVerify(proofs []Proof, vks []VerifyingKey, publicWitnesses []frontend.Circuit) error {
+	if len(proofs) != len(vks) || len(proofs) != len(publicWitnesses) {
+		return errors.New("number of proofs, verifying keys and public witnesses don't match")
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+
+	switch proofs[0].(type) {
+
+	case *plonk_bn254.Proof:
+		_proofs := make([]*plonk_bn254.Proof, len(proofs))
+		_vks := make([]*plonk_bn254.VerifyingKey, len(vks))
+		w := make([]witness_bn254.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bn254.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bn254.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bn254.BatchVerify(_proofs, _vks, w)
+
+	case *plonk_bls12381.Proof:
+		_proofs := make([]*plonk_bls12381.Proof, len(proofs))
+		_vks := make([]*plonk_bls12381.VerifyingKey, len(vks))
+		w := make([]witness_bls12381.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bls12381.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bls12381.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bls12381.BatchVerify(_proofs, _vks, w)
+
+	case *plonk_bls12377.Proof:
+		_proofs := make([]*plonk_bls12377.Proof, len(proofs))
+		_vks := make([]*plonk_bls12377.VerifyingKey, len(vks))
+		w := make([]witness_bls12377.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bls12377.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bls12377.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bls12377.BatchVerify(_proofs, _vks, w)
+
+	case *plonk_bw6761.Proof:
+		_proofs := make([]*plonk_bw6761.Proof, len(proofs))
+		_vks := make([]*plonk_bw6761.VerifyingKey, len(vks))
+		w := make([]witness_bw6761.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bw6761.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bw6761.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bw6761.BatchVerify(_proofs, _vks, w)
+
+	case *plonk_bw6633.Proof:
+		_proofs := make([]*plonk_bw6633.Proof, len(proofs))
+		_vks := make([]*plonk_bw6633.VerifyingKey, len(vks))
+		w := make([]witness_bw6633.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bw6633.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bw6633.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bw6633.BatchVerify(_proofs, _vks, w)
+
+	case *plonk_bls24315.Proof:
+		_proofs := make([]*plonk_bls24315.Proof, len(proofs))
+		_vks := make([]*plonk_bls24315.VerifyingKey, len(vks))
+		w := make([]witness_bls24315.Witness, len(publicWitnesses))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*plonk_bls24315.Proof); !ok {
+				return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+			}
+			if _vks[i], ok = vks[i].(*plonk_bls24315.VerifyingKey); !ok {
+				return fmt.Errorf("proof %d: unexpected verifying key type %T", i, vks[i])
+			}
+			if err := w[i].FromPublicAssignment(publicWitnesses[i]); err != nil {
+				return fmt.Errorf("proof %d: %w", i, err)
+			}
+		}
+		return plonk_bls24315.BatchVerify(_proofs, _vks, w)
+
+	default:
+		panic("unrecognized proof type")
+	}
+}
+
 // NewCS instantiate a concrete curved-typed SparseR1CS and return a ConstraintSystem interface
 // This method exists for (de)serialization purposes
 func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
@@ -468,6 +635,17 @@ func ReadAndVerify(proof Proof, vk VerifyingKey, witness io.Reader) error {
 	}
 }
 
+// MarshalSolidity returns the encoding of the proof expected by the solidity verifier
+// (see VerifyingKey.ExportSolidity). It is implemented for BN254 and will return an
+// error with other curves.
+func MarshalSolidity(proof Proof) ([]byte, error) {
+	_proof, ok := proof.(*plonk_bn254.Proof)
+	if !ok {
+		return nil, errors.New("solidity encoding is only implemented for BN254")
+	}
+	return _proof.MarshalSolidity(), nil
+}
+
 // IsSolved attempts to solve the constraint system with provided witness
 // returns nil if it succeeds, error otherwise.
 func IsSolved(ccs frontend.CompiledConstraintSystem, witness frontend.Circuit, opts ...func(opt *backend.ProverOption) error) error {
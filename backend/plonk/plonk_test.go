@@ -0,0 +1,36 @@
+package plonk
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/frontend"
+	plonk_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonk"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+
+	"github.com/stretchr/testify/require"
+)
+
+type batchCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *batchCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestBatchVerifyMixedCurves(t *testing.T) {
+	assert := require.New(t)
+
+	// mixing curves is reported, not asserted
+	proofs := []Proof{&plonk_bn254.Proof{}}
+	vks := []VerifyingKey{&plonk_bls12381.VerifyingKey{}}
+	err := BatchVerify(proofs, vks, []frontend.Circuit{&batchCircuit{Y: 9}})
+	assert.EqualError(err, "proof 0: unexpected verifying key type *plonk.VerifyingKey")
+
+	proofs = []Proof{&plonk_bn254.Proof{}, &plonk_bls12381.Proof{}}
+	vks = []VerifyingKey{&plonk_bn254.VerifyingKey{}, &plonk_bn254.VerifyingKey{}}
+	err = BatchVerify(proofs, vks, []frontend.Circuit{&batchCircuit{Y: 9}, &batchCircuit{Y: 9}})
+	assert.EqualError(err, "proof 1: unexpected proof type *plonk.Proof")
+}
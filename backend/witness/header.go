@@ -0,0 +1,309 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package witness
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// HeaderVersion is the version of the header written by EncodeFull and EncodePublic
+const HeaderVersion = 1
+
+var headerMagic = [4]byte{'g', 'n', 'k', 'w'}
+
+const (
+	kindFull   uint8 = 0
+	kindPublic uint8 = 1
+)
+
+// Header describes a witness encoded with EncodeFull or EncodePublic
+type Header struct {
+	Version    uint8
+	CurveID    ecc.ID
+	Public     bool     // true for a public witness, false for a full witness
+	SchemaHash [32]byte // see SchemaHash
+	NbPublic   int      // number of public variables of the circuit
+	NbSecret   int      // number of secret variables of the circuit
+
+	// names of the encoded variables, SecretNames being empty for a public witness
+	PublicNames, SecretNames []string
+}
+
+// SchemaHash returns a hash of the names and visibilities of the variables of the
+// circuit (see frontend.Schema), in the order of the witness. It doesn't depend on the
+// curve or the backend.
+func SchemaHash(circuit frontend.Circuit) ([32]byte, error) {
+	public, secret, err := variableNames(circuit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return schemaHash(public, secret), nil
+}
+
+// EncodeFull writes the header describing the full witness, followed by the witness
+// encoded with the binary protocol (see WriteFullTo)
+func EncodeFull(w io.Writer, curveID ecc.ID, witness frontend.Circuit) (int64, error) {
+	return encode(w, curveID, witness, false)
+}
+
+// EncodePublic writes the header describing the public witness, followed by the
+// witness encoded with the binary protocol (see WritePublicTo)
+func EncodePublic(w io.Writer, curveID ecc.ID, publicWitness frontend.Circuit) (int64, error) {
+	return encode(w, curveID, publicWitness, true)
+}
+
+// DecodeFull reads a full witness written by EncodeFull into witness, with big.Int
+// values. It returns an error if the witness was encoded for another curve, is a
+// public witness, or doesn't match the variables of witness.
+func DecodeFull(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int64, error) {
+	return decode(r, curveID, witness, false)
+}
+
+// DecodePublic reads a public witness written by EncodePublic into witness, with
+// big.Int values. It returns an error if the witness was encoded for another curve,
+// is a full witness, or doesn't match the variables of witness.
+func DecodePublic(r io.Reader, curveID ecc.ID, publicWitness frontend.Circuit) (int64, error) {
+	return decode(r, curveID, publicWitness, true)
+}
+
+func encode(w io.Writer, curveID ecc.ID, witness frontend.Circuit, public bool) (int64, error) {
+	publicNames, secretNames, err := variableNames(witness)
+	if err != nil {
+		return 0, err
+	}
+	header := Header{
+		Version:     HeaderVersion,
+		CurveID:     curveID,
+		Public:      public,
+		SchemaHash:  schemaHash(publicNames, secretNames),
+		NbPublic:    len(publicNames),
+		NbSecret:    len(secretNames),
+		PublicNames: publicNames,
+	}
+	if !public {
+		header.SecretNames = secretNames
+	}
+
+	n, err := header.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+	var m int64
+	if public {
+		m, err = WritePublicTo(w, curveID, witness)
+	} else {
+		m, err = WriteFullTo(w, curveID, witness)
+	}
+	return n + m, err
+}
+
+func decode(r io.Reader, curveID ecc.ID, witness frontend.Circuit, public bool) (int64, error) {
+	var header Header
+	n, err := header.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	if header.CurveID != curveID {
+		return n, fmt.Errorf("witness encoded for curve %s, expected %s", header.CurveID, curveID)
+	}
+	if header.Public != public {
+		if public {
+			return n, errors.New("expected a public witness, got a full witness")
+		}
+		return n, errors.New("expected a full witness, got a public witness")
+	}
+	if err := header.checkSchema(witness); err != nil {
+		return n, err
+	}
+
+	var m int64
+	if public {
+		m, err = ReadPublicFrom(r, curveID, witness)
+	} else {
+		m, err = ReadFullFrom(r, curveID, witness)
+	}
+	return n + m, err
+}
+
+// checkSchema returns an error describing the first difference between the variables
+// of the header and the ones of the circuit
+func (header *Header) checkSchema(circuit frontend.Circuit) error {
+	public, secret, err := variableNames(circuit)
+	if err != nil {
+		return err
+	}
+	if schemaHash(public, secret) == header.SchemaHash {
+		return nil
+	}
+	if err := compareNames("public", header.PublicNames, public); err != nil {
+		return err
+	}
+	if !header.Public {
+		if err := compareNames("secret", header.SecretNames, secret); err != nil {
+			return err
+		}
+	}
+	if header.NbSecret != len(secret) {
+		return fmt.Errorf("witness has %d secret variables, circuit has %d", header.NbSecret, len(secret))
+	}
+	return errors.New("witness schema doesn't match the circuit")
+}
+
+func compareNames(visibility string, got, expected []string) error {
+	for i := 0; i < len(got) && i < len(expected); i++ {
+		if got[i] != expected[i] {
+			return fmt.Errorf("%s variable %d is %s in the witness, %s in the circuit", visibility, i, got[i], expected[i])
+		}
+	}
+	if len(got) != len(expected) {
+		return fmt.Errorf("witness has %d %s variables, circuit has %d", len(got), visibility, len(expected))
+	}
+	return nil
+}
+
+// WriteTo encodes the header (implements io.WriterTo)
+func (header *Header) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write(headerMagic[:])
+	buf.WriteByte(header.Version)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(header.CurveID))
+	if header.Public {
+		buf.WriteByte(kindPublic)
+	} else {
+		buf.WriteByte(kindFull)
+	}
+	buf.Write(header.SchemaHash[:])
+	_ = binary.Write(&buf, binary.BigEndian, uint32(header.NbPublic))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(header.NbSecret))
+
+	if len(header.PublicNames) != header.NbPublic || (!header.Public && len(header.SecretNames) != header.NbSecret) {
+		return 0, errors.New("invalid number of variable names")
+	}
+	names := append([]string{}, header.PublicNames...)
+	if !header.Public {
+		names = append(names, header.SecretNames...)
+	}
+	for _, name := range names {
+		if len(name) > 0xffff {
+			return 0, fmt.Errorf("variable name %s is too long", name)
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(name)))
+		buf.WriteString(name)
+	}
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// ReadFrom decodes the header (implements io.ReaderFrom). It returns an error if the
+// data doesn't start with a header, or if the version is not supported.
+func (header *Header) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+	readFull := func(buf []byte) error {
+		n, err := io.ReadFull(r, buf)
+		read += int64(n)
+		return err
+	}
+
+	// magic, version, curveID, kind, schemaHash, nbPublic, nbSecret
+	var buf [4 + 1 + 2 + 1 + 32 + 4 + 4]byte
+	if err := readFull(buf[:]); err != nil {
+		return read, err
+	}
+	if !bytes.Equal(buf[:4], headerMagic[:]) {
+		return read, errors.New("invalid witness header, the witness may use the legacy format")
+	}
+	header.Version = buf[4]
+	if header.Version != HeaderVersion {
+		return read, fmt.Errorf("unsupported witness header version %d, expected %d", header.Version, HeaderVersion)
+	}
+	header.CurveID = ecc.ID(binary.BigEndian.Uint16(buf[5:7]))
+	switch buf[7] {
+	case kindFull:
+		header.Public = false
+	case kindPublic:
+		header.Public = true
+	default:
+		return read, fmt.Errorf("invalid witness kind %d", buf[7])
+	}
+	copy(header.SchemaHash[:], buf[8:40])
+	header.NbPublic = int(binary.BigEndian.Uint32(buf[40:44]))
+	header.NbSecret = int(binary.BigEndian.Uint32(buf[44:48]))
+
+	readNames := func(nb int) ([]string, error) {
+		var names []string
+		for i := 0; i < nb; i++ {
+			var l [2]byte
+			if err := readFull(l[:]); err != nil {
+				return nil, err
+			}
+			name := make([]byte, binary.BigEndian.Uint16(l[:]))
+			if err := readFull(name); err != nil {
+				return nil, err
+			}
+			names = append(names, string(name))
+		}
+		return names, nil
+	}
+
+	var err error
+	if header.PublicNames, err = readNames(header.NbPublic); err != nil {
+		return read, err
+	}
+	header.SecretNames = nil
+	if !header.Public {
+		if header.SecretNames, err = readNames(header.NbSecret); err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+// variableNames returns the names of the public and secret variables of the circuit,
+// in the order of the witness (see frontend.Schema)
+func variableNames(circuit frontend.Circuit) (public, secret []string, err error) {
+	schema, err := frontend.NewSchema(circuit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema.Public, schema.Secret, nil
+}
+
+func schemaHash(public, secret []string) [32]byte {
+	h := sha256.New()
+	write := func(visibility byte, names []string) {
+		for _, name := range names {
+			var l [4]byte
+			binary.BigEndian.PutUint32(l[:], uint32(len(name)))
+			h.Write([]byte{visibility})
+			h.Write(l[:])
+			h.Write([]byte(name))
+		}
+	}
+	write('p', public)
+	write('s', secret)
+	var res [32]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
@@ -38,14 +38,43 @@
 // 	* `[uint32(3)|bytes(Y)|bytes(X)|bytes(Z)]`
 // 	* Hex representation with values `Y = 35`, `X = 3`, `Z = 2`
 // 	`00000003000000000000000000000000000000000000000000000000000000000000002300000000000000000000000000000000000000000000000000000000000000030000000000000000000000000000000000000000000000000000000000000002`
+//
+// Self-describing format
+//
+// EncodeFull and EncodePublic prefix the binary protocol with a header describing the
+// witness, which DecodeFull and DecodePublic check against the expected curve and
+// circuit before reading the values:
+//
+// 	[magic | version | curveID | kind | schemaHash | nbPublic | nbSecret | names | witness]
+//
+// where
+// 	* magic is the 4 bytes "gnkw" and version a uint8 (HeaderVersion)
+// 	* curveID is a uint16, kind a uint8 (0 for a full witness, 1 for a public witness)
+// 	* schemaHash is the 32 bytes SchemaHash of the circuit
+// 	* nbPublic and nbSecret are the uint32 numbers of public and secret variables of the circuit
+// 	* names are the names of the encoded variables (public, then secret for a full witness),
+// 	  each encoded as a uint16 length followed by the bytes of the name
+// 	* witness is the witness encoded with the binary protocol
+//
+// All integers are big-endian.
+//
+// JSON
+//
+// ToJSON and FromJSON encode a witness as a JSON object mapping the variable names to
+// their values, in a "Public" and a "Secret" section, see FromJSON.
 package witness
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
@@ -343,6 +372,136 @@ func ToJSON(witness frontend.Circuit, curveID ecc.ID) (string, error) {
 	}
 }
 
+// FromJSON reads a witness in the format produced by ToJSON and assigns it to the
+// provided witness, with big.Int values:
+//
+// 	{"Public": {"Y": "35"}, "Secret": {"X": "3", "Z": "0x2"}}
+//
+// Names are the ones produced by the parser, nested structs and arrays being joined
+// with "_" (e.g. "Points_0_X"); slices in the witness must be allocated beforehand.
+// Values are decimal or hexadecimal ("0x" prefixed) strings, or JSON integers, and must
+// be in [0, r) where r is the modulus of the scalar field of curveID, negative values
+// being reduced modulo r.
+//
+// FromJSON returns an error, and doesn't modify the witness, if a variable is missing
+// or has an invalid value, or if a key doesn't match a variable of the witness.
+func FromJSON(data []byte, curveID ecc.ID, witness frontend.Circuit) error {
+	var input struct {
+		Public map[string]interface{}
+		Secret map[string]interface{}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&input); err != nil {
+		return fmt.Errorf("invalid witness JSON: %v", err)
+	}
+
+	modulus := curveID.Info().Fr.Modulus()
+
+	// the witness is modified only if all the values are valid
+	var targets []reflect.Value
+	var values []*big.Int
+	names := map[compiled.Visibility]map[string]struct{}{
+		compiled.Public: make(map[string]struct{}),
+		compiled.Secret: make(map[string]struct{}),
+	}
+
+	collectHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
+		section, other := input.Secret, input.Public
+		if visibility == compiled.Public {
+			section, other = input.Public, input.Secret
+		}
+		names[visibility][name] = struct{}{}
+
+		raw, ok := section[name]
+		if !ok {
+			if _, ok := other[name]; ok {
+				return fmt.Errorf("variable %s is %s, but was set in the %s section", name, visibilityName(visibility), otherSection(visibility))
+			}
+			return fmt.Errorf("missing assignment for %s variable %s", visibilityName(visibility), name)
+		}
+		v, err := parseJSONValue(raw, modulus)
+		if err != nil {
+			return fmt.Errorf("when parsing variable %s: %v", name, err)
+		}
+		targets = append(targets, tInput)
+		values = append(values, v)
+		return nil
+	}
+	if err := parser.Visit(witness, "", compiled.Unset, collectHandler, tVariable); err != nil {
+		return err
+	}
+
+	for _, s := range []struct {
+		visibility compiled.Visibility
+		values     map[string]interface{}
+	}{{compiled.Public, input.Public}, {compiled.Secret, input.Secret}} {
+		var unknown []string
+		for name := range s.values {
+			if _, ok := names[s.visibility][name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) != 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("unknown %s variable %s", visibilityName(s.visibility), unknown[0])
+		}
+	}
+
+	for i := range targets {
+		targets[i].Set(reflect.ValueOf(values[i]))
+	}
+	return nil
+}
+
+// parseJSONValue returns the value of a JSON string or number as an element of [0, modulus)
+func parseJSONValue(raw interface{}, modulus *big.Int) (*big.Int, error) {
+	var s string
+	switch v := raw.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	default:
+		return nil, fmt.Errorf("invalid value %v, expected a string or an integer", raw)
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	base := 10
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		base = 16
+		digits = digits[2:]
+	}
+	// SetString accepts a sign, which must only appear before the prefix
+	v, ok := new(big.Int).SetString(digits, base)
+	if !ok || v.Sign() < 0 || strings.HasPrefix(digits, "+") {
+		return nil, fmt.Errorf("invalid value %q", s)
+	}
+	if v.Cmp(modulus) >= 0 {
+		return nil, fmt.Errorf("value %s is larger than the modulus", s)
+	}
+	if neg && v.Sign() != 0 {
+		v.Sub(modulus, v)
+	}
+	return v, nil
+}
+
+func visibilityName(visibility compiled.Visibility) string {
+	if visibility == compiled.Public {
+		return "public"
+	}
+	return "secret"
+}
+
+func otherSection(visibility compiled.Visibility) string {
+	if visibility == compiled.Public {
+		return "Secret"
+	}
+	return "Public"
+}
+
 var tVariable reflect.Type
 
 func init() {
@@ -64,3 +64,145 @@ func TestReconstructionFull(t *testing.T) {
 		t.Fatal("public witness reconstructed doesn't match original value")
 	}
 }
+
+type nestedCircuit struct {
+	P [2]struct {
+		X, Y frontend.Variable
+	} `gnark:",public"`
+	E frontend.Variable `gnark:"e"`
+	S []frontend.Variable
+}
+
+func (circuit *nestedCircuit) Define(api frontend.API) error {
+	return nil
+}
+
+func TestFromJSON(t *testing.T) {
+	assert := require.New(t)
+
+	var w nestedCircuit
+	w.S = make([]frontend.Variable, 2)
+	data := `{
+		"Public": {"P_0_X": "1", "P_0_Y": "0x2a", "P_1_X": 3, "P_1_Y": "-1"},
+		"Secret": {"e": "18446744073709551616", "S_0": "0", "S_1": "0XFF"}
+	}`
+	assert.NoError(FromJSON([]byte(data), ecc.BN254, &w))
+
+	minusOne := new(big.Int).Sub(ecc.BN254.Info().Fr.Modulus(), big.NewInt(1))
+	assert.Equal(big.NewInt(42), w.P[0].Y)
+	assert.Equal(big.NewInt(3), w.P[1].X)
+	assert.Equal(minusOne, w.P[1].Y)
+	assert.Equal(new(big.Int).Lsh(big.NewInt(1), 64), w.E)
+	assert.Equal(big.NewInt(255), w.S[1])
+
+	// round trip with ToJSON
+	s, err := ToJSON(&w, ecc.BN254)
+	assert.NoError(err)
+	var r nestedCircuit
+	r.S = make([]frontend.Variable, 2)
+	assert.NoError(FromJSON([]byte(s), ecc.BN254, &r))
+	assert.True(reflect.DeepEqual(w, r), "witness reconstructed doesn't match original value")
+
+	for _, tc := range []struct {
+		data, err string
+	}{
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3"}, "Secret": {"e": "1", "S_0": "0", "S_1": "0"}}`,
+			"missing assignment for public variable P_1_Y"},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "1", "S_0": "0", "S_1": "0", "S_2": "0"}}`,
+			"unknown secret variable S_2"},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4", "e": "1"}, "Secret": {"S_0": "0", "S_1": "0"}}`,
+			"variable e is secret, but was set in the Public section"},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "1.5", "S_0": "0", "S_1": "0"}}`,
+			"when parsing variable e: invalid value \"1.5\""},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "` + ecc.BN254.Info().Fr.Modulus().String() + `", "S_0": "0", "S_1": "0"}}`,
+			"larger than the modulus"},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "--5", "S_0": "0", "S_1": "0"}}`,
+			"when parsing variable e: invalid value \"--5\""},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "0x-5", "S_0": "0", "S_1": "0"}}`,
+			"when parsing variable e: invalid value \"0x-5\""},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "-+5", "S_0": "0", "S_1": "0"}}`,
+			"when parsing variable e: invalid value \"-+5\""},
+		{`{"Public": {"P_0_X": "1", "P_0_Y": "2", "P_1_X": "3", "P_1_Y": "4"}, "Secret": {"e": "0x+5", "S_0": "0", "S_1": "0"}}`,
+			"when parsing variable e: invalid value \"0x+5\""},
+		{`{"Public": {}, "Secret": {}, "Other": {}}`,
+			"unknown field \"Other\""},
+	} {
+		var w nestedCircuit
+		w.S = make([]frontend.Variable, 2)
+		err := FromJSON([]byte(tc.data), ecc.BN254, &w)
+		assert.Error(err)
+		assert.Contains(err.Error(), tc.err)
+		assert.Nil(w.E, "witness modified on error")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	assert := require.New(t)
+
+	var wFull circuit
+	wFull.X = new(big.Int).SetInt64(42)
+	wFull.Y = new(big.Int).SetInt64(8000)
+	wFull.E = new(big.Int).SetInt64(1)
+
+	var full, public bytes.Buffer
+	written, err := EncodeFull(&full, ecc.BN254, &wFull)
+	assert.NoError(err)
+	_, err = EncodePublic(&public, ecc.BN254, &wFull)
+	assert.NoError(err)
+
+	// the header describes the witness
+	var header Header
+	_, err = header.ReadFrom(bytes.NewReader(full.Bytes()))
+	assert.NoError(err)
+	expectedHash, err := SchemaHash(&circuit{})
+	assert.NoError(err)
+	assert.Equal(Header{
+		Version:     HeaderVersion,
+		CurveID:     ecc.BN254,
+		SchemaHash:  expectedHash,
+		NbPublic:    2,
+		NbSecret:    1,
+		PublicNames: []string{"X", "Y"},
+		SecretNames: []string{"E"},
+	}, header)
+
+	var wFullReconstructed circuit
+	read, err := DecodeFull(bytes.NewReader(full.Bytes()), ecc.BN254, &wFullReconstructed)
+	assert.NoError(err)
+	assert.Equal(written, read)
+	assert.True(reflect.DeepEqual(wFull, wFullReconstructed), "full witness reconstructed doesn't match original value")
+
+	var wPublicReconstructed circuit
+	_, err = DecodePublic(bytes.NewReader(public.Bytes()), ecc.BN254, &wPublicReconstructed)
+	assert.NoError(err)
+	assert.Equal(wFull.X, wPublicReconstructed.X)
+	assert.Equal(wFull.Y, wPublicReconstructed.Y)
+	assert.Nil(wPublicReconstructed.E)
+
+	// wrong curve, kind or circuit
+	var w circuit
+	_, err = DecodeFull(bytes.NewReader(full.Bytes()), ecc.BLS12_381, &w)
+	assert.EqualError(err, "witness encoded for curve bn254, expected bls12_381")
+	_, err = DecodeFull(bytes.NewReader(public.Bytes()), ecc.BN254, &w)
+	assert.EqualError(err, "expected a full witness, got a public witness")
+	_, err = DecodePublic(bytes.NewReader(full.Bytes()), ecc.BN254, &w)
+	assert.EqualError(err, "expected a public witness, got a full witness")
+
+	var other nestedCircuit
+	other.S = make([]frontend.Variable, 2)
+	_, err = DecodeFull(bytes.NewReader(full.Bytes()), ecc.BN254, &other)
+	assert.EqualError(err, "public variable 0 is X in the witness, P_0_X in the circuit")
+
+	// legacy format
+	var legacy bytes.Buffer
+	_, err = WriteFullTo(&legacy, ecc.BN254, &wFull)
+	assert.NoError(err)
+	_, err = DecodeFull(&legacy, ecc.BN254, &w)
+	assert.Error(err)
+
+	// unsupported version
+	b := full.Bytes()
+	b[4] = HeaderVersion + 1
+	_, err = DecodeFull(bytes.NewReader(b), ecc.BN254, &w)
+	assert.EqualError(err, "unsupported witness header version 2, expected 1")
+}
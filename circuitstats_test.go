@@ -2,6 +2,7 @@ package gnark
 
 import (
 	"encoding/gob"
+	"math/big"
 	"os"
 	"sync"
 	"testing"
@@ -105,3 +106,54 @@ func init() {
 	}
 
 }
+
+type rangeCheckBench struct {
+	X [1000]frontend.Variable
+
+	check func(api frontend.API, v frontend.Variable)
+}
+
+func (circuit *rangeCheckBench) Define(api frontend.API) error {
+	for i := range circuit.X {
+		circuit.check(api, circuit.X[i])
+	}
+	return nil
+}
+
+// BenchmarkRangeCheck compiles 1000 range checks of 64 bits and reports the number of
+// constraints per check, to compare RangeCheck with the other ways to range check a value
+func BenchmarkRangeCheck(b *testing.B) {
+	bound := new(big.Int).Lsh(big.NewInt(1), 64)
+	bound.Sub(bound, big.NewInt(1))
+	checks := map[string]func(api frontend.API, v frontend.Variable){
+		"RangeCheck": func(api frontend.API, v frontend.Variable) {
+			api.RangeCheck(v, 64)
+		},
+		"RangeCheckTwice": func(api frontend.API, v frontend.Variable) {
+			api.RangeCheck(v, 64)
+			api.RangeCheck(v, 64)
+		},
+		"ToBinary": func(api frontend.API, v frontend.Variable) {
+			api.ToBinary(v, 64)
+		},
+		"AssertIsLessOrEqual": func(api frontend.API, v frontend.Variable) {
+			api.AssertIsLessOrEqual(v, bound)
+		},
+	}
+	for name, check := range checks {
+		for _, backendID := range backend.Implemented() {
+			circuit := rangeCheckBench{check: check}
+			b.Run(name+"/"+backendID.String(), func(b *testing.B) {
+				var nbConstraints int
+				for i := 0; i < b.N; i++ {
+					ccs, err := frontend.Compile(ecc.BN254, backendID, &circuit)
+					if err != nil {
+						b.Fatal(err)
+					}
+					nbConstraints = ccs.GetNbConstraints()
+				}
+				b.ReportMetric(float64(nbConstraints)/float64(len(circuit.X)), "constraints/check")
+			})
+		}
+	}
+}
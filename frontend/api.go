@@ -50,6 +50,12 @@ type API interface {
 	// Inverse returns res = 1 / i1
 	Inverse(i1 Variable) Variable
 
+	// DivMod returns the quotient and the remainder of the integer division of a by b.
+	// It fails if a >= 2^bits, b >= 2^bits or b == 0. bits must be small enough that
+	// the product of two such integers doesn't overflow the field (bits <= 126 for
+	// BN254), the function panics otherwise.
+	DivMod(a, b Variable, bits int) (quotient, remainder Variable)
+
 	// ---------------------------------------------------------------------------------------------
 	// Bit operations
 
@@ -86,9 +92,18 @@ type API interface {
 	// and i3 if b0=b1=1.
 	Lookup2(b0, b1 Variable, i0, i1, i2, i3 Variable) Variable
 
+	// Lookup returns table[index]. index must be in [0, len(table)), the
+	// constraint system is not satisfiable otherwise. The cost is a binary
+	// decomposition of index followed by a multiplexer tree over the table.
+	Lookup(index Variable, table []Variable) Variable
+
 	// IsZero returns 1 if a is zero, 0 otherwise
 	IsZero(i1 Variable) Variable
 
+	// Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2, i1 and i2 being compared as
+	// integers in [0, r), r being the modulus of the field
+	Cmp(i1, i2 Variable) Variable
+
 	// ---------------------------------------------------------------------------------------------
 	// Assertions
 
@@ -104,6 +119,14 @@ type API interface {
 	// AssertIsLessOrEqual fails if  v > bound
 	AssertIsLessOrEqual(v Variable, bound Variable)
 
+	// RangeCheck fails if v >= 2^bits
+	//
+	// With PlonK, v is decomposed in bytes checked against a lookup table, which costs
+	// about bits/4 constraints. With R1CS, v is decomposed in bits, saving only one
+	// constraint over ToBinary(v, bits). With both, the decomposition is done at compile
+	// time: a value checked several times is only decomposed once (for the smallest size).
+	RangeCheck(v Variable, bits int)
+
 	// Println behaves like fmt.Println but accepts cd.Variable as parameter
 	// whose value will be resolved at runtime when computed by the solver
 	Println(a ...Variable)
@@ -43,6 +43,10 @@ type ConstraintSystem struct {
 
 	// map for recording boolean constrained variables (to not constrain them twice)
 	MTBooleans map[int]struct{}
+
+	// map for recording range checked expressions (to not decompose them twice)
+	// key = expression, value = smallest number of bits checked
+	MRangeChecks map[string]int
 }
 
 func (cs *ConstraintSystem) Curve() ecc.ID {
@@ -133,3 +137,15 @@ func (cs *ConstraintSystem) AddDebugInfo(errName string, i ...interface{}) int {
 func (cs *ConstraintSystem) BitLen() int {
 	return cs.CurveID.Info().Fr.Bits
 }
+
+// RecordRangeCheck records that the expression identified by key is range checked on
+// nbBits, keeping the smallest number of bits it is checked on, and returns true on the
+// first check of the expression (the caller then decomposes it once, at compile time, on
+// MRangeChecks[key] bits)
+func (cs *ConstraintSystem) RecordRangeCheck(key string, nbBits int) bool {
+	b, ok := cs.MRangeChecks[key]
+	if !ok || nbBits < b {
+		cs.MRangeChecks[key] = nbBits
+	}
+	return !ok
+}
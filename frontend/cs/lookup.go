@@ -0,0 +1,44 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cs
+
+import "github.com/consensys/gnark/frontend"
+
+// LookupBits returns table[i], where b are the bits (little endian) of i. The table is
+// padded with its last entry to 2^len(b) entries, so i must be checked against the size
+// of the table by the caller. The bits are boolean constrained by api.Lookup2 and
+// api.Select.
+func LookupBits(api frontend.API, b []frontend.Variable, table []frontend.Variable) frontend.Variable {
+	t := make([]frontend.Variable, 1<<len(b))
+	copy(t, table)
+	for i := len(table); i < len(t); i++ {
+		t[i] = table[len(table)-1]
+	}
+
+	// reduce the table two bits at a time with Lookup2, and use a Select for
+	// the last bit if len(b) is odd
+	for ; len(b) >= 2; b = b[2:] {
+		for i := 0; i < len(t)/4; i++ {
+			t[i] = api.Lookup2(b[0], b[1], t[4*i], t[4*i+1], t[4*i+2], t[4*i+3])
+		}
+		t = t[:len(t)/4]
+	}
+	if len(b) == 1 {
+		return api.Select(b[0], t[1], t[0])
+	}
+	return t[0]
+}
@@ -19,6 +19,7 @@ package plonk
 import (
 	"fmt"
 	"math/big"
+	"math/bits"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -28,6 +29,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/parser"
 	"github.com/consensys/gnark/internal/utils"
@@ -167,6 +169,47 @@ func (system *sparseR1CS) Inverse(i1 frontend.Variable) frontend.Variable {
 	return res
 }
 
+// DivMod returns the quotient and the remainder of the integer division of a by b.
+// It fails if a >= 2^bits, b >= 2^bits or b == 0.
+func (system *sparseR1CS) DivMod(a, b frontend.Variable, bits int) (frontend.Variable, frontend.Variable) {
+	// q*b + r < 2^(2*bits) must not overflow
+	if maxBits := (system.BitLen() - 1) / 2; bits < 0 || bits > maxBits {
+		panic(fmt.Sprintf("divMod: bits must be in [0, %d]", maxBits))
+	}
+
+	if system.IsConstant(a) && system.IsConstant(b) {
+		modulus := system.CurveID.Info().Fr.Modulus()
+		n, d := system.ConstantValue(a), system.ConstantValue(b)
+		n.Mod(n, modulus)
+		d.Mod(d, modulus)
+		if n.BitLen() > bits || d.BitLen() > bits || d.Sign() == 0 {
+			panic(fmt.Sprintf("divMod failed: constant(%s) / constant(%s) on %d bits", n.String(), d.String(), bits))
+		}
+		var q, r big.Int
+		q.DivMod(n, d, &r)
+		return q, r
+	}
+
+	res, err := system.NewHint(hint.DivMod, a, b)
+	if err != nil {
+		panic(err)
+	}
+	q, r := res[0], res[1]
+
+	// a == q*b + r, first so that the solver computes q and r before decomposing them
+	system.AssertIsEqual(system.Add(system.Mul(q, b), r), a)
+
+	system.RangeCheck(a, bits)
+	system.RangeCheck(b, bits)
+	system.RangeCheck(q, bits)
+	system.RangeCheck(r, bits)
+
+	// r < b, which also ensures that b != 0
+	system.RangeCheck(system.Sub(b, r, 1), bits)
+
+	return q, r
+}
+
 // ---------------------------------------------------------------------------------------------
 // Bit operations
 
@@ -224,7 +267,7 @@ func (system *sparseR1CS) toBinary(a compiled.Term, nbBits int, unsafe bool) []f
 	// TODO we can save a constraint here
 	var Σbi frontend.Variable
 	if nbBits == 1 {
-		system.AssertIsEqual(sb[0], a)
+		Σbi = sb[0]
 	} else if nbBits == 2 {
 		Σbi = system.Add(sb[0], sb[1])
 	} else {
@@ -269,18 +312,24 @@ func (system *sparseR1CS) Xor(a, b frontend.Variable) frontend.Variable {
 		a, b = b, a
 	}
 	if system.IsConstant(b) {
+		// res = b + (1-2b)*a
 		l := a.(compiled.Term)
 		r := l
 		_b := utils.FromInterface(b)
-		one := big.NewInt(1)
-		_b.Lsh(&_b, 1).Sub(&_b, one)
-		idl := system.CoeffID(&_b)
-		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, compiled.CoeffIdZero)
+		var cl, k big.Int
+		cl.Lsh(&_b, 1).Sub(&cl, big.NewInt(1)).Mul(&cl, &system.Coeffs[l.CoeffID()]).Mod(&cl, system.CurveID.Info().Fr.Modulus())
+		k.Neg(&_b)
+		system.addPlonkConstraint(l, r, res, system.CoeffID(&cl), compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, system.CoeffID(&k))
 		return res
 	}
+	// res = a + b - 2ab, taking the coefficients of a and b into account
 	l := a.(compiled.Term)
 	r := b.(compiled.Term)
-	system.addPlonkConstraint(l, r, res, compiled.CoeffIdMinusOne, compiled.CoeffIdMinusOne, compiled.CoeffIdTwo, compiled.CoeffIdOne, compiled.CoeffIdOne, compiled.CoeffIdZero)
+	var cl, cr, cm big.Int
+	cl.Neg(&system.Coeffs[l.CoeffID()])
+	cr.Neg(&system.Coeffs[r.CoeffID()])
+	cm.Lsh(&system.Coeffs[l.CoeffID()], 1)
+	system.addPlonkConstraint(l, r, res, system.CoeffID(&cl), system.CoeffID(&cr), system.CoeffID(&cm), r.CoeffID(), compiled.CoeffIdOne, compiled.CoeffIdZero)
 	return res
 }
 
@@ -303,26 +352,30 @@ func (system *sparseR1CS) Or(a, b frontend.Variable) frontend.Variable {
 	}
 	if system.IsConstant(b) {
 		_b := utils.FromInterface(b)
-
-		l := a.(compiled.Term)
-		r := l
-
 		if _b.Cmp(&one) != 0 && _b.Cmp(&zero) != 0 {
 			panic(fmt.Sprintf("%s should be 0 or 1", _b.String()))
 		}
 		system.AssertIsBoolean(a)
 
-		one := big.NewInt(1)
-		_b.Sub(&_b, one)
-		idl := system.CoeffID(&_b)
-		system.addPlonkConstraint(l, r, res, idl, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, compiled.CoeffIdZero)
+		// res = a + b - a*b = (1-b)*a + b
+		l := a.(compiled.Term)
+		r := l
+		var cl, k big.Int
+		cl.Sub(&_b, &one).Mul(&cl, &system.Coeffs[l.CoeffID()]).Mod(&cl, system.CurveID.Info().Fr.Modulus())
+		k.Neg(&_b)
+		system.addPlonkConstraint(l, r, res, system.CoeffID(&cl), compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdOne, system.CoeffID(&k))
 		return res
 	}
 	l := a.(compiled.Term)
 	r := b.(compiled.Term)
 	system.AssertIsBoolean(l)
 	system.AssertIsBoolean(r)
-	system.addPlonkConstraint(l, r, res, compiled.CoeffIdMinusOne, compiled.CoeffIdMinusOne, compiled.CoeffIdOne, compiled.CoeffIdOne, compiled.CoeffIdOne, compiled.CoeffIdZero)
+
+	// res = a + b - a*b, taking the coefficients of a and b into account
+	var cl, cr big.Int
+	cl.Neg(&system.Coeffs[l.CoeffID()])
+	cr.Neg(&system.Coeffs[r.CoeffID()])
+	system.addPlonkConstraint(l, r, res, system.CoeffID(&cl), system.CoeffID(&cr), l.CoeffID(), r.CoeffID(), compiled.CoeffIdOne, compiled.CoeffIdZero)
 	return res
 }
 
@@ -397,6 +450,41 @@ func (system *sparseR1CS) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 front
 
 }
 
+// Lookup returns table[index]. index must be in [0, len(table)), the
+// constraint system is not satisfiable otherwise.
+func (system *sparseR1CS) Lookup(index frontend.Variable, table []frontend.Variable) frontend.Variable {
+	if len(table) == 0 {
+		panic("lookup in an empty table")
+	}
+
+	if system.IsConstant(index) {
+		i := system.ConstantValue(index)
+		if !i.IsUint64() || i.Uint64() >= uint64(len(table)) {
+			panic(fmt.Sprintf("lookup index %s out of range [0, %d)", i.String(), len(table)))
+		}
+		return table[i.Uint64()]
+	}
+
+	if len(table) == 1 {
+		system.AssertIsEqual(index, 0)
+		return table[0]
+	}
+
+	// decompose the index; if the table size is not a power of 2 the
+	// decomposition alone does not bound the index, so its bits are also
+	// compared with len(table)-1
+	nbBits := bits.Len(uint(len(table) - 1))
+	if len(table) == 1<<nbBits {
+		return cs.LookupBits(system, system.ToBinary(index, nbBits), table)
+	}
+	i := index.(compiled.Term)
+	b := system.toBinary(i, nbBits, true)
+	bound := *big.NewInt(int64(len(table) - 1))
+	debug := system.AddDebugInfo("lookup", i, " <= ", bound)
+	system.mustBeLessOrEqCstBits(b, bound, debug)
+	return cs.LookupBits(system, b, table)
+}
+
 // IsZero returns 1 if a is zero, 0 otherwise
 func (system *sparseR1CS) IsZero(i1 frontend.Variable) frontend.Variable {
 
@@ -426,6 +514,49 @@ func (system *sparseR1CS) IsZero(i1 frontend.Variable) frontend.Variable {
 	return m
 }
 
+// Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2, i1 and i2 being compared as
+// integers in [0, r)
+func (system *sparseR1CS) Cmp(i1, i2 frontend.Variable) frontend.Variable {
+	if system.IsConstant(i1) && system.IsConstant(i2) {
+		modulus := system.CurveID.Info().Fr.Modulus()
+		a, b := system.ConstantValue(i1), system.ConstantValue(i2)
+		a.Mod(a, modulus)
+		b.Mod(b, modulus)
+		return a.Cmp(b)
+	}
+
+	bi1 := system.toCanonicalBinary(i1)
+	bi2 := system.toCanonicalBinary(i2)
+
+	// going from the most significant bit, res is set to bi1[i] - bi2[i] at the first bit
+	// where bi1 and bi2 differ: 1 - res² is 1 while res == 0, and 0 afterwards
+	var res frontend.Variable = 0
+	for i := len(bi1) - 1; i >= 0; i-- {
+		undecided := system.Sub(1, system.Mul(res, res))
+		res = system.Add(res, system.Mul(undecided, system.Sub(bi1[i], bi2[i])))
+	}
+	return res
+}
+
+// toCanonicalBinary is equivalent to ToBinary(a), except the bits are constrained to
+// be the decomposition of a in [0, r), and not of a + r.
+func (system *sparseR1CS) toCanonicalBinary(a frontend.Variable) []frontend.Variable {
+	nbBits := system.BitLen()
+	if system.IsConstant(a) {
+		c := system.ConstantValue(a)
+		c.Mod(c, system.CurveID.Info().Fr.Modulus())
+		return system.ToBinary(c, nbBits)
+	}
+
+	var bound big.Int
+	bound.Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+	debug := system.AddDebugInfo("toCanonicalBinary", a, " <= ", bound)
+
+	bits := system.toBinary(a.(compiled.Term), nbBits, true)
+	system.mustBeLessOrEqCstBits(bits, bound, debug)
+	return bits
+}
+
 // Println behaves like fmt.Println but accepts Variable as parameter
 // whose value will be resolved at runtime when computed by the solver
 // Println enables circuit debugging and behaves almost like fmt.Println()
@@ -19,7 +19,9 @@ package plonk
 import (
 	"fmt"
 	"math/big"
+	"strconv"
 
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/utils"
@@ -96,6 +98,105 @@ func (system *sparseR1CS) AssertIsLessOrEqual(v frontend.Variable, bound fronten
 	}
 }
 
+// RangeCheck fails if v >= 2^bits
+//
+// The check is only recorded here, with the smallest number of bits v is checked on, and
+// Compile decomposes v once (see addRangeChecks).
+func (system *sparseR1CS) RangeCheck(v frontend.Variable, bits int) {
+	if bits < 0 {
+		panic("invalid bit size")
+	}
+	if bits >= system.BitLen() {
+		return // all the field elements are less than 2^bits
+	}
+
+	if system.IsConstant(v) {
+		c := utils.FromInterface(v)
+		if c.BitLen() > bits {
+			panic(fmt.Sprintf("rangeCheck failed: constant(%s) >= 2^%d", c.String(), bits))
+		}
+		return
+	}
+
+	a := v.(compiled.Term)
+	if !system.RecordRangeCheck(termKey(a), bits) {
+		return // v is already recorded, on the smallest number of bits
+	}
+	system.rangeChecks = append(system.rangeChecks, rangeCheck{
+		t:     a,
+		debug: system.AddDebugInfo("rangeCheck", a),
+	})
+}
+
+// rangeCheck is a term recorded by RangeCheck, to be decomposed by addRangeChecks
+type rangeCheck struct {
+	t     compiled.Term
+	debug int // debug info of the first call to RangeCheck on t
+}
+
+// addRangeChecks decomposes the terms recorded by RangeCheck, each on the smallest number
+// of bits it was checked on. It is called by CheckVariables and Compile, the terms are
+// decomposed only once.
+//
+// A term is decomposed in bytes, each byte being checked by a lookup in the table of the
+// 256 bytes: the check costs 2 constraints per byte, instead of one per bit for ToBinary.
+// The most significant byte x, on w < 8 bits, is also checked with x*2^(8-w) in the table,
+// so that x < 2^w.
+func (system *sparseR1CS) addRangeChecks() {
+	for _, rc := range system.rangeChecks {
+		bits := system.MRangeChecks[termKey(rc.t)]
+		if bits == 0 {
+			system.addPlonkConstraint(rc.t, system.zero(), system.zero(), rc.t.CoeffID(), compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, rc.debug)
+			continue
+		}
+
+		table := system.byteTable()
+
+		// x = 2^8*q + r, r being the least significant byte of x
+		x := rc.t
+		for ; bits > 8; bits -= 8 {
+			res, err := system.NewHint(hint.DivMod, x, 256)
+			if err != nil {
+				panic(err)
+			}
+			q, r := res[0].(compiled.Term), res[1].(compiled.Term)
+
+			// first so that the solver computes q and r before the lookup
+			var c big.Int
+			c.Neg(&system.Coeffs[x.CoeffID()])
+			system.addPlonkConstraint(q, r, x, system.CoeffID(big.NewInt(256)), compiled.CoeffIdOne, compiled.CoeffIdZero, compiled.CoeffIdZero, system.CoeffID(&c), compiled.CoeffIdZero, rc.debug)
+			system.addLookupConstraint(table, [3]compiled.Term{r, r, r}, rc.debug)
+			x = q
+		}
+
+		w := system.lookupWire(x)
+		system.addLookupConstraint(table, [3]compiled.Term{w, w, w}, rc.debug)
+		if bits < 8 {
+			w = system.lookupWire(system.Mul(x, 1<<(8-bits)))
+			system.addLookupConstraint(table, [3]compiled.Term{w, w, w}, rc.debug)
+		}
+	}
+	system.rangeChecks = nil
+}
+
+// termKey returns a string identifying the term
+func termKey(t compiled.Term) string {
+	return strconv.FormatUint(uint64(t), 16)
+}
+
+// byteTable returns the ID of the lookup table of the 256 bytes, which is registered on
+// first use
+func (system *sparseR1CS) byteTable() int {
+	if system.rangeTable < 0 {
+		rows := make([][]frontend.Variable, 256)
+		for i := 0; i < len(rows); i++ {
+			rows[i] = []frontend.Variable{i}
+		}
+		system.rangeTable = system.NewLookupTable(rows...)
+	}
+	return system.rangeTable
+}
+
 func (system *sparseR1CS) mustBeLessOrEqVar(a compiled.Term, bound compiled.Term) {
 
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", bound)
@@ -162,6 +263,15 @@ func (system *sparseR1CS) mustBeLessOrEqCst(a compiled.Term, bound big.Int) {
 	// (as opposed to ToBinary)
 	aBits := system.toBinary(a, nbBits, true)
 
+	system.mustBeLessOrEqCstBits(aBits, bound, debug)
+}
+
+// mustBeLessOrEqCstBits asserts that the number whose bits (little endian) are aBits is
+// less or equal to bound. The bits are boolean constrained by the assertion.
+func (system *sparseR1CS) mustBeLessOrEqCstBits(aBits []frontend.Variable, bound big.Int, debug int) {
+
+	nbBits := len(aBits)
+
 	// t trailing bits in the bound
 	t := 0
 	for i := 0; i < nbBits; i++ {
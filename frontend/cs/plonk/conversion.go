@@ -30,9 +30,14 @@ import (
 
 func (cs *sparseR1CS) Compile() (frontend.CompiledConstraintSystem, error) {
 
+	// add the range checks, batched during the circuit definition
+	cs.addRangeChecks()
+
 	res := compiled.SparseR1CS{
 		CS:          cs.CS,
 		Constraints: cs.Constraints,
+		Tables:      cs.Tables,
+		MLookups:    cs.MLookups,
 	}
 	res.NbPublicVariables = len(cs.Public)
 	res.NbSecretVariables = len(cs.Secret)
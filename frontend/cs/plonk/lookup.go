@@ -0,0 +1,138 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plonk
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// LookupAPI is implemented by the PlonK builder. It enables a circuit to assert
+// that some values form a row of a table with a single lookup constraint,
+// instead of expressing the relation arithmetically.
+//
+// Typical use is to range check a value (table of all the values in the range),
+// or to constrain a bitwise operation (truth table of the operation):
+//
+//		if l, ok := api.(plonk.LookupAPI); ok {
+//			table := l.NewLookupTable(rows...)
+//			l.AssertIsInTable(table, a, b, c)
+//		} else {
+//			// arithmetic version of the constraint
+//		}
+type LookupAPI interface {
+	frontend.API
+
+	// NewLookupTable registers a table and returns its ID. A row has 1 to 3
+	// constant entries; rows with less than 3 entries are completed by repeating
+	// their first entry. Duplicated rows are ignored.
+	NewLookupTable(rows ...[]frontend.Variable) int
+
+	// AssertIsInTable fails if values is not a row of the table
+	AssertIsInTable(table int, values ...frontend.Variable)
+}
+
+// NewLookupTable registers a table and returns its ID. A row has 1 to 3
+// constant entries; rows with less than 3 entries are completed by repeating
+// their first entry. Duplicated rows are ignored.
+func (system *sparseR1CS) NewLookupTable(rows ...[]frontend.Variable) int {
+	if len(rows) == 0 {
+		panic("lookup table is empty")
+	}
+	modulus := system.CurveID.Info().Fr.Modulus()
+
+	table := make(compiled.LookupTable, 0, len(rows))
+	seen := make(map[[3]int]struct{}, len(rows))
+	for _, row := range rows {
+		if len(row) == 0 || len(row) > 3 {
+			panic(fmt.Sprintf("lookup table rows have 1 to 3 entries, got %d", len(row)))
+		}
+		var r [3]int
+		for i := 0; i < 3; i++ {
+			e := row[0]
+			if i < len(row) {
+				e = row[i]
+			}
+			if !system.IsConstant(e) {
+				panic("lookup table entries must be constants")
+			}
+			v := utils.FromInterface(e)
+			v.Mod(&v, modulus)
+			r[i] = system.CoeffID(&v)
+		}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		table = append(table, r)
+	}
+
+	system.Tables = append(system.Tables, table)
+	return len(system.Tables) - 1
+}
+
+// AssertIsInTable fails if values is not a row of the table. It adds a single
+// lookup constraint, plus one constraint per value that is a constant or a
+// scaled variable.
+func (system *sparseR1CS) AssertIsInTable(table int, values ...frontend.Variable) {
+	if table < 0 || table >= len(system.Tables) {
+		panic(fmt.Sprintf("unknown lookup table %d", table))
+	}
+	if len(values) == 0 || len(values) > 3 {
+		panic(fmt.Sprintf("lookup values have 1 to 3 entries, got %d", len(values)))
+	}
+
+	var lro [3]compiled.Term
+	for i := 0; i < 3; i++ {
+		if i < len(values) {
+			lro[i] = system.lookupWire(values[i])
+		} else {
+			lro[i] = lro[0]
+		}
+	}
+
+	debug := system.AddDebugInfo("assertIsInTable", "(", lro[0], ", ", lro[1], ", ", lro[2], ") in table ", table)
+	system.addLookupConstraint(table, lro, debug)
+}
+
+// addLookupConstraint adds the lookup of the values of the wires lro in the table
+func (system *sparseR1CS) addLookupConstraint(table int, lro [3]compiled.Term, debug int) {
+	system.MLookups[len(system.Constraints)] = table
+	system.addPlonkConstraint(lro[0], lro[1], lro[2], compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, debug)
+}
+
+// lookupWire returns a wire whose value is v. The lookup argument applies to the
+// value of the wires, ignoring the coefficients of the terms, so constants and
+// scaled variables are first assigned to a new wire.
+func (system *sparseR1CS) lookupWire(v frontend.Variable) compiled.Term {
+	if system.IsConstant(v) {
+		res := system.newInternalVariable()
+		k := utils.FromInterface(v)
+		system.addPlonkConstraint(system.zero(), system.zero(), res, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdMinusOne, system.CoeffID(&k))
+		return res
+	}
+	t := v.(compiled.Term)
+	if t.CoeffID() == compiled.CoeffIdOne {
+		return t
+	}
+	res := system.newInternalVariable()
+	system.addPlonkConstraint(t, system.zero(), res, t.CoeffID(), compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdZero, compiled.CoeffIdMinusOne, compiled.CoeffIdZero)
+	return res
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plonk_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/plonk"
+	"github.com/consensys/gnark/test"
+)
+
+// lookupCircuit checks that X is a byte, and that Z = Y xor W where Y, W are on 2 bits
+type lookupCircuit struct {
+	X, Y, W frontend.Variable
+	Z       frontend.Variable `gnark:",public"`
+}
+
+func (circuit *lookupCircuit) Define(api frontend.API) error {
+	l, ok := api.(plonk.LookupAPI)
+	if !ok {
+		api.ToBinary(circuit.X, 8)
+		y := api.ToBinary(circuit.Y, 2)
+		w := api.ToBinary(circuit.W, 2)
+		z := api.FromBinary(api.Xor(y[0], w[0]), api.Xor(y[1], w[1]))
+		api.AssertIsEqual(circuit.Z, z)
+		return nil
+	}
+
+	rangeRows := make([][]frontend.Variable, 256)
+	for i := 0; i < len(rangeRows); i++ {
+		rangeRows[i] = []frontend.Variable{i}
+	}
+	byteTable := l.NewLookupTable(rangeRows...)
+
+	var xorRows [][]frontend.Variable
+	for a := 0; a < 4; a++ {
+		for b := 0; b < 4; b++ {
+			xorRows = append(xorRows, []frontend.Variable{a, b, a ^ b})
+		}
+	}
+	xorTable := l.NewLookupTable(xorRows...)
+
+	l.AssertIsInTable(byteTable, circuit.X)
+	l.AssertIsInTable(byteTable, api.Mul(circuit.Y, 2)) // scaled variable
+	l.AssertIsInTable(xorTable, circuit.Y, circuit.W, circuit.Z)
+	l.AssertIsInTable(xorTable, 1, 2, 3) // constants
+
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit lookupCircuit
+
+	assert.ProverSucceeded(&circuit, &lookupCircuit{
+		X: 200,
+		Y: 3,
+		W: 1,
+		Z: 2,
+	}, test.WithBackends(backend.PLONK), test.WithCurves(ecc.BN254))
+
+	assert.ProverFailed(&circuit, &lookupCircuit{
+		X: 256,
+		Y: 3,
+		W: 1,
+		Z: 2,
+	}, test.WithBackends(backend.PLONK), test.WithCurves(ecc.BN254))
+
+	assert.ProverFailed(&circuit, &lookupCircuit{
+		X: 200,
+		Y: 3,
+		W: 1,
+		Z: 1,
+	}, test.WithBackends(backend.PLONK), test.WithCurves(ecc.BN254))
+}
+
+// rangeCheckCircuit range checks X on each of the sizes in bits
+type rangeCheckCircuit struct {
+	X    frontend.Variable
+	bits []int
+}
+
+func (circuit *rangeCheckCircuit) Define(api frontend.API) error {
+	for _, b := range circuit.bits {
+		api.RangeCheck(circuit.X, b)
+	}
+	return nil
+}
+
+func TestRangeCheckBatched(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	once, err := frontend.Compile(ecc.BN254, backend.PLONK, &rangeCheckCircuit{bits: []int{8}})
+	assert.NoError(err)
+	several, err := frontend.Compile(ecc.BN254, backend.PLONK, &rangeCheckCircuit{bits: []int{16, 8, 12}})
+	assert.NoError(err)
+
+	// X is decomposed once, on 8 bits
+	assert.Equal(once.GetNbConstraints(), several.GetNbConstraints())
+}
@@ -38,6 +38,16 @@ type sparseR1CS struct {
 	cs.ConstraintSystem
 
 	Constraints []compiled.SparseR1C
+
+	// lookup tables, and constraint ID -> table ID for the lookup constraints
+	Tables   []compiled.LookupTable
+	MLookups map[int]int
+
+	// ID of the table of the bytes used by RangeCheck, -1 until it is registered
+	rangeTable int
+
+	// terms to range check, decomposed by Compile (see RangeCheck)
+	rangeChecks []rangeCheck
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -58,9 +68,12 @@ func newSparseR1CS(curveID ecc.ID, initialCapacity ...int) *sparseR1CS {
 			Coeffs:         make([]big.Int, 4),
 			CoeffsIDsLarge: make(map[string]int),
 			CoeffsIDsInt64: make(map[int64]int, 4),
+			MRangeChecks:   make(map[string]int),
 			MTBooleans:     make(map[int]struct{}),
 		},
 		Constraints: make([]compiled.SparseR1C, 0, capacity),
+		MLookups:    make(map[int]int),
+		rangeTable:  -1,
 	}
 
 	system.Coeffs[compiled.CoeffIdZero].SetInt64(0)
@@ -178,6 +191,9 @@ func (system *sparseR1CS) CheckVariables() error {
 
 	// TODO @gbotrel add unit test for that.
 
+	// the range checks constrain their inputs, add them first
+	system.addRangeChecks()
+
 	cptSecret := len(system.Secret)
 	cptPublic := len(system.Public)
 	cptHints := len(system.MHints)
@@ -221,7 +237,13 @@ func (system *sparseR1CS) CheckVariables() error {
 		}
 
 	}
-	for _, c := range system.Constraints {
+	for i, c := range system.Constraints {
+		if _, ok := system.MLookups[i]; ok {
+			// the wires of a lookup constraint have a zero coefficient but are constrained
+			c.L.SetCoeffID(compiled.CoeffIdOne)
+			c.R.SetCoeffID(compiled.CoeffIdOne)
+			c.O.SetCoeffID(compiled.CoeffIdOne)
+		}
 		processTerm(c.L)
 		processTerm(c.R)
 		processTerm(c.M[0])
@@ -19,6 +19,7 @@ package r1cs
 import (
 	"fmt"
 	"math/big"
+	"math/bits"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -28,6 +29,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/parser"
 	"github.com/consensys/gnark/internal/utils"
@@ -187,6 +189,49 @@ func (system *r1CS) Inverse(i1 frontend.Variable) frontend.Variable {
 	return res
 }
 
+// DivMod returns the quotient and the remainder of the integer division of a by b.
+// It fails if a >= 2^bits, b >= 2^bits or b == 0.
+func (system *r1CS) DivMod(a, b frontend.Variable, bits int) (frontend.Variable, frontend.Variable) {
+	// q*b + r < 2^(2*bits) must not overflow
+	if maxBits := (system.BitLen() - 1) / 2; bits < 0 || bits > maxBits {
+		panic(fmt.Sprintf("divMod: bits must be in [0, %d]", maxBits))
+	}
+
+	if system.IsConstant(a) && system.IsConstant(b) {
+		modulus := system.CurveID.Info().Fr.Modulus()
+		n, d := system.ConstantValue(a), system.ConstantValue(b)
+		n.Mod(n, modulus)
+		d.Mod(d, modulus)
+		if n.BitLen() > bits || d.BitLen() > bits || d.Sign() == 0 {
+			panic(fmt.Sprintf("divMod failed: constant(%s) / constant(%s) on %d bits", n.String(), d.String(), bits))
+		}
+		var q, r big.Int
+		q.DivMod(n, d, &r)
+		return q, r
+	}
+
+	res, err := system.NewHint(hint.DivMod, a, b)
+	if err != nil {
+		panic(err)
+	}
+	q, r := res[0], res[1]
+
+	// a == q*b + r, first so that the solver computes q and r before decomposing them
+	vars, _ := system.toVariables(q, b, system.Sub(a, r))
+	debug := system.AddDebugInfo("divMod", vars[0], " * ", vars[1], " == ", vars[2])
+	system.addConstraint(newR1C(vars[0], vars[1], vars[2]), debug)
+
+	system.RangeCheck(a, bits)
+	system.RangeCheck(b, bits)
+	system.RangeCheck(q, bits)
+	system.RangeCheck(r, bits)
+
+	// r < b, which also ensures that b != 0
+	system.RangeCheck(system.Sub(b, r, 1), bits)
+
+	return q, r
+}
+
 // Div returns res = i1 / i2
 func (system *r1CS) Div(i1, i2 frontend.Variable) frontend.Variable {
 	vars, _ := system.toVariables(i1, i2)
@@ -270,7 +315,8 @@ func (system *r1CS) Xor(_a, _b frontend.Variable) frontend.Variable {
 	c := system.Neg(res).(compiled.Variable)
 	c.IsBoolean = new(bool)
 	*c.IsBoolean = false
-	c.LinExp = append(c.LinExp, a.LinExp[0], b.LinExp[0])
+	c.LinExp = append(c.LinExp, a.LinExp...)
+	c.LinExp = append(c.LinExp, b.LinExp...)
 	aa := system.Mul(a, 2)
 	system.Constraints = append(system.Constraints, newR1C(aa, b, c))
 
@@ -294,7 +340,8 @@ func (system *r1CS) Or(_a, _b frontend.Variable) frontend.Variable {
 	c := system.Neg(res).(compiled.Variable)
 	c.IsBoolean = new(bool)
 	*c.IsBoolean = false
-	c.LinExp = append(c.LinExp, a.LinExp[0], b.LinExp[0])
+	c.LinExp = append(c.LinExp, a.LinExp...)
+	c.LinExp = append(c.LinExp, b.LinExp...)
 	system.Constraints = append(system.Constraints, newR1C(a, b, c))
 
 	return res
@@ -350,6 +397,50 @@ func (system *r1CS) IsZero(i1 frontend.Variable) frontend.Variable {
 
 }
 
+// Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2, i1 and i2 being compared as
+// integers in [0, r)
+func (system *r1CS) Cmp(i1, i2 frontend.Variable) frontend.Variable {
+	if system.IsConstant(i1) && system.IsConstant(i2) {
+		modulus := system.CurveID.Info().Fr.Modulus()
+		a, b := system.ConstantValue(i1), system.ConstantValue(i2)
+		a.Mod(a, modulus)
+		b.Mod(b, modulus)
+		return a.Cmp(b)
+	}
+
+	bi1 := system.toCanonicalBinary(i1)
+	bi2 := system.toCanonicalBinary(i2)
+
+	// going from the most significant bit, res is set to bi1[i] - bi2[i] at the first bit
+	// where bi1 and bi2 differ: 1 - res² is 1 while res == 0, and 0 afterwards
+	var res frontend.Variable = 0
+	for i := len(bi1) - 1; i >= 0; i-- {
+		undecided := system.Sub(1, system.Mul(res, res))
+		res = system.Add(res, system.Mul(undecided, system.Sub(bi1[i], bi2[i])))
+	}
+	return res
+}
+
+// toCanonicalBinary is equivalent to ToBinary(a), except the bits are constrained to
+// be the decomposition of a in [0, r), and not of a + r.
+func (system *r1CS) toCanonicalBinary(a frontend.Variable) []frontend.Variable {
+	nbBits := system.BitLen()
+	if system.IsConstant(a) {
+		c := system.ConstantValue(a)
+		c.Mod(c, system.CurveID.Info().Fr.Modulus())
+		return system.ToBinary(c, nbBits)
+	}
+
+	var bound big.Int
+	bound.Sub(system.CurveID.Info().Fr.Modulus(), big.NewInt(1))
+	debug := system.AddDebugInfo("toCanonicalBinary", a, " <= ", bound)
+
+	vars, _ := system.toVariables(a)
+	bits := system.toBinary(vars[0], nbBits, true)
+	system.mustBeLessOrEqCstBits(bits, bound, debug)
+	return bits
+}
+
 // ToBinary unpacks a frontend.Variable in binary,
 // n is the number of bits to select (starting from lsb)
 // n default value is fr.Bits the number of bits needed to represent a field element
@@ -413,7 +504,7 @@ func (system *r1CS) toBinary(a compiled.Variable, nbBits int, unsafe bool) []fro
 	//var Σbi compiled.Variable
 	var Σbi frontend.Variable
 	if nbBits == 1 {
-		system.AssertIsEqual(sb[0], a)
+		Σbi = sb[0]
 	} else if nbBits == 2 {
 		Σbi = system.Add(sb[0], sb[1])
 	} else {
@@ -529,6 +620,42 @@ func (system *r1CS) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Va
 	return res
 }
 
+// Lookup returns table[index]. index must be in [0, len(table)), the
+// constraint system is not satisfiable otherwise.
+func (system *r1CS) Lookup(index frontend.Variable, table []frontend.Variable) frontend.Variable {
+	if len(table) == 0 {
+		panic("lookup in an empty table")
+	}
+
+	if system.IsConstant(index) {
+		i := system.ConstantValue(index)
+		if !i.IsUint64() || i.Uint64() >= uint64(len(table)) {
+			panic(fmt.Sprintf("lookup index %s out of range [0, %d)", i.String(), len(table)))
+		}
+		return table[i.Uint64()]
+	}
+
+	if len(table) == 1 {
+		system.AssertIsEqual(index, 0)
+		return table[0]
+	}
+
+	// decompose the index; if the table size is not a power of 2 the
+	// decomposition alone does not bound the index, so its bits are also
+	// compared with len(table)-1
+	nbBits := bits.Len(uint(len(table) - 1))
+	if len(table) == 1<<nbBits {
+		return cs.LookupBits(system, system.ToBinary(index, nbBits), table)
+	}
+	vars, _ := system.toVariables(index)
+	i := vars[0]
+	b := system.toBinary(i, nbBits, true)
+	bound := *big.NewInt(int64(len(table) - 1))
+	debug := system.AddDebugInfo("lookup", i, " <= ", system.constant(bound))
+	system.mustBeLessOrEqCstBits(b, bound, debug)
+	return cs.LookupBits(system, b, table)
+}
+
 // IsConstant returns true if v is a constant known at compile time
 func (system *r1CS) IsConstant(v frontend.Variable) bool {
 	if _v, ok := v.(compiled.Variable); ok {
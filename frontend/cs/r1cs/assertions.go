@@ -19,7 +19,10 @@ package r1cs
 import (
 	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/utils"
@@ -88,6 +91,101 @@ func (system *r1CS) AssertIsLessOrEqual(_v frontend.Variable, bound frontend.Var
 
 }
 
+// RangeCheck fails if v >= 2^bits
+//
+// The check is only recorded here, with the smallest number of bits v is checked on, and
+// Compile decomposes v once (see addRangeChecks). v is decomposed in bits, except for the
+// most significant bit which is the remainder of the decomposition: the check costs bits
+// constraints, instead of bits+1 for ToBinary.
+func (system *r1CS) RangeCheck(i1 frontend.Variable, bits int) {
+	if bits < 0 {
+		panic("invalid bit size")
+	}
+	if bits >= system.BitLen() {
+		return // all the field elements are less than 2^bits
+	}
+
+	vars, _ := system.toVariables(i1)
+	v := vars[0]
+
+	if v.IsConstant() {
+		c := system.constantValue(v)
+		if c.BitLen() > bits {
+			panic(fmt.Sprintf("rangeCheck failed: constant(%s) >= 2^%d", c.String(), bits))
+		}
+		return
+	}
+
+	if !system.RecordRangeCheck(linExpKey(v.LinExp), bits) {
+		return // v is already recorded, on the smallest number of bits
+	}
+	system.rangeChecks = append(system.rangeChecks, rangeCheck{
+		v:     v,
+		debug: system.AddDebugInfo("rangeCheck", v),
+	})
+}
+
+// rangeCheck is an expression recorded by RangeCheck, to be decomposed by addRangeChecks
+type rangeCheck struct {
+	v     compiled.Variable
+	debug int // debug info of the first call to RangeCheck on v
+}
+
+// addRangeChecks decomposes the expressions recorded by RangeCheck, each on the smallest
+// number of bits it was checked on. It is called by CheckVariables and Compile, the
+// expressions are decomposed only once.
+func (system *r1CS) addRangeChecks() {
+	for _, rc := range system.rangeChecks {
+		bits := system.MRangeChecks[linExpKey(rc.v.LinExp)]
+		if bits == 0 {
+			system.addConstraint(newR1C(system.one(), rc.v, system.constant(0)), rc.debug)
+			continue
+		}
+
+		// r = v - Σ (2**i * b[i]) for i < bits-1
+		r := frontend.Variable(rc.v)
+		if bits > 1 {
+			sb := make([]frontend.Variable, bits-1)
+			var c big.Int
+			c.SetUint64(1)
+			for i := 0; i < len(sb); i++ {
+				res, err := system.NewHint(hint.IthBit, rc.v, i)
+				if err != nil {
+					panic(err)
+				}
+				system.assertIsBoolean(res[0].(compiled.Variable), rc.debug)
+				sb[i] = system.Mul(res[0], c)
+				c.Lsh(&c, 1)
+			}
+			r = system.Sub(rc.v, sb[0], sb[1:]...)
+		}
+
+		// r / 2**(bits-1) is the most significant bit
+		var c big.Int
+		c.Lsh(big.NewInt(1), uint(bits-1)).ModInverse(&c, system.CurveID.Info().Fr.Modulus())
+		system.assertIsBoolean(system.Mul(r, c).(compiled.Variable), rc.debug)
+	}
+	system.rangeChecks = nil
+}
+
+// assertIsBoolean adds the constraint v * (1 - v) == 0, with the given debug info
+func (system *r1CS) assertIsBoolean(v compiled.Variable, debug int) {
+	if !system.markBoolean(v) {
+		return
+	}
+	system.addConstraint(newR1C(v, system.Sub(1, v), system.constant(0)), debug)
+}
+
+// linExpKey returns a string identifying the linear expression
+func linExpKey(l compiled.LinearExpression) string {
+	var sbb strings.Builder
+	for _, t := range l {
+		sbb.WriteString(strconv.FormatUint(uint64(t), 16))
+		sbb.WriteByte(',')
+	}
+	return sbb.String()
+}
+
 func (system *r1CS) mustBeLessOrEqVar(a, bound compiled.Variable) {
 	debug := system.AddDebugInfo("mustBeLessOrEq", a, " <= ", bound)
 
@@ -148,6 +246,15 @@ func (system *r1CS) mustBeLessOrEqCst(a compiled.Variable, bound big.Int) {
 	// (as opposed to ToBinary)
 	aBits := system.toBinary(a, nbBits, true)
 
+	system.mustBeLessOrEqCstBits(aBits, bound, debug)
+}
+
+// mustBeLessOrEqCstBits asserts that the number whose bits (little endian) are aBits is
+// less or equal to bound. The bits are boolean constrained by the assertion.
+func (system *r1CS) mustBeLessOrEqCstBits(aBits []frontend.Variable, bound big.Int, debug int) {
+
+	nbBits := len(aBits)
+
 	// t trailing bits in the bound
 	t := 0
 	for i := 0; i < nbBits; i++ {
@@ -31,6 +31,9 @@ import (
 // Compile constructs a rank-1 constraint sytem
 func (cs *r1CS) Compile() (frontend.CompiledConstraintSystem, error) {
 
+	// add the range checks, batched during the circuit definition
+	cs.addRangeChecks()
+
 	// wires = public wires  | secret wires | internal wires
 
 	// setting up the result
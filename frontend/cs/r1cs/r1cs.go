@@ -38,6 +38,9 @@ type r1CS struct {
 	cs.ConstraintSystem
 
 	Constraints []compiled.R1C
+
+	// expressions to range check, decomposed by Compile (see RangeCheck)
+	rangeChecks []rangeCheck
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -58,6 +61,7 @@ func newR1CS(curveID ecc.ID, initialCapacity ...int) *r1CS {
 			Coeffs:         make([]big.Int, 4),
 			CoeffsIDsLarge: make(map[string]int),
 			CoeffsIDsInt64: make(map[int64]int, 4),
+			MRangeChecks:   make(map[string]int),
 		},
 		Constraints: make([]compiled.R1C, 0, capacity),
 
@@ -223,6 +227,9 @@ func (system *r1CS) CheckVariables() error {
 
 	// TODO @gbotrel add unit test for that.
 
+	// the range checks constrain their inputs, add them first
+	system.addRangeChecks()
+
 	cptSecret := len(system.Secret)
 	cptPublic := len(system.Public)
 	cptHints := len(system.MHints)
@@ -69,3 +69,29 @@ func TestReduce(t *testing.T) {
 	}
 
 }
+
+func TestRangeCheckBatched(t *testing.T) {
+
+	cs := newR1CS(ecc.BN254)
+	x := cs.newInternalVariable()
+	y := cs.newInternalVariable()
+
+	cs.RangeCheck(x, 64)
+	cs.RangeCheck(y, 8)
+	cs.RangeCheck(x, 16)
+	cs.RangeCheck(x, 32)
+	cs.RangeCheck(y, 12)
+
+	if len(cs.Constraints) != 0 {
+		t.Fatal("range checks should be added by Compile")
+	}
+	if _, err := cs.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// x is decomposed once, on 16 bits, and y on 8 bits
+	if len(cs.Constraints) != 16+8 {
+		t.Fatalf("expected %d constraints, got %d", 16+8, len(cs.Constraints))
+	}
+
+}
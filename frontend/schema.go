@@ -0,0 +1,186 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/parser"
+)
+
+// Visibility of the inputs of a circuit in a Schema
+const (
+	VisibilityPublic = "public"
+	VisibilitySecret = "secret"
+)
+
+// Kinds of the fields of a Schema
+const (
+	KindVariable = "variable"
+	KindStruct   = "struct"
+	KindArray    = "array"
+)
+
+// Schema describes the inputs of a circuit, as allocated by Compile and encoded in
+// the witness (see package backend/witness)
+type Schema struct {
+	// Public and Secret are the names of the public and secret variables, in the order
+	// of the witness
+	Public []string `json:"public"`
+	Secret []string `json:"secret"`
+
+	// Fields is the structure of the circuit: the fields holding variables, with their
+	// nested fields and elements
+	Fields []Field `json:"fields"`
+}
+
+// Field is a field of a circuit structure, or an element of an array or a slice
+type Field struct {
+	Name       string  `json:"name"`             // name of the field (from its gnark tag if set), or index of the element
+	FullName   string  `json:"fullName"`         // name of the variable (KindVariable) or prefix of the nested names
+	Tag        string  `json:"tag,omitempty"`    // gnark tag of the field
+	Kind       string  `json:"kind"`             // KindVariable, KindStruct or KindArray
+	Visibility string  `json:"visibility"`       // VisibilityPublic or VisibilitySecret
+	Fields     []Field `json:"fields,omitempty"` // nested fields (KindStruct) or elements (KindArray)
+}
+
+// NewSchema returns the schema of the circuit. Slices of the circuit must be allocated,
+// like for Compile; the values of the variables are ignored.
+func NewSchema(circuit Circuit) (*Schema, error) {
+	s := &Schema{Public: []string{}, Secret: []string{}}
+
+	collectHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
+		if visibility == compiled.Public {
+			s.Public = append(s.Public, name)
+		} else if visibility == compiled.Secret {
+			s.Secret = append(s.Secret, name)
+		}
+		return nil
+	}
+	if err := parser.Visit(circuit, "", compiled.Unset, collectHandler, tVariable); err != nil {
+		return nil, err
+	}
+
+	fields, err := schemaFields(reflect.ValueOf(circuit), "", compiled.Unset)
+	if err != nil {
+		return nil, err
+	}
+	s.Fields = fields
+	return s, nil
+}
+
+// ToJSON returns the JSON encoding of the schema
+func (s *Schema) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "    ")
+}
+
+// schemaFields returns the fields of the struct v holding variables, following the
+// traversal of parser.Visit
+func schemaFields(v reflect.Value, baseName string, parentVisibility compiled.Visibility) ([]Field, error) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var fields []Field
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, visibility, ok, err := parser.FieldInfo(field, parentVisibility)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		f := v.Field(i)
+		if !f.CanAddr() || !f.Addr().CanInterface() {
+			continue // unexported field
+		}
+
+		fullName := parser.AppendName(baseName, name)
+		if name == "" {
+			// embedded fields are inlined
+			embedded, err := schemaFields(f, fullName, visibility)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+
+		sf, ok, err := schemaField(f, name, fullName, visibility)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			sf.Tag = field.Tag.Get("gnark")
+			fields = append(fields, sf)
+		}
+	}
+	return fields, nil
+}
+
+// schemaField returns the description of v, ok being false if v holds no variable
+func schemaField(v reflect.Value, name, fullName string, visibility compiled.Visibility) (f Field, ok bool, err error) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	f = Field{Name: name, FullName: fullName, Visibility: visibilityName(visibility)}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.Type() != tVariable {
+			return f, false, nil
+		}
+		f.Kind = KindVariable
+	case reflect.Struct:
+		f.Kind = KindStruct
+		if f.Fields, err = schemaFields(v, fullName, visibility); err != nil {
+			return f, false, err
+		}
+	case reflect.Slice, reflect.Array:
+		f.Kind = KindArray
+		for j := 0; j < v.Len(); j++ {
+			e, ok, err := schemaField(v.Index(j), strconv.Itoa(j), parser.AppendName(fullName, strconv.Itoa(j)), visibility)
+			if err != nil {
+				return f, false, err
+			}
+			if ok {
+				f.Fields = append(f.Fields, e)
+			}
+		}
+	default:
+		return f, false, nil
+	}
+
+	if f.Kind != KindVariable && len(f.Fields) == 0 {
+		return f, false, nil
+	}
+	return f, true, nil
+}
+
+func visibilityName(visibility compiled.Visibility) string {
+	if visibility == compiled.Public {
+		return VisibilityPublic
+	}
+	return VisibilitySecret
+}
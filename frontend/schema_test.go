@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type point struct {
+	X, Y Variable
+}
+
+type Common struct {
+	Nonce Variable `gnark:",public"`
+}
+
+type schemaCircuit struct {
+	Common `gnark:",embed"`
+	Points [2]point   `gnark:"p,public"`
+	Scalar Variable   `gnark:"s"`
+	Bits   []Variable // secret
+	Alias  Variable   `gnark:"-"`
+	Count  int
+	hidden Variable
+}
+
+func (circuit *schemaCircuit) Define(api API) error {
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	circuit := schemaCircuit{Bits: make([]Variable, 2)}
+	schema, err := NewSchema(&circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(schema.Public, []string{"Nonce", "p_0_X", "p_0_Y", "p_1_X", "p_1_Y"}) {
+		t.Fatal("wrong public variables", schema.Public)
+	}
+	if !reflect.DeepEqual(schema.Secret, []string{"s", "Bits_0", "Bits_1"}) {
+		t.Fatal("wrong secret variables", schema.Secret)
+	}
+
+	variable := func(name, fullName, visibility string) Field {
+		return Field{Name: name, FullName: fullName, Kind: KindVariable, Visibility: visibility}
+	}
+	pointField := func(i string) Field {
+		return Field{Name: i, FullName: "p_" + i, Kind: KindStruct, Visibility: VisibilityPublic, Fields: []Field{
+			variable("X", "p_"+i+"_X", VisibilityPublic),
+			variable("Y", "p_"+i+"_Y", VisibilityPublic),
+		}}
+	}
+	nonce := variable("Nonce", "Nonce", VisibilityPublic)
+	nonce.Tag = ",public"
+	scalar := variable("s", "s", VisibilitySecret)
+	scalar.Tag = "s"
+	expected := []Field{
+		nonce,
+		{Name: "p", FullName: "p", Tag: "p,public", Kind: KindArray, Visibility: VisibilityPublic, Fields: []Field{pointField("0"), pointField("1")}},
+		scalar,
+		{Name: "Bits", FullName: "Bits", Kind: KindArray, Visibility: VisibilitySecret, Fields: []Field{
+			variable("0", "Bits_0", VisibilitySecret),
+			variable("1", "Bits_1", VisibilitySecret),
+		}},
+	}
+	if !reflect.DeepEqual(schema.Fields, expected) {
+		t.Fatal("wrong fields", schema.Fields)
+	}
+
+	// JSON round trip
+	data, err := schema.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Schema
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*schema, decoded) {
+		t.Fatal("schema doesn't survive a JSON round trip")
+	}
+}
@@ -10,6 +10,7 @@ require (
 	github.com/kr/pretty v0.2.0 // indirect
 	github.com/leanovate/gopter v0.2.9
 	github.com/stretchr/testify v1.7.0
+	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
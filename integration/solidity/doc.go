@@ -0,0 +1,7 @@
+// Package solidity runs the solidity verifiers exported by gnark in an EVM.
+//
+// It is a separate module, so that gnark does not depend on go-ethereum. The tests
+// executing the contracts compile them with solc, and are skipped if it is not in the PATH
+// (unless GNARK_REQUIRE_SOLC is set, as in CI, where they fail instead); the groth16 calldata
+// is also checked against the EVM precompiled contracts without solc.
+package solidity
@@ -0,0 +1,11 @@
+module github.com/consensys/gnark/integration/solidity
+
+go 1.16
+
+require (
+	github.com/consensys/gnark v0.5.2
+	github.com/consensys/gnark-crypto v0.5.4-0.20211222202820-aee0c136fb9f
+	github.com/ethereum/go-ethereum v1.10.17
+)
+
+replace github.com/consensys/gnark => ../../
@@ -0,0 +1,194 @@
+package solidity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+func TestGroth16(t *testing.T) {
+	vk, calldata := groth16Calldata(t)
+
+	var contract bytes.Buffer
+	if err := vk.ExportSolidity(&contract); err != nil {
+		t.Fatal(err)
+	}
+	code := compile(t, lookSolc(t), contract.Bytes(), "Verifier")
+	verifyProof := func(calldata []byte) (bool, error) {
+		return call(code, append(selector(fmt.Sprintf("verifyProof(uint256[2],uint256[2][2],uint256[2],uint256[%d])", vk.NbPublicWitness())), calldata...))
+	}
+
+	for name, tc := range groth16Cases(calldata) {
+		ok, err := verifyProof(tc.calldata)
+		if tc.ok && (err != nil || !ok) {
+			t.Fatalf("%s: proof rejected (%v)", name, err)
+		}
+		if !tc.ok && err == nil && ok {
+			t.Fatalf("%s: proof accepted", name)
+		}
+	}
+}
+
+// TestGroth16Precompiles runs the steps of the solidity verifier on the bytes returned by
+// groth16.MarshalSolidity, with the precompiled contracts of the EVM. Unlike TestGroth16,
+// it does not need solc.
+func TestGroth16Precompiles(t *testing.T) {
+	vk, calldata := groth16Calldata(t)
+
+	for name, tc := range groth16Cases(calldata) {
+		ok, err := verifyWithPrecompiles(vk.(*groth16_bn254.VerifyingKey), tc.calldata)
+		if tc.ok && (err != nil || !ok) {
+			t.Fatalf("%s: proof rejected (%v)", name, err)
+		}
+		if !tc.ok && err == nil && ok {
+			t.Fatalf("%s: proof accepted", name)
+		}
+	}
+}
+
+// groth16Calldata returns a verifying key, and the encoding of a valid proof and its public
+// witness returned by groth16.MarshalSolidity
+func groth16Calldata(t *testing.T) (groth16.VerifyingKey, []byte) {
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubicCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, &cubicCircuit{X: 3, Y: 27, Z: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calldata, err := groth16.MarshalSolidity(proof, &cubicCircuit{Y: 27, Z: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vk, calldata
+}
+
+type groth16Case struct {
+	calldata []byte
+	ok       bool
+}
+
+// groth16Cases returns the valid calldata, and tampered versions of it
+func groth16Cases(calldata []byte) map[string]groth16Case {
+	tamper := func(f func(b []byte)) []byte {
+		res := make([]byte, len(calldata))
+		copy(res, calldata)
+		f(res)
+		return res
+	}
+	return map[string]groth16Case{
+		"valid": {calldata, true},
+		"wrong public input": {tamper(func(b []byte) {
+			b[len(b)-33] ^= 1
+		}), false},
+		// the precompiles expect the imaginary part of G2 coordinates first
+		"swapped G2 coordinates": {tamper(func(b []byte) {
+			copy(b[0x40:0x60], calldata[0x60:0x80])
+			copy(b[0x60:0x80], calldata[0x40:0x60])
+		}), false},
+		"input out of range": {tamper(func(b []byte) {
+			fr.Modulus().FillBytes(b[len(b)-32:])
+		}), false},
+	}
+}
+
+var (
+	ecAdd     = vm.PrecompiledContractsIstanbul[common.BytesToAddress([]byte{6})]
+	ecMul     = vm.PrecompiledContractsIstanbul[common.BytesToAddress([]byte{7})]
+	ecPairing = vm.PrecompiledContractsIstanbul[common.BytesToAddress([]byte{8})]
+)
+
+// verifyWithPrecompiles mirrors the verifyProof method of the solidity verifier, calling the
+// precompiled contracts with the words of the ABI encoded arguments (a, b, c, input)
+func verifyWithPrecompiles(vk *groth16_bn254.VerifyingKey, calldata []byte) (bool, error) {
+	nbInputs := len(vk.G1.K) - 1
+	if len(calldata) != (8+nbInputs)*32 {
+		return false, errors.New("wrong calldata size")
+	}
+	for i := 0; i < 8; i++ {
+		if new(big.Int).SetBytes(calldata[i*32:(i+1)*32]).Cmp(fp.Modulus()) >= 0 {
+			return false, errors.New("verifier-gte-prime-q")
+		}
+	}
+	a, b, c := calldata[0x00:0x40], calldata[0x40:0xc0], calldata[0xc0:0x100]
+	input := calldata[0x100:]
+
+	// vk_x
+	vkX := make([]byte, 64)
+	for i := 0; i < nbInputs; i++ {
+		s := input[i*32 : (i+1)*32]
+		if new(big.Int).SetBytes(s).Cmp(fr.Modulus()) >= 0 {
+			return false, errors.New("verifier-gte-snark-scalar-field")
+		}
+		p, err := ecMul.Run(append(g1Bytes(&vk.G1.K[i+1]), s...))
+		if err != nil {
+			return false, err
+		}
+		if vkX, err = ecAdd.Run(append(vkX, p...)); err != nil {
+			return false, err
+		}
+	}
+	vkX, err := ecAdd.Run(append(vkX, g1Bytes(&vk.G1.K[0])...))
+	if err != nil {
+		return false, err
+	}
+
+	// -a
+	negA := make([]byte, 64)
+	copy(negA, a)
+	if y := new(big.Int).SetBytes(a[32:]); y.Sign() != 0 {
+		y.Sub(fp.Modulus(), y).FillBytes(negA[32:])
+	}
+
+	var pairingInput []byte
+	for _, p := range [][]byte{
+		negA, b,
+		g1Bytes(&vk.G1.Alpha), g2Bytes(&vk.G2.Beta),
+		vkX, g2Bytes(&vk.G2.Gamma),
+		c, g2Bytes(&vk.G2.Delta),
+	} {
+		pairingInput = append(pairingInput, p...)
+	}
+	out, err := ecPairing.Run(pairingInput)
+	if err != nil {
+		return false, err
+	}
+	return out[31] == 1, nil
+}
+
+func g1Bytes(p *curve.G1Affine) []byte {
+	var res []byte
+	for _, e := range []*fp.Element{&p.X, &p.Y} {
+		b := e.Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
+// g2Bytes returns the encoding of p expected by the precompiles, imaginary parts first
+func g2Bytes(p *curve.G2Affine) []byte {
+	var res []byte
+	for _, e := range []*fp.Element{&p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0} {
+		b := e.Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
@@ -0,0 +1,163 @@
+package solidity
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y), circuit.Z)
+	return nil
+}
+
+func TestPlonk(t *testing.T) {
+	solc := lookSolc(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &cubicCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := test.NewKZGSRS(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := plonk.Setup(ccs, srs, backend.WithTranscriptHash(backend.KECCAK256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := plonk.Prove(ccs, pk, &cubicCircuit{X: 3, Y: 27, Z: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var contract bytes.Buffer
+	if err := vk.ExportSolidity(&contract); err != nil {
+		t.Fatal(err)
+	}
+	code := compile(t, solc, contract.Bytes(), "PlonkVerifier")
+
+	encoded, err := plonk.MarshalSolidity(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyProof := func(proof []byte, input ...int64) (bool, error) {
+		return call(code, plonkCalldata(proof, input))
+	}
+
+	if ok, err := verifyProof(encoded, 27, 30); err != nil || !ok {
+		t.Fatalf("valid proof rejected (%v)", err)
+	}
+	if ok, err := verifyProof(encoded, 28, 30); err != nil || ok {
+		t.Fatalf("proof accepted with a wrong public input (%v)", err)
+	}
+	tampered := make([]byte, len(encoded))
+	copy(tampered, encoded)
+	tampered[0x25f] ^= 1
+	if ok, err := verifyProof(tampered, 27, 30); err != nil || ok {
+		t.Fatalf("tampered proof accepted (%v)", err)
+	}
+	if _, err := verifyProof(encoded[:len(encoded)-32], 27, 30); err == nil {
+		t.Fatal("truncated proof accepted")
+	}
+}
+
+// plonkCalldata returns the calldata of verifyProof(bytes proof, uint256[] input)
+func plonkCalldata(proof []byte, input []int64) []byte {
+	// proof is made of 32 bytes words, and needs no padding
+	res := selector("verifyProof(bytes,uint256[])")
+	res = append(res, word(big.NewInt(0x40))...)
+	res = append(res, word(big.NewInt(int64(0x60+len(proof))))...)
+	res = append(res, word(big.NewInt(int64(len(proof))))...)
+	res = append(res, proof...)
+	res = append(res, word(big.NewInt(int64(len(input))))...)
+	for _, v := range input {
+		res = append(res, word(big.NewInt(v))...)
+	}
+	return res
+}
+
+// lookSolc returns the path of solc. If it is not found, the test fails when
+// GNARK_REQUIRE_SOLC is set (as in CI), and is skipped otherwise.
+func lookSolc(t *testing.T) string {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		if os.Getenv("GNARK_REQUIRE_SOLC") != "" {
+			t.Fatal("solc not found, but GNARK_REQUIRE_SOLC is set")
+		}
+		t.Skip("solc not found")
+	}
+	return solc
+}
+
+// compile returns the runtime bytecode of the contract name in source
+func compile(t *testing.T, solc string, source []byte, name string) []byte {
+	path := filepath.Join(t.TempDir(), "verifier.sol")
+	if err := os.WriteFile(path, source, 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(solc, "--optimize", "--combined-json", "bin-runtime", path).Output()
+	if err != nil {
+		t.Fatalf("solc: %v", err)
+	}
+	var result struct {
+		Contracts map[string]struct {
+			BinRuntime string `json:"bin-runtime"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatal(err)
+	}
+	contract, ok := result.Contracts[path+":"+name]
+	if !ok {
+		t.Fatalf("contract %s not found in solc output", name)
+	}
+	code, err := hex.DecodeString(contract.BinRuntime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// call executes code with calldata in an EVM, and decodes the returned boolean
+func call(code, calldata []byte) (bool, error) {
+	ret, _, err := runtime.Execute(code, calldata, &runtime.Config{GasLimit: 30_000_000})
+	if err != nil {
+		return false, err
+	}
+	if len(ret) != 32 {
+		return false, errors.New("unexpected return data")
+	}
+	return new(big.Int).SetBytes(ret).Cmp(big.NewInt(1)) == 0, nil
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func word(v *big.Int) []byte {
+	var res [32]byte
+	v.FillBytes(res[:])
+	return res[:]
+}
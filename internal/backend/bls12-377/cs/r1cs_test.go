@@ -18,13 +18,16 @@ package cs_test
 
 import (
 	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/internal/backend/circuits"
-	"reflect"
-	"testing"
+	"github.com/fxamacker/cbor/v2"
 
 	"github.com/consensys/gnark/internal/backend/bls12-377/cs"
 )
@@ -116,3 +119,56 @@ func TestSerialization(t *testing.T) {
 
 	}
 }
+
+func TestSerializationFormat(t *testing.T) {
+	tc := circuits.Circuits["isZero"]
+	ccs, err := frontend.Compile(ecc.BLS12_377, backend.UNKNOWN, tc.Circuit, frontend.WithBuilder(r1cs.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1cs1 := ccs.(*cs.R1CS)
+
+	// a R1CS encoded in the legacy format is decoded, and migrated by WriteTo
+	enc, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := enc.Marshal(r1cs1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var migrated cs.R1CS
+	if _, err := migrated.ReadFrom(bytes.NewReader(legacy)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r1cs1, &migrated) {
+		t.Fatal("legacy R1CS not decoded")
+	}
+	var buffer bytes.Buffer
+	if _, err := migrated.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buffer.Bytes()
+	var reconstructed cs.R1CS
+	if _, err := reconstructed.ReadFrom(bytes.NewReader(encoded)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r1cs1, &reconstructed) {
+		t.Fatal("migrated R1CS not decoded")
+	}
+
+	// another format version is rejected
+	other := append([]byte{}, encoded...)
+	other[5]++
+	_, err = reconstructed.ReadFrom(bytes.NewReader(other))
+	if err == nil || !strings.Contains(err.Error(), "format version") {
+		t.Fatal("expected a format version error, got", err)
+	}
+
+	// as well as another backend
+	var sparse cs.SparseR1CS
+	_, err = sparse.ReadFrom(bytes.NewReader(encoded))
+	if err == nil || !strings.Contains(err.Error(), "backend") {
+		t.Fatal("expected a backend error, got", err)
+	}
+}
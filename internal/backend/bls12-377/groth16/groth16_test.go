@@ -27,6 +27,9 @@ import (
 
 	"bytes"
 	bls12_377groth16 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark/backend"
@@ -78,6 +81,193 @@ func referenceCircuit() (frontend.CompiledConstraintSystem, frontend.Circuit) {
 	return r1cs, &good
 }
 
+func TestBatchVerify(t *testing.T) {
+	const nbConstraints = 10
+	const nbProofs = 4
+	r1cs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &refCircuit{nbConstraints: nbConstraints}, frontend.WithBuilder(r1cs.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pk bls12_377groth16.ProvingKey
+	var vk bls12_377groth16.VerifyingKey
+	if err := bls12_377groth16.Setup(r1cs.(*cs.R1CS), &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+
+	proofs := make([]*bls12_377groth16.Proof, nbProofs)
+	publicWitnesses := make([]bls12_377witness.Witness, nbProofs)
+	for i := 0; i < nbProofs; i++ {
+		var x, y fr.Element
+		x.SetUint64(uint64(i + 2))
+		y.Set(&x)
+		for j := 0; j < nbConstraints; j++ {
+			y.Mul(&y, &y)
+		}
+		assignment := refCircuit{X: x, Y: y}
+
+		var fullWitness bls12_377witness.Witness
+		if err := fullWitness.FromFullAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		if err := publicWitnesses[i].FromPublicAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		if proofs[i], err = bls12_377groth16.Prove(r1cs.(*cs.R1CS), &pk, fullWitness, backend.ProverOption{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := bls12_377groth16.BatchVerify(proofs, &vk, publicWitnesses); err != nil {
+		t.Fatal(err)
+	}
+
+	// swap two public witnesses: the batch must fail and report the first invalid proof
+	publicWitnesses[1], publicWitnesses[2] = publicWitnesses[2], publicWitnesses[1]
+	err = bls12_377groth16.BatchVerify(proofs, &vk, publicWitnesses)
+	if err == nil {
+		t.Fatal("batch with invalid proofs accepted")
+	}
+	if !strings.HasPrefix(err.Error(), "proof 1:") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := bls12_377groth16.BatchVerify(proofs, &vk, publicWitnesses[:1]); err == nil {
+		t.Fatal("batch with missing public witnesses accepted")
+	}
+}
+
+func TestProveMapped(t *testing.T) {
+	const nbConstraints = 10
+	r1cs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &refCircuit{nbConstraints: nbConstraints}, frontend.WithBuilder(r1cs.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pk bls12_377groth16.ProvingKey
+	var vk bls12_377groth16.VerifyingKey
+	if err := bls12_377groth16.Setup(r1cs.(*cs.R1CS), &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+
+	var x, y fr.Element
+	x.SetUint64(2)
+	y.Set(&x)
+	for j := 0; j < nbConstraints; j++ {
+		y.Mul(&y, &y)
+	}
+	assignment := refCircuit{X: x, Y: y}
+	var fullWitness, publicWitness bls12_377witness.Witness
+	if err := fullWitness.FromFullAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := publicWitness.FromPublicAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mapped key must be encoded without point compression
+	var buf bytes.Buffer
+	if _, err := pk.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bls12_377groth16.NewMappedProvingKey(buf.Bytes()); err == nil {
+		t.Fatal("compressed proving key accepted")
+	}
+
+	path := filepath.Join(t.TempDir(), "pk")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pk.WriteRawTo(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mpk, err := bls12_377groth16.OpenMappedProvingKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mpk.Close()
+	if mpk.NbG1() != pk.NbG1() || mpk.NbG2() != pk.NbG2() {
+		t.Fatal("mapped proving key doesn't have the points of the proving key")
+	}
+
+	// multi-exponentiations split in several chunks, and in a single one
+	for _, chunkSize := range []int{3, 0} {
+		mpk.ChunkSize = chunkSize
+		proof, err := bls12_377groth16.ProveMapped(r1cs.(*cs.R1CS), mpk, fullWitness, backend.ProverOption{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bls12_377groth16.Verify(proof, &vk, publicWitness); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mpk.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bls12_377groth16.ProveMapped(r1cs.(*cs.R1CS), mpk, fullWitness, backend.ProverOption{}); err == nil {
+		t.Fatal("closed proving key used")
+	}
+}
+
+// unusedInputCircuit has an unconstrained secret input U, whose point in [Kpk(t)]1 is the
+// point at infinity
+type unusedInputCircuit struct {
+	U, X frontend.Variable
+	Y    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *unusedInputCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestProveMappedInfinity(t *testing.T) {
+	r1cs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &unusedInputCircuit{}, frontend.WithBuilder(r1cs.NewBuilder), frontend.IgnoreUnconstrainedInputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pk bls12_377groth16.ProvingKey
+	var vk bls12_377groth16.VerifyingKey
+	if err := bls12_377groth16.Setup(r1cs.(*cs.R1CS), &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+	if !pk.G1.K[0].IsInfinity() {
+		t.Fatal("the first point of [Kpk(t)]1 should be the point at infinity")
+	}
+
+	var buf bytes.Buffer
+	if _, err := pk.WriteRawTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	mpk, err := bls12_377groth16.NewMappedProvingKey(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := unusedInputCircuit{U: 1, X: 3, Y: 9}
+	var fullWitness, publicWitness bls12_377witness.Witness
+	if err := fullWitness.FromFullAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := publicWitness.FromPublicAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := bls12_377groth16.ProveMapped(r1cs.(*cs.R1CS), mpk, fullWitness, backend.ProverOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bls12_377groth16.Verify(proof, &vk, publicWitness); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	r1cs, _ := referenceCircuit()
 
@@ -0,0 +1,119 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package aggregation
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+
+	"github.com/consensys/gnark/internal/backend/bls12-381/cs"
+
+	bls12_381witness "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+
+	bls12_381groth16 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// TestTranscriptBindsStatement changes the public witnesses of two proofs while keeping
+// Σrⁱ.wᵢ, which would be accepted if r did not depend on them
+func TestTranscriptBindsStatement(t *testing.T) {
+	ccs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &squareCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pk bls12_381groth16.ProvingKey
+	var vk bls12_381groth16.VerifyingKey
+	if err := bls12_381groth16.Setup(ccs.(*cs.R1CS), &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+	srs, err := NewSRS(2, big.NewInt(42), big.NewInt(43))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proofs := make([]*bls12_381groth16.Proof, 2)
+	publicWitnesses := make([]bls12_381witness.Witness, 2)
+	for i := 0; i < 2; i++ {
+		assignment := squareCircuit{X: i + 2, Y: (i + 2) * (i + 2)}
+		var fullWitness bls12_381witness.Witness
+		if err := fullWitness.FromFullAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		if err := publicWitnesses[i].FromPublicAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		if proofs[i], err = bls12_381groth16.Prove(ccs.(*cs.R1CS), &pk, fullWitness, backend.ProverOption{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	proof, err := Aggregate(srs, &vk, proofs, publicWitnesses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(srs.VerifierSRS(), &vk, proof, publicWitnesses); err != nil {
+		t.Fatal(err)
+	}
+
+	// w'₀ = w₀ + 1, w'₁ = w₁ - r⁻¹: w'₀ + r.w'₁ = w₀ + r.w₁
+	r, err := deriveR(&vk, publicWitnesses, 2, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var one, rInv fr.Element
+	one.SetOne()
+	rInv.Inverse(&r)
+	tampered := []bls12_381witness.Witness{
+		{publicWitnesses[0][0]},
+		{publicWitnesses[1][0]},
+	}
+	tampered[0][0].Add(&tampered[0][0], &one)
+	tampered[1][0].Sub(&tampered[1][0], &rInv)
+
+	var sum, tamperedSum fr.Element
+	sum.Mul(&publicWitnesses[1][0], &r).Add(&sum, &publicWitnesses[0][0])
+	tamperedSum.Mul(&tampered[1][0], &r).Add(&tamperedSum, &tampered[0][0])
+	if !sum.Equal(&tamperedSum) {
+		t.Fatal("Σrⁱ.wᵢ should be unchanged")
+	}
+
+	if err := Verify(srs.VerifierSRS(), &vk, proof, tampered); err == nil {
+		t.Fatal("aggregated proof accepted with public witnesses keeping Σrⁱ.wᵢ")
+	}
+
+	// swapped public witnesses
+	swapped := []bls12_381witness.Witness{publicWitnesses[1], publicWitnesses[0]}
+	if err := Verify(srs.VerifierSRS(), &vk, proof, swapped); err == nil {
+		t.Fatal("aggregated proof accepted with swapped public witnesses")
+	}
+}
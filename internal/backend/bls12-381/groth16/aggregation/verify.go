@@ -0,0 +1,363 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package aggregation
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+
+	bls12_381groth16 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+
+	bls12_381witness "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+var (
+	errWrongNbRounds              = errors.New("number of rounds is not as expected")
+	errCorrectSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
+	errWrongFoldedValues          = errors.New("folded values don't match the final A, B, C")
+	errWrongFoldedKeys            = errors.New("folded commitment keys are not well formed")
+	errPairingCheckFailed         = errors.New("pairing doesn't match")
+)
+
+// Verify verifies an aggregated proof of Groth16 proofs of the circuit of vk; publicWitnesses[i] is
+// the public witness of the i-th aggregated proof. srs is the part of the SRS used by Aggregate
+// needed by the verifier (see SRS.VerifierSRS).
+func Verify(srs *VerifierSRS, vk *bls12_381groth16.VerifyingKey, proof *Proof, publicWitnesses []bls12_381witness.Witness) error {
+	if err := checkPublicWitnesses(vk, publicWitnesses); err != nil {
+		return err
+	}
+	n := paddedSize(len(publicWitnesses))
+	nbRounds := bits.TrailingZeros(uint(n))
+	if len(proof.ZL) != nbRounds || len(proof.ZR) != nbRounds ||
+		len(proof.ComABL) != nbRounds || len(proof.ComABR) != nbRounds ||
+		len(proof.ComCL) != nbRounds || len(proof.ComCR) != nbRounds ||
+		len(proof.ZCL) != nbRounds || len(proof.ZCR) != nbRounds {
+		return errWrongNbRounds
+	}
+	if !proof.isValid() {
+		return errCorrectSubgroupCheckFailed
+	}
+
+	// derive r, then fold the commitments and the claimed values with the challenges of each round
+	r, err := deriveR(vk, publicWitnesses, n, proof)
+	if err != nil {
+		return err
+	}
+	comAB, comC, zAB := proof.ComAB, proof.ComC, proof.ZAB
+	var zC curve.G1Jac
+	zC.FromAffine(&proof.ZC)
+	challenges := make([]fr.Element, nbRounds)
+	x := r
+	s, one := fr.One(), fr.One()
+	for j := 0; j < nbRounds; j++ {
+		if x, err = deriveChallenge(x, proof.roundBindings(j)...); err != nil {
+			return err
+		}
+		challenges[j] = x
+		var xInv fr.Element
+		xInv.Inverse(&x)
+		var bx, bxInv big.Int
+		x.ToBigIntRegular(&bx)
+		xInv.ToBigIntRegular(&bxInv)
+
+		// T ← T.L^x.R^(x⁻¹)
+		for k := 0; k < 2; k++ {
+			foldGT(&comAB[k], &proof.ComABL[j][k], &proof.ComABR[j][k], &bx, &bxInv)
+			foldGT(&comC[k], &proof.ComCL[j][k], &proof.ComCR[j][k], &bx, &bxInv)
+		}
+		foldGT(&zAB, &proof.ZL[j], &proof.ZR[j], &bx, &bxInv)
+
+		// ZC ← ZC + x.ZCL + x⁻¹.ZCR
+		var tmp curve.G1Jac
+		tmp.ScalarMultiplication(new(curve.G1Jac).FromAffine(&proof.ZCL[j]), &bx)
+		zC.AddAssign(&tmp)
+		tmp.ScalarMultiplication(new(curve.G1Jac).FromAffine(&proof.ZCR[j]), &bxInv)
+		zC.AddAssign(&tmp)
+
+		xInv.Add(&xInv, &one)
+		s.Mul(&s, &xInv)
+	}
+
+	// the folded values must match the final A, B, C and commitment keys
+	var pairs [5]curve.GT
+	if pairs[0], err = curve.Pair([]curve.G1Affine{proof.A}, []curve.G2Affine{proof.B}); err != nil {
+		return err
+	}
+	for k := 0; k < 2; k++ {
+		if pairs[1+k], err = curve.Pair([]curve.G1Affine{proof.A, proof.W[k]}, []curve.G2Affine{proof.V[k], proof.B}); err != nil {
+			return err
+		}
+		if pairs[3+k], err = curve.Pair([]curve.G1Affine{proof.C}, []curve.G2Affine{proof.V[k]}); err != nil {
+			return err
+		}
+	}
+	var sC curve.G1Jac
+	var bs big.Int
+	s.ToBigIntRegular(&bs)
+	sC.ScalarMultiplication(new(curve.G1Jac).FromAffine(&proof.C), &bs)
+	if !zAB.Equal(&pairs[0]) || !comAB[0].Equal(&pairs[1]) || !comAB[1].Equal(&pairs[2]) ||
+		!comC[0].Equal(&pairs[3]) || !comC[1].Equal(&pairs[4]) || !zC.Equal(&sC) {
+		return errWrongFoldedValues
+	}
+
+	// the final commitment keys must be v = [fᵥ(a)]2, [fᵥ(b)]2 and w = [aⁿfw(a)]1, [bⁿfw(b)]1
+	z, err := deriveChallenge(x, proof.keysBindings()...)
+	if err != nil {
+		return err
+	}
+	if err := verifyFoldedKeys(srs, proof, challenges, &r, &z, n); err != nil {
+		return err
+	}
+
+	// Groth16 equation: ZAB = e((Σrⁱ).[α]1, [β]2).e(Σrⁱ.(Σx.[Kvk(t)]1)ᵢ, [γ]2).e(ZC, [δ]2)
+	scalars := make([]fr.Element, len(vk.G1.K))
+	var ri, tmp fr.Element
+	ri.SetOne()
+	for i := 0; i < n; i++ {
+		w := publicWitnesses[len(publicWitnesses)-1]
+		if i < len(publicWitnesses) {
+			w = publicWitnesses[i]
+		}
+		scalars[0].Add(&scalars[0], &ri)
+		for j := 0; j < len(w); j++ {
+			tmp.Mul(&ri, &w[j])
+			scalars[j+1].Add(&scalars[j+1], &tmp)
+		}
+		ri.Mul(&ri, &r)
+	}
+	var kSum, alpha curve.G1Affine
+	if _, err := kSum.MultiExp(vk.G1.K, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	alpha = vk.G1.Alpha
+	scaleG1(&alpha, &scalars[0])
+	right, err := curve.Pair([]curve.G1Affine{alpha, kSum, proof.ZC}, []curve.G2Affine{vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta})
+	if err != nil {
+		return err
+	}
+	if !proof.ZAB.Equal(&right) {
+		return errPairingCheckFailed
+	}
+	return nil
+}
+
+// verifyFoldedKeys checks the KZG openings at z of the folded commitment keys
+func verifyFoldedKeys(srs *VerifierSRS, proof *Proof, challenges []fr.Element, r, z *fr.Element, n int) error {
+	// fᵥ(z) = Πⱼ(1 + xⱼ⁻¹(z/r)ᵐ), fw(z) = Πⱼ(1 + xⱼzᵐ), m = n/2ʲ⁺¹
+	var zr fr.Element
+	zr.Inverse(r).Mul(&zr, z)
+	fv := evalKeyPolynomial(challenges, &zr, true)
+	fw := evalKeyPolynomial(challenges, z, false)
+	var zn fr.Element
+	zn.Exp(*z, big.NewInt(int64(n)))
+	fw.Mul(&fw, &zn)
+
+	var bz, bfv, bfw big.Int
+	z.ToBigIntRegular(&bz)
+	fv.ToBigIntRegular(&bfv)
+	fw.ToBigIntRegular(&bfw)
+
+	g1, g2 := srs.G1.One, srs.G2.One
+	var g1Neg, g1z, fwG1 curve.G1Affine
+	var g2z, fvH curve.G2Affine
+	g1Neg.Neg(&g1)
+	g1z.ScalarMultiplication(&g1, &bz)
+	g2z.ScalarMultiplication(&g2, &bz)
+	fvH.ScalarMultiplication(&g2, &bfv)
+	fwG1.ScalarMultiplication(&g1, &bfw)
+
+	for k, secret := range [2]struct {
+		g1 curve.G1Affine
+		g2 curve.G2Affine
+	}{{srs.G1.A, srs.G2.A}, {srs.G1.B, srs.G2.B}} {
+		// e([s-z]1, πv) == e([1]1, v - [fᵥ(z)]2)
+		var sz curve.G1Affine
+		var v curve.G2Affine
+		sz.Sub(&secret.g1, &g1z)
+		v.Sub(&proof.V[k], &fvH)
+		ok, err := curve.PairingCheck([]curve.G1Affine{sz, g1Neg}, []curve.G2Affine{proof.OpeningV[k], v})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errWrongFoldedKeys
+		}
+
+		// e(w - [zⁿfw(z)]1, [1]2) == e(πw, [s-z]2)
+		var w, opening curve.G1Affine
+		var sz2 curve.G2Affine
+		w.Sub(&proof.W[k], &fwG1)
+		opening.Neg(&proof.OpeningW[k])
+		sz2.Sub(&secret.g2, &g2z)
+		ok, err = curve.PairingCheck([]curve.G1Affine{w, opening}, []curve.G2Affine{g2, sz2})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errWrongFoldedKeys
+		}
+	}
+	return nil
+}
+
+// evalKeyPolynomial evaluates the polynomial returned by keyPolynomial (without scale) at z
+func evalKeyPolynomial(challenges []fr.Element, z *fr.Element, inverse bool) fr.Element {
+	res := fr.One()
+	zm := *z
+	one := fr.One()
+	for j := len(challenges) - 1; j >= 0; j-- {
+		t := challenges[j]
+		if inverse {
+			t.Inverse(&t)
+		}
+		t.Mul(&t, &zm).Add(&t, &one)
+		res.Mul(&res, &t)
+		zm.Square(&zm)
+	}
+	return res
+}
+
+// foldGT sets t to t.l^x.r^(xInv)
+func foldGT(t, l, r *curve.GT, x, xInv *big.Int) {
+	var tmp curve.GT
+	tmp.Exp(l, *x)
+	t.Mul(t, &tmp)
+	tmp.Exp(r, *xInv)
+	t.Mul(t, &tmp)
+}
+
+// isValid ensures the points of the proof are in the correct subgroup
+func (proof *Proof) isValid() bool {
+	g1 := []*curve.G1Affine{&proof.ZC, &proof.A, &proof.C, &proof.W[0], &proof.W[1], &proof.OpeningW[0], &proof.OpeningW[1]}
+	for i := 0; i < len(proof.ZCL); i++ {
+		g1 = append(g1, &proof.ZCL[i], &proof.ZCR[i])
+	}
+	for _, p := range g1 {
+		if !p.IsInSubGroup() {
+			return false
+		}
+	}
+	for _, p := range []*curve.G2Affine{&proof.B, &proof.V[0], &proof.V[1], &proof.OpeningV[0], &proof.OpeningV[1]} {
+		if !p.IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// paddedSize returns the number of proofs actually aggregated, a power of 2
+func paddedSize(nbProofs int) int {
+	n := int(ecc.NextPowerOfTwo(uint64(nbProofs)))
+	if n < 2 {
+		return 2
+	}
+	return n
+}
+
+type marshaler interface {
+	Marshal() []byte
+}
+
+// checkPublicWitnesses checks there is at least one public witness, and that their size
+// matches vk
+func checkPublicWitnesses(vk *bls12_381groth16.VerifyingKey, publicWitnesses []bls12_381witness.Witness) error {
+	if len(publicWitnesses) == 0 {
+		return errNoProof
+	}
+	for i := 0; i < len(publicWitnesses); i++ {
+		if len(publicWitnesses[i]) != (len(vk.G1.K) - 1) {
+			return fmt.Errorf("public witness %d: invalid witness size, got %d, expected %d (public - ONE_WIRE)", i, len(publicWitnesses[i]), len(vk.G1.K)-1)
+		}
+	}
+	return nil
+}
+
+// deriveR returns the challenge r of the aggregation of n proofs. It is bound to the statement
+// (vk and the padded public witnesses) and to the commitments to A, B and C: otherwise, knowing r,
+// one could change the public witnesses while keeping Σrⁱ.wᵢ, and the aggregated proof valid.
+func deriveR(vk *bls12_381groth16.VerifyingKey, publicWitnesses []bls12_381witness.Witness, n int, proof *Proof) (fr.Element, error) {
+	seed, err := newSeed(vk, publicWitnesses, n)
+	if err != nil {
+		return seed, err
+	}
+	return deriveChallenge(seed, &proof.ComAB[0], &proof.ComAB[1], &proof.ComC[0], &proof.ComC[1])
+}
+
+// newSeed returns the initial state of the transcript, bound to the number of aggregated proofs,
+// to vk and to the public witnesses, the last one being repeated up to n
+func newSeed(vk *bls12_381groth16.VerifyingKey, publicWitnesses []bls12_381witness.Witness, n int) (fr.Element, error) {
+	var size fr.Element
+	size.SetUint64(uint64(n))
+	bindings := []marshaler{&vk.G1.Alpha, &vk.G2.Beta, &vk.G2.Gamma, &vk.G2.Delta}
+	for i := 0; i < len(vk.G1.K); i++ {
+		bindings = append(bindings, &vk.G1.K[i])
+	}
+	for i := 0; i < n; i++ {
+		w := publicWitnesses[len(publicWitnesses)-1]
+		if i < len(publicWitnesses) {
+			w = publicWitnesses[i]
+		}
+		for j := 0; j < len(w); j++ {
+			bindings = append(bindings, &w[j])
+		}
+	}
+	return deriveChallenge(size, bindings...)
+}
+
+// deriveChallenge returns sha256(previous || bindings), previous being the previous challenge
+func deriveChallenge(previous fr.Element, bindings ...marshaler) (fr.Element, error) {
+	h := sha256.New()
+	b := previous.Bytes()
+	h.Write(b[:])
+	for _, e := range bindings {
+		h.Write(e.Marshal())
+	}
+	var res fr.Element
+	res.SetBytes(h.Sum(nil))
+	if res.IsZero() {
+		return res, errZeroChallenge
+	}
+	return res, nil
+}
+
+// roundBindings returns the values the challenge of round j depends on
+func (proof *Proof) roundBindings(j int) []marshaler {
+	var res []marshaler
+	if j == 0 {
+		res = append(res, &proof.ZAB, &proof.ZC)
+	}
+	return append(res,
+		&proof.ZL[j], &proof.ZR[j],
+		&proof.ComABL[j][0], &proof.ComABL[j][1], &proof.ComABR[j][0], &proof.ComABR[j][1],
+		&proof.ComCL[j][0], &proof.ComCL[j][1], &proof.ComCR[j][0], &proof.ComCR[j][1],
+		&proof.ZCL[j], &proof.ZCR[j],
+	)
+}
+
+// keysBindings returns the values the opening point of the commitment keys depends on
+func (proof *Proof) keysBindings() []marshaler {
+	return []marshaler{&proof.A, &proof.B, &proof.C, &proof.V[0], &proof.V[1], &proof.W[0], &proof.W[1]}
+}
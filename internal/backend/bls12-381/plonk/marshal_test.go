@@ -23,8 +23,11 @@ import (
 
 	"bytes"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	"io"
 	"reflect"
 	"testing"
+
+	"github.com/consensys/gnark/backend"
 )
 
 func TestProvingKeySerialization(t *testing.T) {
@@ -44,6 +47,9 @@ func TestProvingKeySerialization(t *testing.T) {
 	vk.Qo = g1gen
 	vk.Qk = g1gen
 	vk.NbPublicVariables = 8000
+	vk.NbLookupTables = 2
+	vk.QLookup = g1gen
+	vk.T[3] = g1gen
 
 	// random pk
 	var pk ProvingKey
@@ -67,6 +73,15 @@ func TestProvingKeySerialization(t *testing.T) {
 	pk.Permutation[0] = -12
 	pk.Permutation[len(pk.Permutation)-1] = 8888
 
+	pk.LQLookup = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.CQLookup = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.LQLookup[3].SetUint64(2)
+	for i := 0; i < len(pk.LT); i++ {
+		pk.LT[i] = make([]fr.Element, pk.DomainNum.Cardinality)
+		pk.CT[i] = make([]fr.Element, pk.DomainNum.Cardinality)
+		pk.LT[i][1].SetUint64(uint64(i))
+	}
+
 	var buf bytes.Buffer
 	written, err := pk.WriteTo(&buf)
 	if err != nil {
@@ -105,6 +120,7 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	vk.Qm = g1gen
 	vk.Qo = g1gen
 	vk.Qk = g1gen
+	vk.TranscriptHash = backend.KECCAK256
 
 	var buf bytes.Buffer
 	written, err := vk.WriteTo(&buf)
@@ -127,3 +143,143 @@ func TestVerifyingKeySerialization(t *testing.T) {
 		t.Fatal("bytes written / read don't match")
 	}
 }
+
+func TestLegacyKeySerialization(t *testing.T) {
+	// a key without lookup tables, as encoded by previous versions of gnark
+	var vk VerifyingKey
+	vk.Size = 8
+	vk.SizeInv.SetUint64(8).Inverse(&vk.SizeInv)
+	vk.Shifter[1].SetUint64(12)
+	vk.NbPublicVariables = 2
+
+	_, _, g1gen, _ := curve.Generators()
+	vk.S[0] = g1gen
+	vk.Ql = g1gen
+	vk.Qk = g1gen
+
+	var pk ProvingKey
+	pk.Vk = &vk
+	pk.DomainNum = *fft.NewDomain(8, 3, false)
+	pk.DomainH = *fft.NewDomain(4*8, 1, false)
+	polys := []*[]fr.Element{
+		(*[]fr.Element)(&pk.Ql),
+		(*[]fr.Element)(&pk.Qr),
+		(*[]fr.Element)(&pk.Qm),
+		(*[]fr.Element)(&pk.Qo),
+		(*[]fr.Element)(&pk.CQk),
+		(*[]fr.Element)(&pk.LQk),
+		(*[]fr.Element)(&pk.LS1),
+		(*[]fr.Element)(&pk.LS2),
+		(*[]fr.Element)(&pk.LS3),
+		(*[]fr.Element)(&pk.CS1),
+		(*[]fr.Element)(&pk.CS2),
+		(*[]fr.Element)(&pk.CS3),
+	}
+	for i, p := range polys {
+		*p = make([]fr.Element, pk.DomainNum.Cardinality)
+		(*p)[i%8].SetUint64(uint64(i + 1))
+	}
+	pk.Permutation = make([]int64, 3*pk.DomainNum.Cardinality)
+	pk.Permutation[5] = 7
+
+	var buf bytes.Buffer
+	if err := writeLegacyTo(&buf, &pk); err != nil {
+		t.Fatal(err)
+	}
+	size := int64(buf.Len())
+	legacy := buf.Bytes()
+
+	var reconstructed ProvingKey
+	read, err := reconstructed.ReadFrom(bytes.NewReader(legacy))
+	if err != nil {
+		t.Fatal("couldn't decode legacy proving key", err)
+	}
+	if read != size {
+		t.Fatal("bytes written / read don't match")
+	}
+	if !reflect.DeepEqual(&pk, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
+
+	// legacy keys use the sha256 transcript
+	var reconstructedVk VerifyingKey
+	reconstructedVk.TranscriptHash = backend.KECCAK256
+	if _, err := reconstructedVk.ReadFrom(bytes.NewReader(legacy)); err != nil {
+		t.Fatal("couldn't decode legacy verifying key", err)
+	}
+	if !reflect.DeepEqual(&vk, &reconstructedVk) {
+		t.Fatal("reconstructed object don't match original")
+	}
+
+	// encoding the key again migrates it to the current format
+	buf.Reset()
+	if _, err := reconstructed.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), keyMagic[:]) {
+		t.Fatal("migrated key has no header")
+	}
+
+	// keys of another format version are rejected
+	encoded := buf.Bytes()
+	encoded[5]++
+	if _, err := reconstructed.ReadFrom(bytes.NewReader(encoded)); err == nil {
+		t.Fatal("key of another format version accepted")
+	}
+}
+
+// writeLegacyTo writes pk in the legacy format, without header nor lookup tables
+func writeLegacyTo(w io.Writer, pk *ProvingKey) error {
+	vk := pk.Vk
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{
+		vk.Size,
+		&vk.SizeInv,
+		&vk.Generator,
+		vk.NbPublicVariables,
+		&vk.Shifter[0],
+		&vk.Shifter[1],
+		&vk.S[0],
+		&vk.S[1],
+		&vk.S[2],
+		&vk.Ql,
+		&vk.Qr,
+		&vk.Qm,
+		&vk.Qo,
+		&vk.Qk,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	if _, err := pk.DomainNum.WriteTo(w); err != nil {
+		return err
+	}
+	if _, err := pk.DomainH.WriteTo(w); err != nil {
+		return err
+	}
+
+	toEncode = []interface{}{
+		([]fr.Element)(pk.Ql),
+		([]fr.Element)(pk.Qr),
+		([]fr.Element)(pk.Qm),
+		([]fr.Element)(pk.Qo),
+		([]fr.Element)(pk.CQk),
+		([]fr.Element)(pk.LQk),
+		([]fr.Element)(pk.LS1),
+		([]fr.Element)(pk.LS2),
+		([]fr.Element)(pk.LS3),
+		([]fr.Element)(pk.CS1),
+		([]fr.Element)(pk.CS2),
+		([]fr.Element)(pk.CS3),
+		pk.Permutation,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
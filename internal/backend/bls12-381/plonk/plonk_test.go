@@ -30,6 +30,7 @@ import (
 	"bytes"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -86,6 +87,74 @@ func referenceCircuit() (frontend.CompiledConstraintSystem, frontend.Circuit, *k
 	return ccs, &good, srs
 }
 
+func TestBatchVerify(t *testing.T) {
+	srs, err := kzg.NewSRS(64+3, new(big.Int).SetUint64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two proofs for a first circuit, one for a second circuit
+	var proofs []*bls12_381plonk.Proof
+	var vks []*bls12_381plonk.VerifyingKey
+	var publicWitnesses []bls12_381witness.Witness
+	for _, c := range []struct{ nbConstraints, x int }{{10, 2}, {10, 3}, {20, 2}} {
+		ccs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &refCircuit{nbConstraints: c.nbConstraints}, frontend.WithBuilder(plonk.NewBuilder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, vk, err := bls12_381plonk.Setup(ccs.(*cs.SparseR1CS), srs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var x, y fr.Element
+		x.SetUint64(uint64(c.x))
+		y.Set(&x)
+		for j := 0; j < c.nbConstraints; j++ {
+			y.Mul(&y, &y)
+		}
+		assignment := refCircuit{X: x, Y: y}
+
+		var fullWitness, publicWitness bls12_381witness.Witness
+		if err := fullWitness.FromFullAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		if err := publicWitness.FromPublicAssignment(&assignment); err != nil {
+			t.Fatal(err)
+		}
+		proof, err := bls12_381plonk.Prove(ccs.(*cs.SparseR1CS), pk, fullWitness, backend.ProverOption{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs = append(proofs, proof)
+		vks = append(vks, vk)
+		publicWitnesses = append(publicWitnesses, publicWitness)
+	}
+
+	if err := bls12_381plonk.BatchVerify(proofs, vks, publicWitnesses); err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong public witness: the claimed quotient of the second proof is wrong
+	wrongWitnesses := []bls12_381witness.Witness{publicWitnesses[0], publicWitnesses[0], publicWitnesses[2]}
+	err = bls12_381plonk.BatchVerify(proofs, vks, wrongWitnesses)
+	if err == nil || !strings.HasPrefix(err.Error(), "proof 1:") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// wrong opening proof: only the folded pairing check fails
+	tampered := *proofs[2]
+	tampered.ZShiftedOpening.H = proofs[0].ZShiftedOpening.H
+	err = bls12_381plonk.BatchVerify([]*bls12_381plonk.Proof{proofs[0], proofs[1], &tampered}, vks, publicWitnesses)
+	if err == nil || !strings.HasPrefix(err.Error(), "proof 2:") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := bls12_381plonk.BatchVerify(proofs, vks[:2], publicWitnesses); err == nil {
+		t.Fatal("batch with missing verifying keys accepted")
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	ccs, _, srs := referenceCircuit()
 
@@ -27,12 +27,13 @@ import (
 
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/backend/compiled"
-	"github.com/consensys/gnark/internal/backend/ioutils"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"text/template"
 
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 )
 
 // R1CS decsribes a set of R1CS constraint
@@ -371,22 +372,30 @@ func (cs *R1CS) FrSize() int {
 	return fr.Limbs * 8
 }
 
-// WriteTo encodes R1CS into provided io.Writer using cbor
+// WriteTo encodes R1CS into provided io.Writer, with a header holding the format version, the
+// curve and the backend, followed by compressed sections (see compiled.FormatVersion)
 func (cs *R1CS) WriteTo(w io.Writer) (int64, error) {
-	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
-	enc, err := cbor.CoreDetEncOptions().EncMode()
+	return cs.R1CS.Encode(w, cs.CurveID(), cs.writeCoefficients)
+}
+
+// ReadFrom attempts to decode R1CS from io.Reader, encoded with WriteTo. It returns an error
+// if the R1CS was encoded with another version of the format, or for another curve.
+//
+// A R1CS encoded in the legacy format (cbor, without header) by previous versions of gnark is
+// decoded too; encoding it again with WriteTo migrates it to the current format.
+func (cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
+	r, legacy, err := compiled.IsLegacy(r)
 	if err != nil {
 		return 0, err
 	}
-	encoder := enc.NewEncoder(&_w)
-
-	// encode our object
-	err = encoder.Encode(cs)
-	return _w.N, err
+	if legacy {
+		return cs.readLegacyFrom(r)
+	}
+	return cs.R1CS.Decode(r, cs.CurveID(), cs.readCoefficients)
 }
 
-// ReadFrom attempts to decode R1CS from io.Reader using cbor
-func (cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
+// readLegacyFrom decodes R1CS from io.Reader using cbor, as encoded by previous versions of gnark
+func (cs *R1CS) readLegacyFrom(r io.Reader) (int64, error) {
 	dm, err := cbor.DecOptions{
 		MaxArrayElements: 134217728,
 		MaxMapPairs:      134217728,
@@ -402,3 +411,11 @@ func (cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
 
 	return int64(decoder.NumBytesRead()), nil
 }
+
+func (cs *R1CS) writeCoefficients(w io.Writer) error {
+	return curve.NewEncoder(w).Encode(cs.Coefficients)
+}
+
+func (cs *R1CS) readCoefficients(r io.Reader) error {
+	return curve.NewDecoder(r).Decode(&cs.Coefficients)
+}
@@ -0,0 +1,333 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+
+	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+
+	"bytes"
+	"errors"
+	"fmt"
+	bls24_315witness "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	"io"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// DefaultChunkSize is the default number of points of a MappedProvingKey decoded at once
+const DefaultChunkSize = 1 << 18
+
+// MappedProvingKey is a ProvingKey read from its raw encoding (see ProvingKey.WriteRawTo),
+// typically memory-mapped from a file (see OpenMappedProvingKey).
+//
+// Only the domain and the small elements of the key are decoded when the key is created.
+// The points of the multi-exponentiations ([A(t)]1, [B(t)]1, [Z(t)]1, [Kpk(t)]1 and [B(t)]2)
+// are decoded by chunks of ChunkSize points while proving, so the prover never holds the
+// whole decoded key in memory. As with ProvingKey.UnsafeReadFrom, the points are not
+// checked to be in the correct subgroup.
+type MappedProvingKey struct {
+	// domain
+	Domain fft.Domain
+
+	// [α]1, [β]1, [δ]1
+	G1 struct {
+		Alpha, Beta, Delta curve.G1Affine
+	}
+
+	// [β]2, [δ]2
+	G2 struct {
+		Beta, Delta curve.G2Affine
+	}
+
+	// if InfinityA[i] == true, the point G1.A[i] == infinity
+	InfinityA, InfinityB     []bool
+	NbInfinityA, NbInfinityB uint64
+
+	// ChunkSize is the number of points decoded at once by the multi-exponentiations;
+	// DefaultChunkSize is used if it is not positive
+	ChunkSize int
+
+	// [A(t)]1, [B(t)]1, [Z(t)]1, [Kpk(t)]1 and [B(t)]2, in data
+	g1A, g1B, g1Z, g1K, g2B pointSection
+
+	data  []byte
+	unmap func() error
+}
+
+// mCompressed is the flag of a compressed point, in its most significant byte. The point at
+// infinity is flagged with 0b010 << 5 when it is not compressed.
+const mCompressed byte = 0b1 << 7
+
+// pointSection locates n raw points in the encoding of the key
+type pointSection struct {
+	offset, n int
+}
+
+// OpenMappedProvingKey maps in memory the file at path, holding a ProvingKey encoded with
+// WriteRawTo, and returns the corresponding MappedProvingKey. The key must be closed once
+// it is no longer used.
+func OpenMappedProvingKey(path string) (*MappedProvingKey, error) {
+	data, unmap, err := ioutils.MapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := NewMappedProvingKey(data)
+	if err != nil {
+		_ = unmap()
+		return nil, err
+	}
+	pk.unmap = unmap
+	return pk, nil
+}
+
+// NewMappedProvingKey returns a MappedProvingKey reading its points from data, a ProvingKey
+// encoded with WriteRawTo. data must not be modified while the key is used.
+func NewMappedProvingKey(data []byte) (*MappedProvingKey, error) {
+	pk := &MappedProvingKey{data: data}
+	r := bytes.NewReader(data)
+
+	if _, err := pk.Domain.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	dec := curve.NewDecoder(r, curve.NoSubgroupChecks())
+
+	// skipPoints records the position of a slice of points of the given size and skips it
+	skipPoints := func(s *pointSection, size int) error {
+		var n uint32
+		if err := dec.Decode(&n); err != nil {
+			return err
+		}
+		s.offset = len(data) - r.Len()
+		s.n = int(n)
+		if s.n*size > r.Len() {
+			return errors.New("invalid proving key: unexpected end of data")
+		}
+		if s.n != 0 && data[s.offset]&mCompressed != 0 {
+			return errors.New("invalid proving key: points must not be compressed, use ProvingKey.WriteRawTo")
+		}
+		_, err := r.Seek(int64(s.n*size), io.SeekCurrent)
+		return err
+	}
+
+	for _, v := range []interface{}{&pk.G1.Alpha, &pk.G1.Beta, &pk.G1.Delta} {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range []*pointSection{&pk.g1A, &pk.g1B, &pk.g1Z, &pk.g1K} {
+		if err := skipPoints(s, curve.SizeOfG1AffineUncompressed); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range []interface{}{&pk.G2.Beta, &pk.G2.Delta} {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := skipPoints(&pk.g2B, curve.SizeOfG2AffineUncompressed); err != nil {
+		return nil, err
+	}
+
+	var nbWires uint64
+	for _, v := range []interface{}{&nbWires, &pk.NbInfinityA, &pk.NbInfinityB} {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	if nbWires > uint64(r.Len()) {
+		return nil, errors.New("invalid proving key: unexpected end of data")
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+	if err := dec.Decode(&pk.InfinityA); err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(&pk.InfinityB); err != nil {
+		return nil, err
+	}
+
+	if pk.g1A.n != len(pk.InfinityA)-int(pk.NbInfinityA) || pk.g1B.n != len(pk.InfinityB)-int(pk.NbInfinityB) || pk.g2B.n != pk.g1B.n {
+		return nil, errors.New("invalid proving key: inconsistent number of points")
+	}
+
+	return pk, nil
+}
+
+// Close releases the memory mapping of the key, if it was created with OpenMappedProvingKey.
+// The key must not be used after it is closed.
+func (pk *MappedProvingKey) Close() error {
+	pk.data = nil
+	if pk.unmap == nil {
+		return nil
+	}
+	unmap := pk.unmap
+	pk.unmap = nil
+	return unmap()
+}
+
+// CurveID returns the curveID
+func (pk *MappedProvingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// NbG1 returns the number of G1 elements in the MappedProvingKey
+func (pk *MappedProvingKey) NbG1() int {
+	return 3 + pk.g1A.n + pk.g1B.n + pk.g1Z.n + pk.g1K.n
+}
+
+// NbG2 returns the number of G2 elements in the MappedProvingKey
+func (pk *MappedProvingKey) NbG2() int {
+	return 2 + pk.g2B.n
+}
+
+// ProveMapped behaves like Prove, with the points of the key decoded while proving
+func ProveMapped(r1cs *cs.R1CS, pk *MappedProvingKey, witness bls24_315witness.Witness, opt backend.ProverOption) (*Proof, error) {
+	if pk.data == nil {
+		return nil, errors.New("proving key is closed")
+	}
+	return prove(r1cs, pk.proverKey(), witness, opt)
+}
+
+func (pk *MappedProvingKey) proverKey() *proverKey {
+	return &proverKey{
+		domain:      &pk.Domain,
+		g1Alpha:     &pk.G1.Alpha,
+		g1Beta:      &pk.G1.Beta,
+		g1Delta:     &pk.G1.Delta,
+		g2Beta:      &pk.G2.Beta,
+		g2Delta:     &pk.G2.Delta,
+		infinityA:   pk.InfinityA,
+		infinityB:   pk.InfinityB,
+		nbInfinityA: pk.NbInfinityA,
+		nbInfinityB: pk.NbInfinityB,
+		g1A:         pk.g1MultiExp(pk.g1A),
+		g1B:         pk.g1MultiExp(pk.g1B),
+		g1Z:         pk.g1MultiExp(pk.g1Z),
+		g1K:         pk.g1MultiExp(pk.g1K),
+		g2B:         pk.g2MultiExp(pk.g2B),
+	}
+}
+
+func (pk *MappedProvingKey) chunkSize() int {
+	if pk.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return pk.ChunkSize
+}
+
+// g1MultiExp returns the multi-exponentiation with the points of s, computed by chunks
+// of decoded points
+func (pk *MappedProvingKey) g1MultiExp(s pointSection) g1MultiExp {
+	return func(res *curve.G1Jac, scalars []fr.Element, config ecc.MultiExpConfig) error {
+		if len(scalars) != s.n {
+			return fmt.Errorf("%d scalars for %d points", len(scalars), s.n)
+		}
+		chunkSize := pk.chunkSize()
+		if chunkSize > s.n {
+			chunkSize = s.n
+		}
+		points := make([]curve.G1Affine, chunkSize)
+
+		// res starts at infinity
+		res.Set(&curve.G1Jac{})
+		var tmp curve.G1Jac
+		for start := 0; start < s.n; start += chunkSize {
+			end := start + chunkSize
+			if end > s.n {
+				end = s.n
+			}
+			chunk := points[:end-start]
+			err := decodePoints(len(chunk), curve.SizeOfG1AffineUncompressed, pk.data[s.offset+start*curve.SizeOfG1AffineUncompressed:], func(dec *curve.Decoder, i int) error {
+				return dec.Decode(&chunk[i])
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := tmp.MultiExp(chunk, scalars[start:end], config); err != nil {
+				return err
+			}
+			res.AddAssign(&tmp)
+		}
+		return nil
+	}
+}
+
+// g2MultiExp returns the multi-exponentiation with the points of s, computed by chunks
+// of decoded points
+func (pk *MappedProvingKey) g2MultiExp(s pointSection) g2MultiExp {
+	return func(res *curve.G2Jac, scalars []fr.Element, config ecc.MultiExpConfig) error {
+		if len(scalars) != s.n {
+			return fmt.Errorf("%d scalars for %d points", len(scalars), s.n)
+		}
+		chunkSize := pk.chunkSize()
+		if chunkSize > s.n {
+			chunkSize = s.n
+		}
+		points := make([]curve.G2Affine, chunkSize)
+
+		// res starts at infinity
+		res.Set(&curve.G2Jac{})
+		var tmp curve.G2Jac
+		for start := 0; start < s.n; start += chunkSize {
+			end := start + chunkSize
+			if end > s.n {
+				end = s.n
+			}
+			chunk := points[:end-start]
+			err := decodePoints(len(chunk), curve.SizeOfG2AffineUncompressed, pk.data[s.offset+start*curve.SizeOfG2AffineUncompressed:], func(dec *curve.Decoder, i int) error {
+				return dec.Decode(&chunk[i])
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := tmp.MultiExp(chunk, scalars[start:end], config); err != nil {
+				return err
+			}
+			res.AddAssign(&tmp)
+		}
+		return nil
+	}
+}
+
+// decodePoints decodes in parallel n raw points of the given size from data, decode(dec, i)
+// reading the i-th point from dec
+func decodePoints(n, size int, data []byte, decode func(dec *curve.Decoder, i int) error) error {
+	var lock sync.Mutex
+	var err error
+	utils.Parallelize(n, func(start, end int) {
+		dec := curve.NewDecoder(bytes.NewReader(data[start*size:end*size]), curve.NoSubgroupChecks())
+		for i := start; i < end; i++ {
+			if e := decode(dec, i); e != nil {
+				lock.Lock()
+				err = e
+				lock.Unlock()
+				return
+			}
+		}
+	})
+	return err
+}
@@ -19,6 +19,9 @@ package groth16
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 )
 
 // WriteTo writes binary encoding of the Proof elements to writer
@@ -55,6 +58,45 @@ func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
 	return enc.BytesWritten(), nil
 }
 
+// SolidityCalldata returns the proof as the (a, b, c) arguments of the verifyProof method
+// of the solidity verifier (see VerifyingKey.ExportSolidity).
+//
+// a = Ar, b = Bs and c = Krs; the coordinates of Bs are given imaginary part first
+// (b[0] = [X.A1, X.A0], b[1] = [Y.A1, Y.A0]), as expected by the EVM pairing precompile.
+func (proof *Proof) SolidityCalldata() (a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int) {
+	a[0] = proof.Ar.X.ToBigIntRegular(new(big.Int))
+	a[1] = proof.Ar.Y.ToBigIntRegular(new(big.Int))
+
+	b[0][0] = proof.Bs.X.A1.ToBigIntRegular(new(big.Int))
+	b[0][1] = proof.Bs.X.A0.ToBigIntRegular(new(big.Int))
+	b[1][0] = proof.Bs.Y.A1.ToBigIntRegular(new(big.Int))
+	b[1][1] = proof.Bs.Y.A0.ToBigIntRegular(new(big.Int))
+
+	c[0] = proof.Krs.X.ToBigIntRegular(new(big.Int))
+	c[1] = proof.Krs.Y.ToBigIntRegular(new(big.Int))
+
+	return
+}
+
+// MarshalSolidity returns the ABI encoding of the (a, b, c) arguments returned by
+// SolidityCalldata: 8 words of 32 bytes, big endian.
+//
+// The verifyProof calldata is the method selector followed by this encoding and
+// the one of the public witness (see witness.Witness.MarshalSolidity).
+func (proof *Proof) MarshalSolidity() []byte {
+	res := make([]byte, 0, 8*fp.Bytes)
+	for _, e := range []*fp.Element{
+		&proof.Ar.X, &proof.Ar.Y,
+		&proof.Bs.X.A1, &proof.Bs.X.A0,
+		&proof.Bs.Y.A1, &proof.Bs.Y.A0,
+		&proof.Krs.X, &proof.Krs.Y,
+	} {
+		b := e.Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
 // ReadFrom attempts to decode a Proof from reader
 // Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
@@ -0,0 +1,156 @@
+package groth16_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+	bn254groth16 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
+)
+
+type solidityCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *solidityCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y), circuit.Z)
+	return nil
+}
+
+func TestSolidityCalldata(t *testing.T) {
+	ccs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &solidityCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pk bn254groth16.ProvingKey
+	var vk bn254groth16.VerifyingKey
+	if err := bn254groth16.Setup(ccs.(*cs.R1CS), &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := &solidityCircuit{X: 3, Y: 27, Z: 30}
+	var fullWitness, publicWitness bn254witness.Witness
+	if err := fullWitness.FromFullAssignment(assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := publicWitness.FromPublicAssignment(assignment); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := bn254groth16.Prove(ccs.(*cs.R1CS), &pk, fullWitness, backend.ProverOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// contract
+	var buf bytes.Buffer
+	if err := vk.ExportSolidity(&buf); err != nil {
+		t.Fatal(err)
+	}
+	signature := fmt.Sprintf("uint256[%d] memory input", len(publicWitness))
+	if !strings.Contains(buf.String(), signature) {
+		t.Fatalf("contract does not declare %q", signature)
+	}
+
+	// calldata
+	calldata := append(proof.MarshalSolidity(), publicWitness.MarshalSolidity()...)
+	if len(calldata) != (8+len(publicWitness))*32 {
+		t.Fatalf("unexpected calldata length %d", len(calldata))
+	}
+	a, b, c := proof.SolidityCalldata()
+	expected := append([]*big.Int{a[0], a[1], b[0][0], b[0][1], b[1][0], b[1][1], c[0], c[1]}, publicWitness.SolidityInput()...)
+	for i := 0; i < len(expected); i++ {
+		word := new(big.Int).SetBytes(calldata[i*32 : (i+1)*32])
+		if word.Cmp(expected[i]) != 0 {
+			t.Fatalf("word %d: ABI encoding and big.Int arrays differ", i)
+		}
+	}
+	if !solidityVerify(&vk, calldata) {
+		t.Fatal("valid proof rejected")
+	}
+
+	// wrong public input
+	wrongWitness := make(bn254witness.Witness, len(publicWitness))
+	copy(wrongWitness, publicWitness)
+	wrongWitness[0].SetUint64(28)
+	if solidityVerify(&vk, append(proof.MarshalSolidity(), wrongWitness.MarshalSolidity()...)) {
+		t.Fatal("proof accepted with a wrong public input")
+	}
+
+	// swapped G2 coordinates, as produced by a naive A0, A1 encoding
+	swapped := make([]byte, len(calldata))
+	copy(swapped, calldata)
+	copy(swapped[0x40:0x60], calldata[0x60:0x80])
+	copy(swapped[0x60:0x80], calldata[0x40:0x60])
+	if solidityVerify(&vk, swapped) {
+		t.Fatal("proof accepted with swapped G2 coordinates")
+	}
+}
+
+// solidityVerify mirrors the verifyProof method of the solidity verifier (see solidityTemplate)
+// on the ABI encoded arguments (a, b, c, input). The contract itself is executed in integration/solidity.
+func solidityVerify(vk *bn254groth16.VerifyingKey, calldata []byte) bool {
+	nbWords := len(calldata) / 32
+	if len(calldata)%32 != 0 || nbWords != 8+len(vk.G1.K)-1 {
+		return false
+	}
+	words := make([]*big.Int, nbWords)
+	for i := 0; i < nbWords; i++ {
+		words[i] = new(big.Int).SetBytes(calldata[i*32 : (i+1)*32])
+	}
+
+	// range checks
+	for i := 0; i < 8; i++ {
+		if words[i].Cmp(fp.Modulus()) >= 0 {
+			return false
+		}
+	}
+	input := words[8:]
+	for i := 0; i < len(input); i++ {
+		if input[i].Cmp(fr.Modulus()) >= 0 {
+			return false
+		}
+	}
+
+	// the precompiles expect G2 coordinates imaginary part first
+	var a, c curve.G1Affine
+	var b curve.G2Affine
+	a.X.SetBigInt(words[0])
+	a.Y.SetBigInt(words[1])
+	b.X.A1.SetBigInt(words[2])
+	b.X.A0.SetBigInt(words[3])
+	b.Y.A1.SetBigInt(words[4])
+	b.Y.A0.SetBigInt(words[5])
+	c.X.SetBigInt(words[6])
+	c.Y.SetBigInt(words[7])
+	if !a.IsOnCurve() || !b.IsOnCurve() || !c.IsOnCurve() {
+		return false
+	}
+
+	// vk_x
+	vkX := vk.G1.K[0]
+	for i := 0; i < len(input); i++ {
+		var p curve.G1Affine
+		p.ScalarMultiplication(&vk.G1.K[i+1], input[i])
+		vkX.Add(&vkX, &p)
+	}
+
+	a.Neg(&a)
+	ok, err := curve.PairingCheck(
+		[]curve.G1Affine{a, vk.G1.Alpha, vkX, c},
+		[]curve.G2Affine{b, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta},
+	)
+	return err == nil && ok
+}
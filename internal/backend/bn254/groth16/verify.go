@@ -19,12 +19,15 @@ package groth16
 import (
 	"github.com/consensys/gnark-crypto/ecc"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 
 	"errors"
 	"fmt"
 	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
 	"io"
+	"math/big"
 
 	"text/template"
 )
@@ -32,6 +35,7 @@ import (
 var (
 	errPairingCheckFailed         = errors.New("pairing doesn't match")
 	errCorrectSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
+	errBatchSizeMismatch          = errors.New("number of proofs and public witnesses don't match")
 )
 
 // Verify verifies a proof with given VerifyingKey and publicWitness
@@ -83,6 +87,98 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 	return nil
 }
 
+// BatchVerify verifies several proofs against the same VerifyingKey; publicWitnesses[i] is the
+// public witness of proofs[i].
+//
+// The verification equations are combined with random coefficients rᵢ into a single pairing check
+// 	e(-(Σrᵢ)α, β) . Πe(rᵢ.Arᵢ, Bsᵢ) . e(Σrᵢ.Krsᵢ, -δ) . e(Σrᵢ.(Σx.[Kvk(t)]1)ᵢ, -γ) == 1
+// If it fails, the proofs are verified one by one and the error identifies the first invalid proof.
+func BatchVerify(proofs []*Proof, vk *VerifyingKey, publicWitnesses []bn254witness.Witness) error {
+	if len(proofs) != len(publicWitnesses) {
+		return errBatchSizeMismatch
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(proofs); i++ {
+		if len(publicWitnesses[i]) != (len(vk.G1.K) - 1) {
+			return fmt.Errorf("proof %d: invalid witness size, got %d, expected %d (public - ONE_WIRE)", i, len(publicWitnesses[i]), len(vk.G1.K)-1)
+		}
+		if !proofs[i].isValid() {
+			return fmt.Errorf("proof %d: %w", i, errCorrectSubgroupCheckFailed)
+		}
+	}
+
+	// random coefficients
+	r := make([]fr.Element, len(proofs))
+	for i := 0; i < len(r); i++ {
+		if _, err := r[i].SetRandom(); err != nil {
+			return err
+		}
+	}
+
+	// Σrᵢ.(Σx.[Kvk(t)]1)ᵢ = (Σrᵢ).[Kvk(0)]1 + Σⱼ(Σrᵢ.xᵢⱼ).[Kvk(j)]1
+	scalars := make([]fr.Element, len(vk.G1.K))
+	var tmp fr.Element
+	for i := 0; i < len(proofs); i++ {
+		scalars[0].Add(&scalars[0], &r[i])
+		for j := 0; j < len(publicWitnesses[i]); j++ {
+			tmp.Mul(&r[i], &publicWitnesses[i][j])
+			scalars[j+1].Add(&scalars[j+1], &tmp)
+		}
+	}
+	var kSum curve.G1Affine
+	if _, err := kSum.MultiExp(vk.G1.K, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+
+	// Σrᵢ.Krsᵢ
+	krs := make([]curve.G1Affine, len(proofs))
+	for i := 0; i < len(proofs); i++ {
+		krs[i] = proofs[i].Krs
+	}
+	var krsSum curve.G1Affine
+	if _, err := krsSum.MultiExp(krs, r, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+
+	// -(Σrᵢ)α
+	var bScalar big.Int
+	var alpha curve.G1Affine
+	scalars[0].ToBigIntRegular(&bScalar)
+	alpha.ScalarMultiplication(&vk.G1.Alpha, &bScalar)
+	alpha.Neg(&alpha)
+
+	P := make([]curve.G1Affine, 0, len(proofs)+3)
+	Q := make([]curve.G2Affine, 0, len(proofs)+3)
+	for i := 0; i < len(proofs); i++ {
+		var ar curve.G1Affine
+		r[i].ToBigIntRegular(&bScalar)
+		ar.ScalarMultiplication(&proofs[i].Ar, &bScalar)
+		P = append(P, ar)
+		Q = append(Q, proofs[i].Bs)
+	}
+	P = append(P, alpha, krsSum, kSum)
+	Q = append(Q, vk.G2.Beta, vk.G2.deltaNeg, vk.G2.gammaNeg)
+
+	ok, err := curve.PairingCheck(P, Q)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	// find the culprit
+	for i := 0; i < len(proofs); i++ {
+		if err := Verify(proofs[i], vk, publicWitnesses[i]); err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+	}
+	return errPairingCheckFailed
+}
+
 // ExportSolidity writes a solidity Verifier contract on provided writer
 // while this uses an audited template https://github.com/appliedzkp/semaphore/blob/master/contracts/sol/verifier.sol
 // audit report https://github.com/appliedzkp/semaphore/blob/master/audit/Audit%20Report%20Summary%20for%20Semaphore%20and%20MicroMix.pdf
@@ -17,7 +17,6 @@
 package plonk
 
 import (
-	"crypto/sha256"
 	"math/big"
 	"math/bits"
 	"runtime"
@@ -37,7 +36,6 @@ import (
 
 	"github.com/consensys/gnark/internal/backend/bn254/cs"
 
-	"github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/utils"
 )
@@ -52,21 +50,35 @@ type Proof struct {
 	// Commitments to h1, h2, h3 such that h = h1 + Xh2 + X**2h3 is the quotient polynomial
 	H [3]kzg.Digest
 
-	// Batch opening proof of h1 + zeta*h2 + zeta**2h3, linearizedPolynomial, l, r, o, s1, s2
+	// Batch opening proof of h1 + zeta*h2 + zeta**2h3, linearizedPolynomial, l, r, o, s1, s2,
+	// followed by qLookup, t, h1Lookup, h2Lookup, zLookup if the circuit has lookup constraints
 	BatchedProof kzg.BatchOpeningProof
 
 	// Opening proof of Z at zeta*mu
 	ZShiftedOpening kzg.OpeningProof
+
+	// Commitments to h1Lookup, h2Lookup, the lookup values and the table sorted by the table
+	// (only if the circuit has lookup constraints)
+	LookupH [2]kzg.Digest
+
+	// Commitment to zLookup, the lookup accumulator polynomial
+	LookupZ kzg.Digest
+
+	// Batch opening proof of zLookup, h1Lookup, h2Lookup, t at zeta*mu
+	LookupShiftedOpening kzg.BatchOpeningProof
 }
 
 // Prove from the public data
 func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bn254witness.Witness, opt backend.ProverOption) (*Proof, error) {
 
 	// pick a hash function that will be used to derive the challenges
-	hFunc := sha256.New()
+	hFunc := pk.Vk.TranscriptHash.New()
+	if hFunc == nil {
+		return nil, errUnknownTranscriptHash
+	}
 
 	// create a transcript manager to apply Fiat Shamir
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "alpha", "zeta")
+	fs := newTranscript(hFunc, pk.Vk)
 
 	// result
 	proof := &Proof{}
@@ -109,6 +121,14 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bn254witness.Witness,
 		return nil, err
 	}
 
+	// lookup argument: commit to h1Lookup, h2Lookup and to the lookup accumulator
+	var lookup *lookupArgument
+	if pk.Vk.NbLookupTables != 0 {
+		if lookup, err = proveLookup(&fs, pk, proof, ll, lr, lo); err != nil {
+			return nil, err
+		}
+	}
+
 	// compute Z, the permutation accumulator polynomial, in canonical basis
 	// ll, lr, lo are NOT blinded
 	var bz polynomial.Polynomial
@@ -134,8 +154,12 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bn254witness.Witness,
 			return
 		}
 
-		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z)
-		alpha, err = deriveRandomness(&fs, "alpha", &proof.Z)
+		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z) (and Comm(zLookup))
+		toBind := []*curve.G1Affine{&proof.Z}
+		if lookup != nil {
+			toBind = append(toBind, &proof.LookupZ)
+		}
+		alpha, err = deriveRandomness(&fs, "alpha", toBind...)
 		chZ <- err
 		close(chZ)
 	}()
@@ -199,8 +223,15 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bn254witness.Witness,
 		return nil, err
 	}
 	<-chConstraintInd
+
+	// evaluation of the lookup constraints on the odd cosets of (Z/8mZ)/(Z/mZ)
+	var constraintsLookup polynomial.Polynomial
+	if lookup != nil {
+		constraintsLookup = lookup.evalConstraints(pk, evalBL, evalBR, evalBO, alpha)
+	}
+
 	// compute h in canonical form
-	h1, h2, h3 := computeH(pk, constraintsInd, constraintsOrdering, evalBZ, alpha)
+	h1, h2, h3 := computeH(pk, constraintsInd, constraintsOrdering, constraintsLookup, evalBZ, alpha)
 
 	// compute kzg commitments of h1, h2 and h3
 	if err := commitToH(h1, h2, h3, proof, pk.Vk.KZGSRS); err != nil {
@@ -303,25 +334,44 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bn254witness.Witness,
 	}
 
 	// Batch open the first list of polynomials
+	polynomials := []polynomial.Polynomial{
+		foldedH,
+		linearizedPolynomial,
+		bcl,
+		bcr,
+		bco,
+		pk.CS1,
+		pk.CS2,
+	}
+	digests := []kzg.Digest{
+		foldedHDigest,
+		linearizedPolynomialDigest,
+		proof.LRO[0],
+		proof.LRO[1],
+		proof.LRO[2],
+		pk.Vk.S[0],
+		pk.Vk.S[1],
+	}
+	if lookup != nil {
+		polynomials = append(polynomials, pk.CQLookup, lookup.ct, lookup.bh1, lookup.bh2, lookup.bz)
+		digests = append(digests, pk.Vk.QLookup, lookup.ctDigest, proof.LookupH[0], proof.LookupH[1], proof.LookupZ)
+
+		// open zLookup, h1Lookup, h2Lookup, t at zeta*z
+		proof.LookupShiftedOpening, err = kzg.BatchOpenSinglePoint(
+			[]polynomial.Polynomial{lookup.bz, lookup.bh1, lookup.bh2, lookup.ct},
+			[]kzg.Digest{proof.LookupZ, proof.LookupH[0], proof.LookupH[1], lookup.ctDigest},
+			&zetaShifted,
+			hFunc,
+			&pk.DomainH,
+			pk.Vk.KZGSRS,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
 	proof.BatchedProof, err = kzg.BatchOpenSinglePoint(
-		[]polynomial.Polynomial{
-			foldedH,
-			linearizedPolynomial,
-			bcl,
-			bcr,
-			bco,
-			pk.CS1,
-			pk.CS2,
-		},
-		[]kzg.Digest{
-			foldedHDigest,
-			linearizedPolynomialDigest,
-			proof.LRO[0],
-			proof.LRO[1],
-			proof.LRO[2],
-			pk.Vk.S[0],
-			pk.Vk.S[1],
-		},
+		polynomials,
+		digests,
 		&zeta,
 		hFunc,
 		&pk.DomainH,
@@ -724,12 +774,13 @@ func evaluateHDomain(poly []fr.Element, domainH *fft.Domain) []fr.Element {
 
 // computeH computes h in canonical form, split as h1+X^mh2+X^2mh3 such that
 //
-// qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1*(z-1)= h.Z
-// \------------------/         \------------------------/             \-----/
-//    constraintsInd			    constraintOrdering					startsAtOne
+// qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1*(z-1) + alpha**3*lookup = h.Z
+// \------------------/         \------------------------/             \-----/           \----/
+//    constraintsInd			    constraintOrdering					startsAtOne     constraintsLookup
 //
-// constraintInd, constraintOrdering are evaluated on the odd cosets of (Z/8mZ)/(Z/mZ)
-func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, evalBZ polynomial.Polynomial, alpha fr.Element) (polynomial.Polynomial, polynomial.Polynomial, polynomial.Polynomial) {
+// constraintInd, constraintOrdering, constraintsLookup are evaluated on the odd cosets of (Z/8mZ)/(Z/mZ).
+// constraintsLookup is nil if the circuit has no lookup constraints.
+func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, constraintsLookup, evalBZ polynomial.Polynomial, alpha fr.Element) (polynomial.Polynomial, polynomial.Polynomial, polynomial.Polynomial) {
 
 	h := make(polynomial.Polynomial, pk.DomainH.Cardinality)
 
@@ -786,7 +837,12 @@ func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, evalBZ polynom
 		var t fr.Element
 		for i := uint64(start); i < uint64(end); i++ {
 			t.Sub(&evalBZ[i], &one) // evaluates L1*(z-1) on the odd cosets of (Z/8mZ)/(Z/mZ)
-			h[i].Mul(&startsAtOne[i], &alpha).Mul(&h[i], &t).
+			h[i].Mul(&startsAtOne[i], &t)
+			if constraintsLookup != nil {
+				t.Mul(&constraintsLookup[i], &alpha)
+				h[i].Add(&h[i], &t)
+			}
+			h[i].Mul(&h[i], &alpha).
 				Add(&h[i], &constraintOrdering[i]).
 				Mul(&h[i], &alpha).
 				Add(&h[i], &constraintsInd[i])
@@ -0,0 +1,342 @@
+package plonk
+
+// solidityTemplate is a PlonK verifier contract for a given VerifyingKey. It reproduces Verify:
+// the challenges are derived with the same keccak256 transcript (the verifying key must be set
+// up with backend.KECCAK256 as TranscriptHash), and the two KZG opening proofs are checked with
+// a single pairing, combined with a random number derived with keccak256.
+//
+// The proof is the output of Proof.MarshalSolidity.
+// this is an experimental feature and gnark solidity generator has not been thoroughly tested
+const solidityTemplate = `
+// SPDX-License-Identifier: Apache-2.0
+
+// Copyright 2021 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+pragma solidity ^0.8.0;
+
+contract PlonkVerifier {
+
+    uint256 constant R_MOD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+    uint256 constant P_MOD = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    // verifying key
+    uint256 constant N = {{.Size}};
+    uint256 constant N_INV = {{.SizeInv.String}};
+    uint256 constant OMEGA = {{.Generator.String}};
+    uint256 constant NB_PUBLIC_INPUTS = {{.NbPublicVariables}};
+    uint256 constant COSET_SHIFT_1 = {{(index .Shifter 0).String}};
+    uint256 constant COSET_SHIFT_2 = {{(index .Shifter 1).String}};
+
+    uint256 constant QL_X = {{.Ql.X.String}};
+    uint256 constant QL_Y = {{.Ql.Y.String}};
+    uint256 constant QR_X = {{.Qr.X.String}};
+    uint256 constant QR_Y = {{.Qr.Y.String}};
+    uint256 constant QM_X = {{.Qm.X.String}};
+    uint256 constant QM_Y = {{.Qm.Y.String}};
+    uint256 constant QO_X = {{.Qo.X.String}};
+    uint256 constant QO_Y = {{.Qo.Y.String}};
+    uint256 constant QK_X = {{.Qk.X.String}};
+    uint256 constant QK_Y = {{.Qk.Y.String}};
+    uint256 constant S1_X = {{(index .S 0).X.String}};
+    uint256 constant S1_Y = {{(index .S 0).Y.String}};
+    uint256 constant S2_X = {{(index .S 1).X.String}};
+    uint256 constant S2_Y = {{(index .S 1).Y.String}};
+    uint256 constant S3_X = {{(index .S 2).X.String}};
+    uint256 constant S3_Y = {{(index .S 2).Y.String}};
+
+    // kzg srs: [1] in G1, [1] and [x] in G2 (imaginary part first)
+    uint256 constant G1_X = {{(index .KZGSRS.G1 0).X.String}};
+    uint256 constant G1_Y = {{(index .KZGSRS.G1 0).Y.String}};
+    uint256 constant G2_X_0 = {{(index .KZGSRS.G2 0).X.A1.String}};
+    uint256 constant G2_X_1 = {{(index .KZGSRS.G2 0).X.A0.String}};
+    uint256 constant G2_Y_0 = {{(index .KZGSRS.G2 0).Y.A1.String}};
+    uint256 constant G2_Y_1 = {{(index .KZGSRS.G2 0).Y.A0.String}};
+    uint256 constant G2_SRS_X_0 = {{(index .KZGSRS.G2 1).X.A1.String}};
+    uint256 constant G2_SRS_X_1 = {{(index .KZGSRS.G2 1).X.A0.String}};
+    uint256 constant G2_SRS_Y_0 = {{(index .KZGSRS.G2 1).Y.A1.String}};
+    uint256 constant G2_SRS_Y_1 = {{(index .KZGSRS.G2 1).Y.A0.String}};
+
+    // offsets in the proof, in bytes (see Proof.MarshalSolidity)
+    uint256 constant PROOF_L = 0x00;
+    uint256 constant PROOF_R = 0x40;
+    uint256 constant PROOF_O = 0x80;
+    uint256 constant PROOF_Z = 0xc0;
+    uint256 constant PROOF_H_0 = 0x100;
+    uint256 constant PROOF_H_1 = 0x140;
+    uint256 constant PROOF_H_2 = 0x180;
+    uint256 constant PROOF_BATCH_H = 0x1c0;
+    uint256 constant PROOF_QUOTIENT_AT_ZETA = 0x200;
+    uint256 constant PROOF_LINEARIZED_AT_ZETA = 0x220;
+    uint256 constant PROOF_L_AT_ZETA = 0x240;
+    uint256 constant PROOF_R_AT_ZETA = 0x260;
+    uint256 constant PROOF_O_AT_ZETA = 0x280;
+    uint256 constant PROOF_S1_AT_ZETA = 0x2a0;
+    uint256 constant PROOF_S2_AT_ZETA = 0x2c0;
+    uint256 constant PROOF_Z_SHIFTED_H = 0x2e0;
+    uint256 constant PROOF_Z_SHIFTED_AT = 0x320;
+    uint256 constant PROOF_SIZE = 0x340;
+
+    struct G1Point {
+        uint256 X;
+        uint256 Y;
+    }
+
+    /*
+     * @return true if proof is a valid proof for the public inputs input
+     */
+    function verifyProof(bytes calldata proof, uint256[] calldata input) public view returns (bool) {
+
+        require(proof.length == PROOF_SIZE, "verifier-bad-proof-size");
+        require(input.length == NB_PUBLIC_INPUTS, "verifier-bad-input");
+        for (uint256 i = 0; i < input.length; i++) {
+            require(input[i] < R_MOD, "verifier-gte-snark-scalar-field");
+        }
+        for (uint256 offset = PROOF_QUOTIENT_AT_ZETA; offset < PROOF_Z_SHIFTED_H; offset += 0x20) {
+            require(load(proof, offset) < R_MOD, "verifier-gte-snark-scalar-field");
+        }
+        require(load(proof, PROOF_Z_SHIFTED_AT) < R_MOD, "verifier-gte-snark-scalar-field");
+
+        (uint256 gamma, uint256 alpha, uint256 zeta) = deriveChallenges(proof);
+
+        // zeta**n-1
+        uint256 zh = addmod(expmod(zeta, N), R_MOD - 1, R_MOD);
+
+        (uint256 pi, uint256 lagrangeOne) = publicInputsAt(input, zeta, zh);
+        uint256 alphaSquareLagrange = mulmod(mulmod(lagrangeOne, alpha, R_MOD), alpha, R_MOD);
+
+        if (!checkQuotient(proof, gamma, alpha, zh, pi, alphaSquareLagrange)) {
+            return false;
+        }
+
+        G1Point memory foldedH = foldedHDigest(proof, zeta);
+        G1Point memory linearizedDigest = linearizedPolynomialDigest(proof, gamma, alpha, zeta, alphaSquareLagrange);
+
+        return batchVerify(proof, foldedH, linearizedDigest, zeta);
+    }
+
+    // deriveChallenges returns gamma, alpha, zeta: the challenge c is
+    // keccak256(name || previous challenge || bound values) mod r, where the points are encoded as x || y
+    function deriveChallenges(bytes calldata proof) internal pure returns (uint256, uint256, uint256) {
+        bytes32 gamma = keccak256(abi.encodePacked("gamma", proof[PROOF_L:PROOF_Z]));
+        bytes32 alpha = keccak256(abi.encodePacked("alpha", gamma, proof[PROOF_Z:PROOF_H_0]));
+        bytes32 zeta = keccak256(abi.encodePacked("zeta", alpha, proof[PROOF_H_0:PROOF_BATCH_H]));
+        return (uint256(gamma) % R_MOD, uint256(alpha) % R_MOD, uint256(zeta) % R_MOD);
+    }
+
+    // publicInputsAt returns PI(zeta) = Sum_i input[i]*L_i(zeta), and L_0(zeta), where
+    // L_i(zeta) = omega**i/n * (zeta**n-1)/(zeta-omega**i)
+    function publicInputsAt(uint256[] calldata input, uint256 zeta, uint256 zh) internal view returns (uint256 pi, uint256 lagrangeOne) {
+        uint256 c = mulmod(zh, N_INV, R_MOD);
+        uint256 w = 1;
+        lagrangeOne = mulmod(c, inverse(addmod(zeta, R_MOD - 1, R_MOD)), R_MOD);
+        for (uint256 i = 0; i < input.length; i++) {
+            uint256 li = mulmod(mulmod(c, w, R_MOD), inverse(addmod(zeta, R_MOD - w, R_MOD)), R_MOD);
+            pi = addmod(pi, mulmod(li, input[i], R_MOD), R_MOD);
+            w = mulmod(w, OMEGA, R_MOD);
+        }
+    }
+
+    // checkQuotient checks that the claimed quotient at zeta is
+    // (linearizedpolynomial + pi(zeta) + alpha*Z(u*zeta)*(a+s1+gamma)*(b+s2+gamma)*(c+gamma) - alpha**2*L1(zeta)) / (zeta**n-1)
+    function checkQuotient(bytes calldata proof, uint256 gamma, uint256 alpha, uint256 zh, uint256 pi, uint256 alphaSquareLagrange) internal view returns (bool) {
+        uint256 t = mulmod(
+            addmod(addmod(load(proof, PROOF_L_AT_ZETA), load(proof, PROOF_S1_AT_ZETA), R_MOD), gamma, R_MOD),
+            addmod(addmod(load(proof, PROOF_R_AT_ZETA), load(proof, PROOF_S2_AT_ZETA), R_MOD), gamma, R_MOD),
+            R_MOD
+        );
+        t = mulmod(t, addmod(load(proof, PROOF_O_AT_ZETA), gamma, R_MOD), R_MOD);
+        t = mulmod(mulmod(t, alpha, R_MOD), load(proof, PROOF_Z_SHIFTED_AT), R_MOD);
+
+        uint256 res = addmod(load(proof, PROOF_LINEARIZED_AT_ZETA), pi, R_MOD);
+        res = addmod(res, t, R_MOD);
+        res = addmod(res, R_MOD - alphaSquareLagrange, R_MOD);
+        res = mulmod(res, inverse(zh), R_MOD);
+
+        return res == load(proof, PROOF_QUOTIENT_AT_ZETA);
+    }
+
+    // foldedHDigest returns Comm(h1) + zeta**(n+2)*Comm(h2) + zeta**2(n+2)*Comm(h3)
+    function foldedHDigest(bytes calldata proof, uint256 zeta) internal view returns (G1Point memory res) {
+        uint256 zetaPower = expmod(zeta, N + 2);
+        res = ecMul(loadPoint(proof, PROOF_H_2), zetaPower);
+        res = ecAdd(res, loadPoint(proof, PROOF_H_1));
+        res = ecMul(res, zetaPower);
+        res = ecAdd(res, loadPoint(proof, PROOF_H_0));
+    }
+
+    // linearizedPolynomialDigest returns
+    // l*ql+r*qr+rl*qm+o*qo+qk +
+    // alpha*( Z(uzeta)(a+s1+gamma)*(b+s2+gamma)*s3(X)-Z(X)(a+zeta+gamma)*(b+uzeta+gamma)*(c+u**2*zeta+gamma) ) +
+    // alpha**2*L1(zeta)*Z
+    function linearizedPolynomialDigest(bytes calldata proof, uint256 gamma, uint256 alpha, uint256 zeta, uint256 alphaSquareLagrange) internal view returns (G1Point memory res) {
+        (uint256 s3Coeff, uint256 zCoeff) = permutationCoefficients(proof, gamma, alpha, zeta, alphaSquareLagrange);
+        uint256 l = load(proof, PROOF_L_AT_ZETA);
+        uint256 r = load(proof, PROOF_R_AT_ZETA);
+
+        res = G1Point(QK_X, QK_Y);
+        res = ecAdd(res, ecMul(G1Point(QL_X, QL_Y), l));
+        res = ecAdd(res, ecMul(G1Point(QR_X, QR_Y), r));
+        res = ecAdd(res, ecMul(G1Point(QM_X, QM_Y), mulmod(l, r, R_MOD)));
+        res = ecAdd(res, ecMul(G1Point(QO_X, QO_Y), load(proof, PROOF_O_AT_ZETA)));
+        res = ecAdd(res, ecMul(G1Point(S3_X, S3_Y), s3Coeff));
+        res = ecAdd(res, ecMul(loadPoint(proof, PROOF_Z), zCoeff));
+    }
+
+    // permutationCoefficients returns the coefficients of s3 and Z in the linearized polynomial:
+    // alpha*Z(uzeta)(a+s1+gamma)*(b+s2+gamma) and alpha**2*L1(zeta) - alpha*(a+zeta+gamma)*(b+uzeta+gamma)*(c+u**2*zeta+gamma)
+    function permutationCoefficients(bytes calldata proof, uint256 gamma, uint256 alpha, uint256 zeta, uint256 alphaSquareLagrange) internal pure returns (uint256 s3Coeff, uint256 zCoeff) {
+        uint256 l = load(proof, PROOF_L_AT_ZETA);
+        uint256 r = load(proof, PROOF_R_AT_ZETA);
+
+        s3Coeff = mulmod(
+            addmod(addmod(l, load(proof, PROOF_S1_AT_ZETA), R_MOD), gamma, R_MOD),
+            addmod(addmod(r, load(proof, PROOF_S2_AT_ZETA), R_MOD), gamma, R_MOD),
+            R_MOD
+        );
+        s3Coeff = mulmod(mulmod(s3Coeff, load(proof, PROOF_Z_SHIFTED_AT), R_MOD), alpha, R_MOD);
+
+        uint256 t = addmod(addmod(l, zeta, R_MOD), gamma, R_MOD);
+        t = mulmod(t, addmod(addmod(mulmod(zeta, COSET_SHIFT_1, R_MOD), r, R_MOD), gamma, R_MOD), R_MOD);
+        t = mulmod(t, addmod(addmod(mulmod(zeta, COSET_SHIFT_2, R_MOD), load(proof, PROOF_O_AT_ZETA), R_MOD), gamma, R_MOD), R_MOD);
+        t = mulmod(t, alpha, R_MOD);
+        zCoeff = addmod(alphaSquareLagrange, R_MOD - t, R_MOD);
+    }
+
+    // batchVerify checks the batched opening proof at zeta and the opening proof of Z at zeta*omega
+    // with a single pairing: e(F, [1]) * e(-W, [x]) == 1 where, for a random u,
+    // F = folded digest + u*Z - (folded value + u*Z(zeta*omega))*[1] + zeta*W1 + u*zeta*omega*W2
+    // W = W1 + u*W2
+    function batchVerify(bytes calldata proof, G1Point memory foldedH, G1Point memory linearizedDigest, uint256 zeta) internal view returns (bool) {
+        (G1Point memory digest, uint256 value) = foldOpenings(proof, foldedH, linearizedDigest, zeta);
+
+        uint256 u = uint256(keccak256(abi.encodePacked(digest.X, digest.Y, value, proof))) % R_MOD;
+
+        G1Point memory w1 = loadPoint(proof, PROOF_BATCH_H);
+        G1Point memory w2 = loadPoint(proof, PROOF_Z_SHIFTED_H);
+
+        G1Point memory f = ecAdd(digest, ecMul(loadPoint(proof, PROOF_Z), u));
+        value = addmod(value, mulmod(u, load(proof, PROOF_Z_SHIFTED_AT), R_MOD), R_MOD);
+        f = ecAdd(f, negate(ecMul(G1Point(G1_X, G1_Y), value)));
+        f = ecAdd(f, ecMul(w1, zeta));
+        f = ecAdd(f, ecMul(w2, mulmod(u, mulmod(zeta, OMEGA, R_MOD), R_MOD)));
+
+        G1Point memory w = ecAdd(w1, ecMul(w2, u));
+
+        return pairing(f, negate(w));
+    }
+
+    // foldOpenings folds the digests and the claimed values of the batched opening proof at zeta
+    // with the powers of gamma = keccak256("gamma" || zeta || digests) mod r, where the points are encoded as x || y
+    function foldOpenings(bytes calldata proof, G1Point memory foldedH, G1Point memory linearizedDigest, uint256 zeta) internal view returns (G1Point memory digest, uint256 value) {
+        G1Point[7] memory digests;
+        digests[0] = foldedH;
+        digests[1] = linearizedDigest;
+        digests[2] = loadPoint(proof, PROOF_L);
+        digests[3] = loadPoint(proof, PROOF_R);
+        digests[4] = loadPoint(proof, PROOF_O);
+        digests[5] = G1Point(S1_X, S1_Y);
+        digests[6] = G1Point(S2_X, S2_Y);
+
+        bytes memory buf = abi.encodePacked("gamma", zeta);
+        for (uint256 i = 0; i < digests.length; i++) {
+            buf = abi.encodePacked(buf, digests[i].X, digests[i].Y);
+        }
+        uint256 gamma = uint256(keccak256(buf)) % R_MOD;
+
+        digest = digests[0];
+        value = load(proof, PROOF_QUOTIENT_AT_ZETA);
+        uint256 acc = 1;
+        for (uint256 i = 1; i < digests.length; i++) {
+            acc = mulmod(acc, gamma, R_MOD);
+            digest = ecAdd(digest, ecMul(digests[i], acc));
+            value = addmod(value, mulmod(acc, load(proof, PROOF_QUOTIENT_AT_ZETA + 0x20 * i), R_MOD), R_MOD);
+        }
+    }
+
+    function load(bytes calldata proof, uint256 offset) internal pure returns (uint256 res) {
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            res := calldataload(add(proof.offset, offset))
+        }
+    }
+
+    function loadPoint(bytes calldata proof, uint256 offset) internal pure returns (G1Point memory) {
+        return G1Point(load(proof, offset), load(proof, offset + 0x20));
+    }
+
+    /*
+     * @return The negation of p, i.e. p.plus(p.negate()) should be zero.
+     */
+    function negate(G1Point memory p) internal pure returns (G1Point memory) {
+        if (p.X == 0 && p.Y == 0) {
+            return G1Point(0, 0);
+        }
+        return G1Point(p.X, P_MOD - (p.Y % P_MOD));
+    }
+
+    function ecAdd(G1Point memory p, G1Point memory q) internal view returns (G1Point memory r) {
+        uint256[4] memory input = [p.X, p.Y, q.X, q.Y];
+        bool success;
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(gas(), 6, input, 0x80, r, 0x40)
+        }
+        require(success, "ec-add-failed");
+    }
+
+    function ecMul(G1Point memory p, uint256 s) internal view returns (G1Point memory r) {
+        uint256[3] memory input = [p.X, p.Y, s];
+        bool success;
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(gas(), 7, input, 0x60, r, 0x40)
+        }
+        require(success, "ec-mul-failed");
+    }
+
+    // pairing returns e(a, [1]) * e(b, [x]) == 1
+    function pairing(G1Point memory a, G1Point memory b) internal view returns (bool) {
+        uint256[12] memory input = [
+            a.X, a.Y, G2_X_0, G2_X_1, G2_Y_0, G2_Y_1,
+            b.X, b.Y, G2_SRS_X_0, G2_SRS_X_1, G2_SRS_Y_0, G2_SRS_Y_1
+        ];
+        uint256[1] memory out;
+        bool success;
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(gas(), 8, input, 0x180, out, 0x20)
+        }
+        require(success, "pairing-opcode-failed");
+        return out[0] != 0;
+    }
+
+    function expmod(uint256 base, uint256 e) internal view returns (uint256 res) {
+        uint256[6] memory input = [0x20, 0x20, 0x20, base, e, R_MOD];
+        uint256[1] memory out;
+        bool success;
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(gas(), 5, input, 0xc0, out, 0x20)
+        }
+        require(success, "expmod-failed");
+        res = out[0];
+    }
+
+    function inverse(uint256 x) internal view returns (uint256) {
+        require(x != 0, "inverse-of-zero");
+        return expmod(x, R_MOD - 2);
+    }
+}
+`
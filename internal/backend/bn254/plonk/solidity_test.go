@@ -0,0 +1,262 @@
+package plonk_test
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/plonk"
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+	bn254plonk "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"golang.org/x/crypto/sha3"
+)
+
+type solidityCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *solidityCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y), circuit.Z)
+	return nil
+}
+
+func TestSolidity(t *testing.T) {
+	ccs, err := frontend.Compile(curve.ID, backend.UNKNOWN, &solidityCircuit{}, frontend.WithBuilder(plonk.NewBuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := kzg.NewSRS(64, new(big.Int).SetUint64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := bn254plonk.Setup(ccs.(*cs.SparseR1CS), srs, backend.WithTranscriptHash(backend.KECCAK256))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := &solidityCircuit{X: 3, Y: 27, Z: 30}
+	var fullWitness, publicWitness bn254witness.Witness
+	if err := fullWitness.FromFullAssignment(assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := publicWitness.FromPublicAssignment(assignment); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := bn254plonk.Prove(ccs.(*cs.SparseR1CS), pk, fullWitness, backend.ProverOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// contract
+	var buf bytes.Buffer
+	if err := vk.ExportSolidity(&buf); err != nil {
+		t.Fatal(err)
+	}
+	contract := buf.String()
+	if strings.Contains(contract, "<no value>") || !strings.Contains(contract, "contract PlonkVerifier") {
+		t.Fatal("unexpected solidity contract")
+	}
+
+	// the contract derives the challenges with keccak256
+	sha256Vk := *vk
+	sha256Vk.TranscriptHash = backend.SHA256
+	if err := sha256Vk.ExportSolidity(&buf); err == nil {
+		t.Fatal("contract exported for a sha256 transcript")
+	}
+
+	// proof encoding
+	encoded := proof.MarshalSolidity()
+	if len(encoded) != 0x340 {
+		t.Fatalf("expected a proof of %d bytes, got %d", 0x340, len(encoded))
+	}
+	if !solidityVerify(vk, encoded, publicWitness) {
+		t.Fatal("valid proof rejected")
+	}
+
+	// wrong public input
+	wrongWitness := make(bn254witness.Witness, len(publicWitness))
+	copy(wrongWitness, publicWitness)
+	wrongWitness[0].SetUint64(28)
+	if solidityVerify(vk, encoded, wrongWitness) {
+		t.Fatal("proof accepted with a wrong public input")
+	}
+
+	// wrong claimed value
+	tampered := make([]byte, len(encoded))
+	copy(tampered, encoded)
+	tampered[0x25f] ^= 1
+	if solidityVerify(vk, tampered, publicWitness) {
+		t.Fatal("tampered proof accepted")
+	}
+}
+
+// solidityVerify mirrors the steps of the solidity verifier (see solidityTemplate)
+// on the encoded proof. The contract itself is compiled and executed in an EVM by the
+// integration/solidity module, which CI runs with solc installed.
+func solidityVerify(vk *bn254plonk.VerifyingKey, proof []byte, input []fr.Element) bool {
+	word := func(offset int) fr.Element {
+		var res fr.Element
+		res.SetBytes(proof[offset : offset+32])
+		return res
+	}
+	point := func(offset int) curve.G1Affine {
+		var res curve.G1Affine
+		res.X.SetBytes(proof[offset : offset+32])
+		res.Y.SetBytes(proof[offset+32 : offset+64])
+		return res
+	}
+	mul := func(p curve.G1Affine, s fr.Element) curve.G1Affine {
+		var b big.Int
+		s.ToBigIntRegular(&b)
+		p.ScalarMultiplication(&p, &b)
+		return p
+	}
+	challenge := func(name string, previous []byte, bound []byte) ([]byte, fr.Element) {
+		h := sha3.NewLegacyKeccak256()
+		h.Write([]byte(name))
+		h.Write(previous)
+		h.Write(bound)
+		raw := h.Sum(nil)
+		var res fr.Element
+		res.SetBytes(raw)
+		return raw, res
+	}
+
+	// challenges
+	rawGamma, gamma := challenge("gamma", nil, proof[0x00:0xc0])
+	rawAlpha, alpha := challenge("alpha", rawGamma, proof[0xc0:0x100])
+	_, zeta := challenge("zeta", rawAlpha, proof[0x100:0x1c0])
+
+	var one, zh, t fr.Element
+	one.SetOne()
+	zh.Exp(zeta, new(big.Int).SetUint64(vk.Size)).Sub(&zh, &one)
+
+	// public inputs
+	var pi, lagrangeOne, c, w, li fr.Element
+	c.Mul(&zh, &vk.SizeInv)
+	w.SetOne()
+	t.Sub(&zeta, &one).Inverse(&t)
+	lagrangeOne.Mul(&c, &t)
+	for i := 0; i < len(input); i++ {
+		t.Sub(&zeta, &w).Inverse(&t)
+		li.Mul(&c, &w).Mul(&li, &t)
+		li.Mul(&li, &input[i])
+		pi.Add(&pi, &li)
+		w.Mul(&w, &vk.Generator)
+	}
+	var alphaSquareLagrange fr.Element
+	alphaSquareLagrange.Mul(&lagrangeOne, &alpha).Mul(&alphaSquareLagrange, &alpha)
+
+	// quotient
+	l, r, o := word(0x240), word(0x260), word(0x280)
+	s1, s2, zu := word(0x2a0), word(0x2c0), word(0x320)
+	var a, b, res fr.Element
+	a.Add(&l, &s1).Add(&a, &gamma)
+	b.Add(&r, &s2).Add(&b, &gamma)
+	t.Add(&o, &gamma)
+	a.Mul(&a, &b).Mul(&a, &t).Mul(&a, &alpha).Mul(&a, &zu)
+	res = word(0x220)
+	res.Add(&res, &pi).Add(&res, &a).Sub(&res, &alphaSquareLagrange)
+	t.Inverse(&zh)
+	res.Mul(&res, &t)
+	quotient := word(0x200)
+	if !res.Equal(&quotient) {
+		return false
+	}
+
+	// folded h
+	var zetaPower fr.Element
+	zetaPower.Exp(zeta, new(big.Int).SetUint64(vk.Size+2))
+	foldedH := mul(point(0x180), zetaPower)
+	h1 := point(0x140)
+	foldedH.Add(&foldedH, &h1)
+	foldedH = mul(foldedH, zetaPower)
+	h0 := point(0x100)
+	foldedH.Add(&foldedH, &h0)
+
+	// linearized polynomial
+	var s3Coeff, zCoeff fr.Element
+	s3Coeff.Add(&l, &s1).Add(&s3Coeff, &gamma)
+	b.Add(&r, &s2).Add(&b, &gamma)
+	s3Coeff.Mul(&s3Coeff, &b).Mul(&s3Coeff, &zu).Mul(&s3Coeff, &alpha)
+	a.Add(&l, &zeta).Add(&a, &gamma)
+	b.Mul(&zeta, &vk.Shifter[0]).Add(&b, &r).Add(&b, &gamma)
+	a.Mul(&a, &b)
+	b.Mul(&zeta, &vk.Shifter[1]).Add(&b, &o).Add(&b, &gamma)
+	a.Mul(&a, &b).Mul(&a, &alpha)
+	zCoeff.Sub(&alphaSquareLagrange, &a)
+	var rl fr.Element
+	rl.Mul(&l, &r)
+	linearized := vk.Qk
+	for _, term := range []struct {
+		p curve.G1Affine
+		s fr.Element
+	}{{vk.Ql, l}, {vk.Qr, r}, {vk.Qm, rl}, {vk.Qo, o}, {vk.S[2], s3Coeff}, {point(0xc0), zCoeff}} {
+		p := mul(term.p, term.s)
+		linearized.Add(&linearized, &p)
+	}
+
+	// fold the openings at zeta
+	digests := []curve.G1Affine{foldedH, linearized, point(0x00), point(0x40), point(0x80), vk.S[0], vk.S[1]}
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte("gamma"))
+	zb := zeta.Bytes()
+	h.Write(zb[:])
+	for i := range digests {
+		b := digests[i].RawBytes()
+		h.Write(b[:])
+	}
+	var kzgGamma, acc fr.Element
+	kzgGamma.SetBytes(h.Sum(nil))
+	digest := digests[0]
+	value := word(0x200)
+	acc.SetOne()
+	for i := 1; i < len(digests); i++ {
+		acc.Mul(&acc, &kzgGamma)
+		p := mul(digests[i], acc)
+		digest.Add(&digest, &p)
+		t = word(0x200 + 0x20*i)
+		t.Mul(&t, &acc)
+		value.Add(&value, &t)
+	}
+
+	// batch the two openings with a random u
+	keccak := sha3.NewLegacyKeccak256()
+	db := digest.RawBytes()
+	vb := value.Bytes()
+	keccak.Write(db[:])
+	keccak.Write(vb[:])
+	keccak.Write(proof)
+	var u fr.Element
+	u.SetBytes(keccak.Sum(nil))
+
+	w1, w2 := point(0x1c0), point(0x2e0)
+	f := mul(point(0xc0), u)
+	f.Add(&f, &digest)
+	t.Mul(&u, &zu)
+	value.Add(&value, &t)
+	g := mul(vk.KZGSRS.G1[0], value)
+	g.Neg(&g)
+	f.Add(&f, &g)
+	g = mul(w1, zeta)
+	f.Add(&f, &g)
+	t.Mul(&zeta, &vk.Generator).Mul(&t, &u)
+	g = mul(w2, t)
+	f.Add(&f, &g)
+	wu := mul(w2, u)
+	wu.Add(&wu, &w1)
+	wu.Neg(&wu)
+
+	ok, err := curve.PairingCheck([]curve.G1Affine{f, wu}, []curve.G2Affine{vk.KZGSRS.G2[0], vk.KZGSRS.G2[1]})
+	return err == nil && ok
+}
@@ -17,10 +17,14 @@
 package plonk
 
 import (
-	"crypto/sha256"
 	"errors"
+	"fmt"
+	"hash"
+	"io"
 	"math/big"
 
+	"text/template"
+
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
@@ -31,36 +35,139 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/backend"
 )
 
 var (
-	errWrongClaimedQuotient = errors.New("claimed quotient is not as expected")
+	errWrongClaimedQuotient  = errors.New("claimed quotient is not as expected")
+	errWrongNbClaimedValues  = errors.New("number of claimed values is not as expected")
+	errBatchSizeMismatch     = errors.New("number of proofs, verifying keys and public witnesses don't match")
+	errDifferentSRS          = errors.New("verifying keys don't share the same kzg srs")
+	errUnknownTranscriptHash = errors.New("unknown transcript hash")
 )
 
+// Verify verifies a proof with given VerifyingKey and publicWitness
 func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness) error {
+	digests, proofs, err := verify(proof, vk, publicWitness)
+	if err != nil {
+		return err
+	}
+	return kzg.BatchVerifyMultiPoints(digests, proofs, vk.KZGSRS)
+}
+
+// BatchVerify verifies several proofs at once; proofs[i] is verified against vks[i] and publicWitnesses[i].
+// The verifying keys may belong to different circuits, but must share the same KZG SRS.
+//
+// The claimed quotients are checked for each proof, then the KZG opening proofs of all the proofs
+// are folded with random coefficients into a single pairing check.
+// If it fails, the proofs are verified one by one and the error identifies the first invalid proof.
+func BatchVerify(proofs []*Proof, vks []*VerifyingKey, publicWitnesses []bn254witness.Witness) error {
+	if len(proofs) != len(vks) || len(proofs) != len(publicWitnesses) {
+		return errBatchSizeMismatch
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+
+	srs := vks[0].KZGSRS
+	var digests []kzg.Digest
+	var openings []kzg.OpeningProof
+	for i := 0; i < len(proofs); i++ {
+		if !sameSRS(srs, vks[i].KZGSRS) {
+			return fmt.Errorf("proof %d: %w", i, errDifferentSRS)
+		}
+		d, o, err := verify(proofs[i], vks[i], publicWitnesses[i])
+		if err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+		digests = append(digests, d...)
+		openings = append(openings, o...)
+	}
+
+	if err := kzg.BatchVerifyMultiPoints(digests, openings, srs); err == nil {
+		return nil
+	}
+
+	// find the culprit
+	for i := 0; i < len(proofs); i++ {
+		if err := Verify(proofs[i], vks[i], publicWitnesses[i]); err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+	}
+	return kzg.ErrVerifyOpeningProof
+}
+
+// sameSRS returns true if the pairing checks of kzg.BatchVerifyMultiPoints use
+// the same points of a and b, that is [1]1, [1]2 and [α]2
+func sameSRS(a, b *kzg.SRS) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	return len(a.G1) > 0 && len(b.G1) > 0 && a.G1[0].Equal(&b.G1[0]) &&
+		a.G2[0].Equal(&b.G2[0]) && a.G2[1].Equal(&b.G2[1])
+}
+
+// verify checks the claimed quotient of the proof, and returns the KZG opening
+// proofs (with their digests) which remain to be checked with kzg.BatchVerifyMultiPoints
+func verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness) ([]kzg.Digest, []kzg.OpeningProof, error) {
 
 	// pick a hash function to derive the challenge (the same as in the prover)
-	hFunc := sha256.New()
+	hFunc := vk.TranscriptHash.New()
+	if hFunc == nil {
+		return nil, nil, errUnknownTranscriptHash
+	}
 
 	// transcript to derive the challenge
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "alpha", "zeta")
+	fs := newTranscript(hFunc, vk)
+
+	nbClaimedValues := 7
+	if vk.NbLookupTables != 0 {
+		nbClaimedValues += 5
+		if len(proof.LookupShiftedOpening.ClaimedValues) != 4 {
+			return nil, nil, errWrongNbClaimedValues
+		}
+	}
+	if len(proof.BatchedProof.ClaimedValues) != nbClaimedValues {
+		return nil, nil, errWrongNbClaimedValues
+	}
 
 	// derive gamma from Comm(l), Comm(r), Comm(o)
 	gamma, err := deriveRandomness(&fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	// derive eta, then beta from Comm(h1Lookup), Comm(h2Lookup), and delta
+	var eta, beta, delta fr.Element
+	if vk.NbLookupTables != 0 {
+		if eta, err = deriveRandomness(&fs, "eta"); err != nil {
+			return nil, nil, err
+		}
+		if beta, err = deriveRandomness(&fs, "beta", &proof.LookupH[0], &proof.LookupH[1]); err != nil {
+			return nil, nil, err
+		}
+		if delta, err = deriveRandomness(&fs, "delta"); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z)
-	alpha, err := deriveRandomness(&fs, "alpha", &proof.Z)
+	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z) (and Comm(zLookup))
+	toBind := []*curve.G1Affine{&proof.Z}
+	if vk.NbLookupTables != 0 {
+		toBind = append(toBind, &proof.LookupZ)
+	}
+	alpha, err := deriveRandomness(&fs, "alpha", toBind...)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// derive zeta, the point of evaluation
 	zeta, err := deriveRandomness(&fs, "zeta", &proof.H[0], &proof.H[1], &proof.H[2])
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// evaluation of Z=X**m-1 at zeta
@@ -120,6 +227,24 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 									Add(&linearizedPolynomialZeta, &_s1).                // linearizedpolynomial+pi(zeta)+alpha*Z(u*zeta)*(a+s1+gamma)*(b+s2+gamma)*(c+gamma)
 									Sub(&linearizedPolynomialZeta, &alphaSquareLagrange) // linearizedpolynomial+pi(zeta)+(Z(u*zeta))*(a+s1+gamma)*(b+s2+gamma)*(c+gamma)*alpha-alpha**2*L1(zeta)
 
+	// lookup constraints: + alpha**3*lookup(zeta)
+	var ctDigest kzg.Digest
+	if vk.NbLookupTables != 0 {
+		if ctDigest, err = foldLookupTableDigest(vk, eta); err != nil {
+			return nil, nil, err
+		}
+		claimed := proof.BatchedProof.ClaimedValues[7:]
+		shifted := proof.LookupShiftedOpening.ClaimedValues
+		lookup := evalLookupConstraints(vk,
+			l, r, o, claimed[0], claimed[1], claimed[2], claimed[3], claimed[4],
+			shifted[3], shifted[1], shifted[2], shifted[0],
+			eta, beta, delta, alpha, zeta, zzeta)
+		lookup.Mul(&lookup, &alpha).
+			Mul(&lookup, &alpha).
+			Mul(&lookup, &alpha)
+		linearizedPolynomialZeta.Add(&linearizedPolynomialZeta, &lookup)
+	}
+
 	// Compute H(zeta) using the previous result: H(zeta) = prev_result/(zeta**n-1)
 	var zetaPowerMMinusOne fr.Element
 	zetaPowerMMinusOne.Sub(&zetaPowerM, &one)
@@ -127,7 +252,7 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 
 	// check that H(zeta) is as claimed
 	if !claimedQuotient.Equal(&linearizedPolynomialZeta) {
-		return errWrongClaimedQuotient
+		return nil, nil, errWrongClaimedQuotient
 	}
 
 	// compute the folded commitment to H: Comm(h1) + zeta**m*Comm(h2) + zeta**2m*Comm(h3)
@@ -180,11 +305,11 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		_s1, _s2, // second & third part
 	}
 	if _, err := linearizedPolynomialDigest.MultiExp(points, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Fold the first proof
-	foldedProof, foldedDigest, err := kzg.FoldProof([]kzg.Digest{
+	digests := []kzg.Digest{
 		foldedH,
 		linearizedPolynomialDigest,
 		proof.LRO[0],
@@ -192,25 +317,50 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		proof.LRO[2],
 		vk.S[0],
 		vk.S[1],
-	},
+	}
+	if vk.NbLookupTables != 0 {
+		digests = append(digests, vk.QLookup, ctDigest, proof.LookupH[0], proof.LookupH[1], proof.LookupZ)
+	}
+	foldedProof, foldedDigest, err := kzg.FoldProof(digests,
 		&proof.BatchedProof,
 		hFunc,
 	)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// Batch verify
-	return kzg.BatchVerifyMultiPoints([]kzg.Digest{
+	foldedDigests := []kzg.Digest{
 		foldedDigest,
 		proof.Z,
-	},
-		[]kzg.OpeningProof{
-			foldedProof,
-			proof.ZShiftedOpening,
-		},
-		vk.KZGSRS,
-	)
+	}
+	foldedProofs := []kzg.OpeningProof{
+		foldedProof,
+		proof.ZShiftedOpening,
+	}
+	if vk.NbLookupTables != 0 {
+		// fold the opening proof of zLookup, h1Lookup, h2Lookup, t at zeta*mu
+		lookupProof, lookupDigest, err := kzg.FoldProof(
+			[]kzg.Digest{proof.LookupZ, proof.LookupH[0], proof.LookupH[1], ctDigest},
+			&proof.LookupShiftedOpening,
+			hFunc,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		foldedDigests = append(foldedDigests, lookupDigest)
+		foldedProofs = append(foldedProofs, lookupProof)
+	}
+	return foldedDigests, foldedProofs, nil
+}
+
+// newTranscript returns the transcript used to derive the challenges, eta, beta and
+// delta are the challenges of the lookup argument
+func newTranscript(h hash.Hash, vk *VerifyingKey) fiatshamir.Transcript {
+	if vk.NbLookupTables != 0 {
+		return fiatshamir.NewTranscript(h, "gamma", "eta", "beta", "delta", "alpha", "zeta")
+	}
+	return fiatshamir.NewTranscript(h, "gamma", "alpha", "zeta")
 }
 
 func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
@@ -232,3 +382,29 @@ func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*cu
 	r.SetBytes(b)
 	return r, nil
 }
+
+// ExportSolidity writes a solidity Verifier contract on provided writer.
+// The contract expects proofs encoded with Proof.MarshalSolidity.
+//
+// The lookup argument is not supported, vk.KZGSRS must be set and the challenges must be derived
+// with keccak256 (see backend.WithTranscriptHash).
+// this is an experimental feature and gnark solidity generator has not been thoroughly tested
+func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+	if vk.NbLookupTables != 0 {
+		return errors.New("lookup argument is not supported by the solidity verifier")
+	}
+	if vk.KZGSRS == nil {
+		return errors.New("kzg srs is not set")
+	}
+	if vk.TranscriptHash != backend.KECCAK256 {
+		return errors.New("the solidity verifier requires a keccak256 transcript")
+	}
+
+	tmpl, err := template.New("").Parse(solidityTemplate)
+	if err != nil {
+		return err
+	}
+
+	// execute template
+	return tmpl.Execute(w, vk)
+}
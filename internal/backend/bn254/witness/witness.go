@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"reflect"
 
 	"github.com/consensys/gnark/frontend"
@@ -215,6 +216,27 @@ func ToJSON(w frontend.Circuit) (string, error) {
 	return string(prettyJSON), nil
 }
 
+// SolidityInput returns the public witness as the input argument of the verifyProof method
+// of the Groth16 solidity verifier (see groth16.VerifyingKey.ExportSolidity)
+func (witness *Witness) SolidityInput() []*big.Int {
+	res := make([]*big.Int, len(*witness))
+	for i := 0; i < len(*witness); i++ {
+		res[i] = (*witness)[i].ToBigIntRegular(new(big.Int))
+	}
+	return res
+}
+
+// MarshalSolidity returns the ABI encoding of the public witness as a fixed size uint256 array:
+// one word of 32 bytes, big endian, per element
+func (witness *Witness) MarshalSolidity() []byte {
+	res := make([]byte, 0, len(*witness)*fr.Bytes)
+	for i := 0; i < len(*witness); i++ {
+		b := (*witness)[i].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
+
 var tVariable reflect.Type
 
 func init() {
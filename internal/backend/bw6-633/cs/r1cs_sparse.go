@@ -28,9 +28,10 @@ import (
 
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/backend/compiled"
-	"github.com/consensys/gnark/internal/backend/ioutils"
 
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
 )
 
 // SparseR1CS represents a Plonk like circuit
@@ -100,12 +101,23 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverOption) ([]f
 		coefficientsNegInv[i].Neg(&coefficientsNegInv[i])
 	}
 
+	// rows of the lookup tables
+	tables := cs.lookupTables()
+
 	// loop through the constraints to solve the variables
 	for i := 0; i < len(cs.Constraints); i++ {
-		if err := cs.solveConstraint(cs.Constraints[i], &solution, coefficientsNegInv); err != nil {
-			return solution.values, fmt.Errorf("constraint %d: %w", i, err)
+		if tID, ok := cs.MLookups[i]; ok {
+			if err := cs.solveLookup(cs.Constraints[i], &solution); err != nil {
+				return solution.values, fmt.Errorf("constraint %d: %w", i, err)
+			}
+			err = cs.checkLookup(cs.Constraints[i], tables[tID], &solution)
+		} else {
+			if err := cs.solveConstraint(cs.Constraints[i], &solution, coefficientsNegInv); err != nil {
+				return solution.values, fmt.Errorf("constraint %d: %w", i, err)
+			}
+			err = cs.checkConstraint(cs.Constraints[i], &solution)
 		}
-		if err := cs.checkConstraint(cs.Constraints[i], &solution); err != nil {
+		if err != nil {
 			if dID, ok := cs.MDebug[i]; ok {
 				debugInfoStr := solution.logValue(cs.DebugInfo[dID])
 				return solution.values, fmt.Errorf("%w: %s", ErrUnsatisfiedConstraint, debugInfoStr)
@@ -270,6 +282,53 @@ func (cs *SparseR1CS) checkConstraint(c compiled.SparseR1C, solution *solution)
 
 }
 
+// lookupTables returns the rows of the lookup tables, as sets
+func (cs *SparseR1CS) lookupTables() []map[[3]fr.Element]struct{} {
+	tables := make([]map[[3]fr.Element]struct{}, len(cs.Tables))
+	for i, table := range cs.Tables {
+		tables[i] = make(map[[3]fr.Element]struct{}, len(table))
+		for _, row := range table {
+			tables[i][[3]fr.Element{cs.Coefficients[row[0]], cs.Coefficients[row[1]], cs.Coefficients[row[2]]}] = struct{}{}
+		}
+	}
+	return tables
+}
+
+// solveLookup computes the wires of a lookup constraint that are outputs of a hint
+func (cs *SparseR1CS) solveLookup(c compiled.SparseR1C, solution *solution) error {
+	for _, t := range []compiled.Term{c.L, c.R, c.O} {
+		vID := t.WireID()
+		if solution.solved[vID] {
+			continue
+		}
+		hint, ok := cs.MHints[vID]
+		if !ok {
+			return fmt.Errorf("wire %d of a lookup constraint is not instantiated", vID)
+		}
+		if err := solution.solveWithHint(vID, hint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLookup verifies that the values of the wires of the lookup constraint form a row of the table
+func (cs *SparseR1CS) checkLookup(c compiled.SparseR1C, table map[[3]fr.Element]struct{}, solution *solution) error {
+	row := [3]fr.Element{
+		solution.values[c.L.WireID()],
+		solution.values[c.R.WireID()],
+		solution.values[c.O.WireID()],
+	}
+	if _, ok := table[row]; !ok {
+		return fmt.Errorf("%w\n(%s, %s, %s) is not in the lookup table", ErrUnsatisfiedConstraint,
+			row[0].String(),
+			row[1].String(),
+			row[2].String(),
+		)
+	}
+	return nil
+}
+
 // ToHTML returns an HTML human-readable representation of the constraint system
 func (cs *SparseR1CS) ToHTML(w io.Writer) error {
 	t, err := template.New("scs.html").Funcs(template.FuncMap{
@@ -318,22 +377,30 @@ func (cs *SparseR1CS) CurveID() ecc.ID {
 	return ecc.BW6_633
 }
 
-// WriteTo encodes SparseR1CS into provided io.Writer using cbor
+// WriteTo encodes SparseR1CS into provided io.Writer, with a header holding the format version, the
+// curve and the backend, followed by compressed sections (see compiled.FormatVersion)
 func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
-	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
-	enc, err := cbor.CoreDetEncOptions().EncMode()
+	return cs.SparseR1CS.Encode(w, cs.CurveID(), cs.writeCoefficients)
+}
+
+// ReadFrom attempts to decode SparseR1CS from io.Reader, encoded with WriteTo. It returns an error
+// if the SparseR1CS was encoded with another version of the format, or for another curve.
+//
+// A SparseR1CS encoded in the legacy format (cbor, without header) by previous versions of gnark is
+// decoded too; encoding it again with WriteTo migrates it to the current format.
+func (cs *SparseR1CS) ReadFrom(r io.Reader) (int64, error) {
+	r, legacy, err := compiled.IsLegacy(r)
 	if err != nil {
 		return 0, err
 	}
-	encoder := enc.NewEncoder(&_w)
-
-	// encode our object
-	err = encoder.Encode(cs)
-	return _w.N, err
+	if legacy {
+		return cs.readLegacyFrom(r)
+	}
+	return cs.SparseR1CS.Decode(r, cs.CurveID(), cs.readCoefficients)
 }
 
-// ReadFrom attempts to decode SparseR1CS from io.Reader using cbor
-func (cs *SparseR1CS) ReadFrom(r io.Reader) (int64, error) {
+// readLegacyFrom decodes SparseR1CS from io.Reader using cbor, as encoded by previous versions of gnark
+func (cs *SparseR1CS) readLegacyFrom(r io.Reader) (int64, error) {
 	dm, err := cbor.DecOptions{
 		MaxArrayElements: 134217728,
 		MaxMapPairs:      134217728,
@@ -346,6 +413,14 @@ func (cs *SparseR1CS) ReadFrom(r io.Reader) (int64, error) {
 	return int64(decoder.NumBytesRead()), err
 }
 
+func (cs *SparseR1CS) writeCoefficients(w io.Writer) error {
+	return curve.NewEncoder(w).Encode(cs.Coefficients)
+}
+
+func (cs *SparseR1CS) readCoefficients(r io.Reader) error {
+	return curve.NewDecoder(r).Decode(&cs.Coefficients)
+}
+
 // SetLoggerOutput replace existing logger output with provided one
 // default uses os.Stdout
 // if nil is provided, logs are not printed
@@ -54,14 +54,65 @@ func (proof *Proof) CurveID() ecc.ID {
 
 // Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
 func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt backend.ProverOption) (*Proof, error) {
+	return prove(r1cs, pk.proverKey(), witness, opt)
+}
+
+// proverKey holds the elements of a proving key used by prove. The multi-exponentiations
+// on the points of the key are abstracted, so that the points may be decoded while
+// proving (see MappedProvingKey).
+type proverKey struct {
+	domain                   *fft.Domain
+	g1Alpha, g1Beta, g1Delta *curve.G1Affine
+	g2Beta, g2Delta          *curve.G2Affine
+	infinityA, infinityB     []bool
+	nbInfinityA, nbInfinityB uint64
+
+	// multi-exponentiations with [A(t)]1, [B(t)]1, [Z(t)]1, [Kpk(t)]1 and [B(t)]2
+	g1A, g1B, g1Z, g1K g1MultiExp
+	g2B                g2MultiExp
+}
+
+type g1MultiExp func(res *curve.G1Jac, scalars []fr.Element, config ecc.MultiExpConfig) error
+type g2MultiExp func(res *curve.G2Jac, scalars []fr.Element, config ecc.MultiExpConfig) error
+
+func (pk *ProvingKey) proverKey() *proverKey {
+	g1 := func(points []curve.G1Affine) g1MultiExp {
+		return func(res *curve.G1Jac, scalars []fr.Element, config ecc.MultiExpConfig) error {
+			_, err := res.MultiExp(points, scalars, config)
+			return err
+		}
+	}
+	return &proverKey{
+		domain:      &pk.Domain,
+		g1Alpha:     &pk.G1.Alpha,
+		g1Beta:      &pk.G1.Beta,
+		g1Delta:     &pk.G1.Delta,
+		g2Beta:      &pk.G2.Beta,
+		g2Delta:     &pk.G2.Delta,
+		infinityA:   pk.InfinityA,
+		infinityB:   pk.InfinityB,
+		nbInfinityA: pk.NbInfinityA,
+		nbInfinityB: pk.NbInfinityB,
+		g1A:         g1(pk.G1.A),
+		g1B:         g1(pk.G1.B),
+		g1Z:         g1(pk.G1.Z),
+		g1K:         g1(pk.G1.K),
+		g2B: func(res *curve.G2Jac, scalars []fr.Element, config ecc.MultiExpConfig) error {
+			_, err := res.MultiExp(pk.G2.B, scalars, config)
+			return err
+		},
+	}
+}
+
+func prove(r1cs *cs.R1CS, pk *proverKey, witness bw6_633witness.Witness, opt backend.ProverOption) (*Proof, error) {
 	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
 		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
 	}
 
 	// solve the R1CS and compute the a, b, c vectors
-	a := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	b := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	c := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
+	a := make([]fr.Element, len(r1cs.Constraints), pk.domain.Cardinality)
+	b := make([]fr.Element, len(r1cs.Constraints), pk.domain.Cardinality)
+	c := make([]fr.Element, len(r1cs.Constraints), pk.domain.Cardinality)
 	var wireValues []fr.Element
 	var err error
 	if wireValues, err = r1cs.Solve(witness, a, b, c, opt); err != nil {
@@ -89,7 +140,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	var h []fr.Element
 	chHDone := make(chan struct{}, 1)
 	go func() {
-		h = computeH(a, b, c, &pk.Domain)
+		h = computeH(a, b, c, pk.domain)
 		a = nil
 		b = nil
 		c = nil
@@ -102,9 +153,9 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
 
 	go func() {
-		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.nbInfinityA))
 		for i, j := 0, 0; j < len(wireValuesA); i++ {
-			if pk.InfinityA[i] {
+			if pk.infinityA[i] {
 				continue
 			}
 			wireValuesA[j] = wireValues[i]
@@ -113,9 +164,9 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		close(chWireValuesA)
 	}()
 	go func() {
-		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.nbInfinityB))
 		for i, j := 0, 0; j < len(wireValuesB); i++ {
-			if pk.InfinityB[i] {
+			if pk.infinityB[i] {
 				continue
 			}
 			wireValuesB[j] = wireValues[i]
@@ -142,7 +193,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	_s.ToBigInt(&s)
 
 	// computes r[δ], s[δ], kr[δ]
-	deltas := curve.BatchScalarMultiplicationG1(&pk.G1.Delta, []fr.Element{_r, _s, _kr})
+	deltas := curve.BatchScalarMultiplicationG1(pk.g1Delta, []fr.Element{_r, _s, _kr})
 
 	proof := &Proof{}
 	var bs1, ar curve.G1Jac
@@ -152,12 +203,12 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	chBs1Done := make(chan error, 1)
 	computeBS1 := func() {
 		<-chWireValuesB
-		if _, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		if err := pk.g1B(&bs1, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chBs1Done <- err
 			close(chBs1Done)
 			return
 		}
-		bs1.AddMixed(&pk.G1.Beta)
+		bs1.AddMixed(pk.g1Beta)
 		bs1.AddMixed(&deltas[1])
 		chBs1Done <- nil
 	}
@@ -165,12 +216,12 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 	chArDone := make(chan error, 1)
 	computeAR1 := func() {
 		<-chWireValuesA
-		if _, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		if err := pk.g1A(&ar, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chArDone <- err
 			close(chArDone)
 			return
 		}
-		ar.AddMixed(&pk.G1.Alpha)
+		ar.AddMixed(pk.g1Alpha)
 		ar.AddMixed(&deltas[0])
 		proof.Ar.FromJacobian(&ar)
 		chArDone <- nil
@@ -184,10 +235,10 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 		var krs, krs2, p1 curve.G1Jac
 		chKrs2Done := make(chan error, 1)
 		go func() {
-			_, err := krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			err := pk.g1Z(&krs2, h, ecc.MultiExpConfig{NbTasks: n / 2})
 			chKrs2Done <- err
 		}()
-		if _, err := krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		if err := pk.g1K(&krs, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chKrsDone <- err
 			return
 		}
@@ -233,14 +284,14 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt ba
 			nbTasks *= 2
 		}
 		<-chWireValuesB
-		if _, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
+		if err := pk.g2B(&Bs, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
 			return err
 		}
 
-		deltaS.FromAffine(&pk.G2.Delta)
+		deltaS.FromAffine(pk.g2Delta)
 		deltaS.ScalarMultiplication(&deltaS, &s)
 		Bs.AddAssign(&deltaS)
-		Bs.AddMixed(&pk.G2.Beta)
+		Bs.AddMixed(pk.g2Beta)
 
 		proof.Bs.FromJacobian(&Bs)
 		return nil
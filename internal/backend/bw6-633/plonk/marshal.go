@@ -19,11 +19,71 @@ package plonk
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
 
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
 	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 )
 
+// keyFormatVersion is the version of the binary format of the proving and verifying keys.
+//
+// A verifying key is encoded as a header:
+//
+//	magic "gnkp" | format version (uint16) | curve ID (uint16)
+//
+// followed by its fields; a proving key is encoded as its verifying key followed by its
+// own fields. Integers are big endian.
+//
+// Keys encoded without header by previous versions of gnark (legacy format, without lookup
+// tables nor transcript hash) are decoded too, with the sha256 transcript they were used
+// with; encoding them again with WriteTo migrates them to this format.
+const keyFormatVersion = 1
+
+var keyMagic = [4]byte{'g', 'n', 'k', 'p'}
+
+const keyHeaderSize = len(keyMagic) + 4
+
+// writeKeyHeader writes the header of a key, with the current keyFormatVersion
+func writeKeyHeader(w io.Writer) error {
+	var buf [keyHeaderSize]byte
+	copy(buf[:], keyMagic[:])
+	binary.BigEndian.PutUint16(buf[4:6], keyFormatVersion)
+	binary.BigEndian.PutUint16(buf[6:8], uint16(ecc.BW6_633))
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// readKeyHeader reads the header written by writeKeyHeader. It returns an error if the
+// format version is not keyFormatVersion, or if the key was encoded for another curve.
+//
+// If r holds a key in the legacy format, it returns legacy == true and a reader replaying
+// the bytes read from r.
+func readKeyHeader(r io.Reader) (_ io.Reader, legacy bool, err error) {
+	var buf [keyHeaderSize]byte
+	if _, err := io.ReadFull(r, buf[:len(keyMagic)]); err != nil {
+		return r, false, err
+	}
+	if !bytes.Equal(buf[:len(keyMagic)], keyMagic[:]) {
+		// a legacy key starts with the size of the domain, a small uint64
+		return io.MultiReader(bytes.NewReader(buf[:len(keyMagic)]), r), true, nil
+	}
+	if _, err := io.ReadFull(r, buf[len(keyMagic):]); err != nil {
+		return r, false, err
+	}
+	if version := binary.BigEndian.Uint16(buf[4:6]); version != keyFormatVersion {
+		return r, false, fmt.Errorf("key encoded with format version %d, this version of gnark supports version %d", version, keyFormatVersion)
+	}
+	if id := ecc.ID(binary.BigEndian.Uint16(buf[6:8])); id != ecc.BW6_633 {
+		return r, false, fmt.Errorf("key encoded for curve %s, expected %s", id, ecc.BW6_633)
+	}
+	return r, false, nil
+}
+
 // WriteTo writes binary encoding of Proof to w
 func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
 	enc := curve.NewEncoder(w)
@@ -49,6 +109,23 @@ func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
 		return n + enc.BytesWritten(), err
 	}
 	n2, err := proof.ZShiftedOpening.WriteTo(w)
+	n += n2
+	if err != nil || len(proof.BatchedProof.ClaimedValues) <= 7 {
+		return n + enc.BytesWritten(), err
+	}
+
+	// lookup argument
+	toEncode = []interface{}{
+		&proof.LookupH[0],
+		&proof.LookupH[1],
+		&proof.LookupZ,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+	n2, err = proof.LookupShiftedOpening.WriteTo(w)
 
 	return n + n2 + enc.BytesWritten(), err
 }
@@ -77,10 +154,27 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		return n + dec.BytesRead(), err
 	}
 	n2, err := proof.ZShiftedOpening.ReadFrom(r)
+	n += n2
+	if err != nil || len(proof.BatchedProof.ClaimedValues) <= 7 {
+		return n + dec.BytesRead(), err
+	}
+
+	// lookup argument
+	toDecode = []interface{}{
+		&proof.LookupH[0],
+		&proof.LookupH[1],
+		&proof.LookupZ,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	n2, err = proof.LookupShiftedOpening.ReadFrom(r)
 	return n + n2 + dec.BytesRead(), err
 }
 
-// WriteTo writes binary encoding of ProvingKey to w
+// WriteTo writes binary encoding of ProvingKey to w, starting with its verifying key
 func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
 	// encode the verifying key
 	n, err = pk.Vk.WriteTo(w)
@@ -125,6 +219,15 @@ func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
 		([]fr.Element)(pk.CS3),
 		pk.Permutation,
 	}
+	if pk.Vk.NbLookupTables != 0 {
+		toEncode = append(toEncode,
+			([]fr.Element)(pk.LQLookup),
+			([]fr.Element)(pk.CQLookup),
+		)
+		for i := 0; i < len(pk.LT); i++ {
+			toEncode = append(toEncode, ([]fr.Element)(pk.LT[i]), ([]fr.Element)(pk.CT[i]))
+		}
+	}
 
 	for _, v := range toEncode {
 		if err := enc.Encode(v); err != nil {
@@ -135,7 +238,8 @@ func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
 	return n + enc.BytesWritten(), nil
 }
 
-// ReadFrom reads from binary representation in r into ProvingKey
+// ReadFrom reads from binary representation in r into ProvingKey, in the format described
+// by keyFormatVersion or in the legacy format
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 	pk.Vk = &VerifyingKey{}
 	n, err := pk.Vk.ReadFrom(r)
@@ -173,6 +277,15 @@ func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 		(*[]fr.Element)(&pk.CS3),
 		&pk.Permutation,
 	}
+	if pk.Vk.NbLookupTables != 0 {
+		toDecode = append(toDecode,
+			(*[]fr.Element)(&pk.LQLookup),
+			(*[]fr.Element)(&pk.CQLookup),
+		)
+		for i := 0; i < len(pk.LT); i++ {
+			toDecode = append(toDecode, (*[]fr.Element)(&pk.LT[i]), (*[]fr.Element)(&pk.CT[i]))
+		}
+	}
 
 	for _, v := range toDecode {
 		if err := dec.Decode(v); err != nil {
@@ -184,8 +297,12 @@ func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 
 }
 
-// WriteTo writes binary encoding of VerifyingKey to w
+// WriteTo writes binary encoding of VerifyingKey to w, after the header described by
+// keyFormatVersion
 func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
+	if err := writeKeyHeader(w); err != nil {
+		return 0, err
+	}
 	enc := curve.NewEncoder(w)
 
 	toEncode := []interface{}{
@@ -203,20 +320,36 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 		&vk.Qm,
 		&vk.Qo,
 		&vk.Qk,
+		vk.NbLookupTables,
+		uint64(vk.TranscriptHash),
+	}
+	if vk.NbLookupTables != 0 {
+		toEncode = append(toEncode, &vk.QLookup, &vk.T[0], &vk.T[1], &vk.T[2], &vk.T[3])
 	}
 
 	for _, v := range toEncode {
 		if err := enc.Encode(v); err != nil {
-			return enc.BytesWritten(), err
+			return int64(keyHeaderSize) + enc.BytesWritten(), err
 		}
 	}
 
-	return enc.BytesWritten(), nil
+	return int64(keyHeaderSize) + enc.BytesWritten(), nil
 }
 
-// ReadFrom reads from binary representation in r into VerifyingKey
+// ReadFrom reads from binary representation in r into VerifyingKey, in the format described
+// by keyFormatVersion or in the legacy format
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	r, legacy, err := readKeyHeader(r)
+	if err != nil {
+		return 0, err
+	}
+	n := int64(keyHeaderSize)
+	if legacy {
+		n = 0 // the header bytes are replayed by r
+	}
+
 	dec := curve.NewDecoder(r)
+	var transcriptHash uint64
 	toDecode := []interface{}{
 		&vk.Size,
 		&vk.SizeInv,
@@ -233,12 +366,32 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		&vk.Qo,
 		&vk.Qk,
 	}
+	if legacy {
+		// legacy keys have no lookup tables, and were used with a sha256 transcript
+		vk.NbLookupTables = 0
+		transcriptHash = uint64(backend.SHA256)
+	} else {
+		toDecode = append(toDecode, &vk.NbLookupTables, &transcriptHash)
+	}
 
 	for _, v := range toDecode {
 		if err := dec.Decode(v); err != nil {
-			return dec.BytesRead(), err
+			return n + dec.BytesRead(), err
+		}
+	}
+	vk.TranscriptHash = backend.HashID(transcriptHash)
+	if vk.TranscriptHash.New() == nil {
+		return n + dec.BytesRead(), fmt.Errorf("%w (id %d)", errUnknownTranscriptHash, transcriptHash)
+	}
+
+	if vk.NbLookupTables != 0 {
+		toDecode = []interface{}{&vk.QLookup, &vk.T[0], &vk.T[1], &vk.T[2], &vk.T[3]}
+		for _, v := range toDecode {
+			if err := dec.Decode(v); err != nil {
+				return n + dec.BytesRead(), err
+			}
 		}
 	}
 
-	return dec.BytesRead(), nil
+	return n + dec.BytesRead(), nil
 }
@@ -24,7 +24,9 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/polynomial"
 	"github.com/consensys/gnark/internal/backend/bw6-633/cs"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	kzgg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
 )
 
 // ProvingKey stores the data needed to generate a proof:
@@ -35,6 +37,7 @@ import (
 // with the list of public inputs.
 // * sigma_1, sigma_2, sigma_3 in both basis
 // * the copy constraint permutation
+// * the lookup selector and the lookup table, if the circuit has lookup constraints
 type ProvingKey struct {
 	// Verifying Key is embedded into the proving key (needed by Prove)
 	Vk *VerifyingKey
@@ -55,6 +58,14 @@ type ProvingKey struct {
 
 	// position -> permuted position (position in [0,3*sizeSystem-1])
 	Permutation []int64
+
+	// lookup selector (L=Lagrange basis, C=canonical basis): 0 for a regular constraint,
+	// 1 + the ID of the table for a lookup constraint
+	LQLookup, CQLookup polynomial.Polynomial
+
+	// columns of the lookup table (L=Lagrange basis, C=canonical basis). Row j of the table
+	// with ID k is stored as ((k+1)*t_j0, (k+1)*t_j1, (k+1)*t_j2, (k+1)**2), first row is zero.
+	LT, CT [4]polynomial.Polynomial
 }
 
 // VerifyingKey stores the data needed to verify a proof:
@@ -62,6 +73,7 @@ type ProvingKey struct {
 // * Commitments of ql prepended with as many ones as there are public inputs
 // * Commitments of qr, qm, qo, qk prepended with as many zeroes as there are public inputs
 // * Commitments to S1, S2, S3
+// * Commitments to the lookup selector and lookup table columns, if the circuit has lookup constraints
 type VerifyingKey struct {
 	// Size circuit
 	Size              uint64
@@ -82,10 +94,25 @@ type VerifyingKey struct {
 	// Commitments to ql, qr, qm, qo prepended with as many zeroes (ones for l) as there are public inputs.
 	// In particular Qk is not complete.
 	Ql, Qr, Qm, Qo, Qk kzg.Digest
+
+	// NbLookupTables is the number of lookup tables, if 0 the lookup argument is skipped
+	NbLookupTables uint64
+
+	// TranscriptHash is the hash function used by the prover and the verifier to derive the challenges
+	TranscriptHash backend.HashID
+
+	// Commitments to the lookup selector and to the columns of the lookup table
+	QLookup kzg.Digest
+	T       [4]kzg.Digest
 }
 
 // Setup sets proving and verifying keys
-func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error) {
+func Setup(spr *cs.SparseR1CS, srs *kzg.SRS, opts ...func(opt *backend.SetupOption) error) (*ProvingKey, *VerifyingKey, error) {
+	opt, err := backend.NewSetupOption(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var pk ProvingKey
 	var vk VerifyingKey
 
@@ -95,7 +122,8 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	nbConstraints := len(spr.Constraints)
 
 	// fft domains
-	sizeSystem := uint64(nbConstraints + spr.NbPublicVariables) // spr.NbPublicVariables is for the placeholder constraints
+	sizeSystem := computeSizeSystem(spr)
+	table := buildLookupTable(spr)
 	pk.DomainNum = *fft.NewDomain(sizeSystem, 0, false)
 
 	// h, the quotient polynomial is of degree 3(n+1)+2, so it's in a 3(n+2) dim vector space,
@@ -111,6 +139,8 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	vk.SizeInv.SetUint64(vk.Size).Inverse(&vk.SizeInv)
 	vk.Generator.Set(&pk.DomainNum.Generator)
 	vk.NbPublicVariables = uint64(spr.NbPublicVariables)
+	vk.NbLookupTables = uint64(len(spr.Tables))
+	vk.TranscriptHash = opt.TranscriptHash
 
 	// shifters
 	vk.Shifter[0].Set(&pk.DomainNum.FinerGenerator)
@@ -165,8 +195,11 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	// set s1, s2, s3
 	computeLDE(&pk)
 
+	if vk.NbLookupTables != 0 {
+		computeLookupPolynomials(spr, &pk, table)
+	}
+
 	// Commit to the polynomials to set up the verifying key
-	var err error
 	if vk.Ql, err = kzg.Commit(pk.Ql, vk.KZGSRS); err != nil {
 		return nil, nil, err
 	}
@@ -191,6 +224,16 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	if vk.S[2], err = kzg.Commit(pk.CS3, vk.KZGSRS); err != nil {
 		return nil, nil, err
 	}
+	if vk.NbLookupTables != 0 {
+		if vk.QLookup, err = kzg.Commit(pk.CQLookup, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < len(vk.T); i++ {
+			if vk.T[i], err = kzg.Commit(pk.CT[i], vk.KZGSRS); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
 
 	return &pk, &vk, nil
 
@@ -256,6 +299,96 @@ func buildPermutation(spr *cs.SparseR1CS, pk *ProvingKey) {
 	}
 }
 
+// DomainSize returns the size of the fft domain of the circuit, a power of 2. The kzg SRS
+// given to Setup must have at least DomainSize(spr)+3 points.
+func DomainSize(spr *cs.SparseR1CS) uint64 {
+	return ecc.NextPowerOfTwo(computeSizeSystem(spr))
+}
+
+// computeSizeSystem returns the number of rows needed by the circuit
+func computeSizeSystem(spr *cs.SparseR1CS) uint64 {
+	sizeSystem := uint64(len(spr.Constraints) + spr.NbPublicVariables) // spr.NbPublicVariables is for the placeholder constraints
+	if len(spr.Tables) != 0 {
+		// the last row of the domain is not constrained by the lookup argument, so it must not
+		// be a lookup constraint, and the table (with its zero row, see buildLookupTable)
+		// must fit in the domain
+		sizeSystem++
+		sizeTable := uint64(1)
+		for _, table := range spr.Tables {
+			sizeTable += uint64(len(table))
+		}
+		if sizeTable > sizeSystem {
+			sizeSystem = sizeTable
+		}
+	}
+	return sizeSystem
+}
+
+// buildLookupTable returns the columns of the lookup table, the concatenation of
+// the tables of the circuit where row (t0, t1, t2) of the table with ID k is
+// stored as ((k+1)*t0, (k+1)*t1, (k+1)*t2, (k+1)**2), so that rows of different
+// tables are distinct. The first row is zero, it is the value looked up by the
+// constraints that are not lookup constraints.
+func buildLookupTable(spr *cs.SparseR1CS) [4][]fr.Element {
+	var res [4][]fr.Element
+	if len(spr.Tables) == 0 {
+		return res
+	}
+
+	size := 1
+	for _, table := range spr.Tables {
+		size += len(table)
+	}
+	for i := 0; i < len(res); i++ {
+		res[i] = make([]fr.Element, 1, size)
+	}
+
+	var id, idSquare, t fr.Element
+	for k, table := range spr.Tables {
+		id.SetUint64(uint64(k + 1))
+		idSquare.Square(&id)
+		for _, row := range table {
+			for i := 0; i < 3; i++ {
+				t.Mul(&id, &spr.Coefficients[row[i]])
+				res[i] = append(res[i], t)
+			}
+			res[3] = append(res[3], idSquare)
+		}
+	}
+
+	return res
+}
+
+// computeLookupPolynomials sets the lookup selector and the lookup table columns,
+// in Lagrange and canonical basis. The table is padded with its last row to the
+// size of the domain.
+func computeLookupPolynomials(spr *cs.SparseR1CS, pk *ProvingKey, table [4][]fr.Element) {
+
+	nbElmt := int(pk.DomainNum.Cardinality)
+
+	pk.LQLookup = make(polynomial.Polynomial, nbElmt)
+	offset := spr.NbPublicVariables
+	for i, tID := range spr.MLookups {
+		pk.LQLookup[offset+i].SetUint64(uint64(tID + 1))
+	}
+	pk.CQLookup = make(polynomial.Polynomial, nbElmt)
+	copy(pk.CQLookup, pk.LQLookup)
+	pk.DomainNum.FFTInverse(pk.CQLookup, fft.DIF, 0)
+	fft.BitReverse(pk.CQLookup)
+
+	for i := 0; i < len(table); i++ {
+		pk.LT[i] = make(polynomial.Polynomial, nbElmt)
+		copy(pk.LT[i], table[i])
+		for j := len(table[i]); j < nbElmt; j++ {
+			pk.LT[i][j] = table[i][len(table[i])-1]
+		}
+		pk.CT[i] = make(polynomial.Polynomial, nbElmt)
+		copy(pk.CT[i], pk.LT[i])
+		pk.DomainNum.FFTInverse(pk.CT[i], fft.DIF, 0)
+		fft.BitReverse(pk.CT[i])
+	}
+}
+
 // computeLDE computes the LDE (Lagrange basis) of the permutations
 // s1, s2, s3.
 //
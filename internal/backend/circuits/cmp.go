@@ -0,0 +1,36 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+type cmpCircuit struct {
+	X, Y frontend.Variable
+	Res  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cmpCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Cmp(circuit.X, circuit.Y), circuit.Res)
+
+	// comparisons with constants
+	api.AssertIsEqual(api.Cmp(circuit.X, 0), api.Sub(1, api.IsZero(circuit.X)))
+	api.AssertIsEqual(api.Cmp(-1, circuit.Y), api.Sub(1, api.IsZero(api.Add(circuit.Y, 1))))
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&cmpCircuit{X: 3, Y: 5, Res: -1},
+		&cmpCircuit{X: 5, Y: 5, Res: 0},
+		&cmpCircuit{X: -1, Y: 5, Res: 1},
+		&cmpCircuit{X: 0, Y: -1, Res: -1},
+	}
+	bad := []frontend.Circuit{
+		&cmpCircuit{X: 3, Y: 5, Res: 1},
+		&cmpCircuit{X: 5, Y: 5, Res: -1},
+		&cmpCircuit{X: -1, Y: 5, Res: 0},
+	}
+
+	addNewEntry("cmp", &cmpCircuit{}, good, bad, ecc.Implemented())
+}
@@ -0,0 +1,37 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+type divModCircuit struct {
+	A, B                frontend.Variable
+	Quotient, Remainder frontend.Variable `gnark:",public"`
+}
+
+func (circuit *divModCircuit) Define(api frontend.API) error {
+	q, r := api.DivMod(circuit.A, circuit.B, 64)
+	api.AssertIsEqual(q, circuit.Quotient)
+	api.AssertIsEqual(r, circuit.Remainder)
+
+	// constant divisor
+	q, r = api.DivMod(circuit.A, 10, 64)
+	api.AssertIsEqual(api.Add(api.Mul(q, 10), r), circuit.A)
+	return nil
+}
+
+func init() {
+	good := []frontend.Circuit{
+		&divModCircuit{A: 1234567, B: 1000, Quotient: 1234, Remainder: 567},
+		&divModCircuit{A: 3, B: 7, Quotient: 0, Remainder: 3},
+		&divModCircuit{A: "18446744073709551615", B: 1, Quotient: "18446744073709551615", Remainder: 0},
+	}
+	bad := []frontend.Circuit{
+		&divModCircuit{A: 1234567, B: 1000, Quotient: 1233, Remainder: 1567},
+		&divModCircuit{A: 3, B: 0, Quotient: 0, Remainder: 3},
+		&divModCircuit{A: "18446744073709551616", B: 2, Quotient: "9223372036854775808", Remainder: 0},
+	}
+
+	addNewEntry("divmod", &divModCircuit{}, good, bad, ecc.Implemented())
+}
@@ -0,0 +1,37 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+type lookupCircuit struct {
+	Table    [5]frontend.Variable `gnark:",secret"`
+	Index    frontend.Variable    `gnark:",secret"`
+	Expected frontend.Variable    `gnark:",public"`
+}
+
+func (c *lookupCircuit) Define(api frontend.API) error {
+	selected := api.Lookup(c.Index, c.Table[:])
+	api.AssertIsEqual(selected, c.Expected)
+
+	// table of size a power of 2, with constant entries
+	selected = api.Lookup(api.Sub(c.Index, 1), []frontend.Variable{3, 5, 7, 11, 13, 17, 19, 23})
+	api.AssertIsDifferent(selected, 0)
+	return nil
+}
+
+func init() {
+	table := [5]frontend.Variable{42, 43, 44, 45, 46}
+	good := []frontend.Circuit{}
+	bad := []frontend.Circuit{}
+	for i := 1; i < 5; i++ {
+		good = append(good, &lookupCircuit{Table: table, Index: i, Expected: 42 + i})
+		bad = append(bad, &lookupCircuit{Table: table, Index: i, Expected: 41 + i})
+	}
+	// out of range index, the table being padded with its last entry
+	bad = append(bad, &lookupCircuit{Table: table, Index: 5, Expected: 46})
+	bad = append(bad, &lookupCircuit{Table: table, Index: 7, Expected: 46})
+
+	addNewEntry("lookup", &lookupCircuit{}, good, bad, ecc.Implemented())
+}
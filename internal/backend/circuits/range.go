@@ -61,7 +61,62 @@ func rangeCheck() {
 	addEntry("range", &circuit, &good, &bad, ecc.Implemented())
 }
 
+type rangeCheckBitsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *rangeCheckBitsCircuit) Define(api frontend.API) error {
+	api.RangeCheck(circuit.X, 64)
+	api.RangeCheck(api.Mul(circuit.X, circuit.Y), 10)
+	api.RangeCheck(api.Add(circuit.X, circuit.Y), 9) // linear expression
+	api.RangeCheck(circuit.X, 64)                    // already checked, no constraint is added
+	api.RangeCheck(circuit.Y, 1)
+	api.RangeCheck(255, 8)
+	return nil
+}
+
+func rangeCheckBits() {
+	good := []frontend.Circuit{
+		&rangeCheckBitsCircuit{X: 200, Y: 1},
+		&rangeCheckBitsCircuit{X: 511, Y: 0},
+	}
+	bad := []frontend.Circuit{
+		&rangeCheckBitsCircuit{X: 600, Y: 1},
+		&rangeCheckBitsCircuit{X: 200, Y: 2},
+		&rangeCheckBitsCircuit{X: "18446744073709551616", Y: 0},
+	}
+
+	addNewEntry("range_bits", &rangeCheckBitsCircuit{}, good, bad, ecc.Implemented())
+}
+
+type rangeCheckTighterCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *rangeCheckTighterCircuit) Define(api frontend.API) error {
+	api.RangeCheck(circuit.X, 64)
+	api.RangeCheck(circuit.X, 16) // tighter check on the same variable, after the first one
+	api.RangeCheck(circuit.X, 32)
+	return nil
+}
+
+func rangeCheckTighter() {
+	good := []frontend.Circuit{
+		&rangeCheckTighterCircuit{X: 0},
+		&rangeCheckTighterCircuit{X: 65535},
+	}
+	bad := []frontend.Circuit{
+		&rangeCheckTighterCircuit{X: 65536},
+		&rangeCheckTighterCircuit{X: "4294967295"},
+	}
+
+	addNewEntry("range_bits_tighter", &rangeCheckTighterCircuit{}, good, bad, ecc.Implemented())
+}
+
 func init() {
 	rangeCheckConstant()
 	rangeCheck()
+	rangeCheckBits()
+	rangeCheckTighter()
 }
@@ -0,0 +1,57 @@
+package circuits
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// xorCoefficientsCircuit checks that Xor and Or take into account the coefficients and all the
+// terms of their inputs
+type xorCoefficientsCircuit struct {
+	A, B        frontend.Variable // A == 1/2, so 2*A is boolean
+	Xor, XorCst frontend.Variable `gnark:",public"`
+	Or          frontend.Variable `gnark:",public"`
+	OrCst       frontend.Variable `gnark:",public"`
+	OrWeighted  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *xorCoefficientsCircuit) Define(api frontend.API) error {
+	a := api.Mul(circuit.A, 2) // term with a non-unit coefficient
+	b := api.Mul(circuit.B, 1)
+	api.AssertIsEqual(api.Xor(a, b), circuit.Xor)
+	api.AssertIsEqual(api.Xor(a, 1), circuit.XorCst)
+
+	nb := api.Sub(1, circuit.B) // several terms
+	api.AssertIsEqual(api.Or(nb, api.Xor(nb, a)), circuit.Or)
+	api.AssertIsEqual(api.Or(nb, 1), circuit.OrCst)
+	api.AssertIsEqual(api.Or(a, nb), circuit.OrWeighted)
+	api.AssertIsEqual(api.Or(a, 0), circuit.OrWeighted)
+	return nil
+}
+
+func init() {
+	var good, bad xorCoefficientsCircuit
+
+	var half big.Int
+	half.ModInverse(big.NewInt(2), ecc.BN254.Info().Fr.Modulus())
+
+	good.A = half
+	good.B = 1
+	good.Xor = 0
+	good.XorCst = 0
+	good.Or = 1
+	good.OrCst = 1
+	good.OrWeighted = 1
+
+	bad.A = half
+	bad.B = 1
+	bad.Xor = 0
+	bad.XorCst = 0
+	bad.Or = 0
+	bad.OrCst = 1
+	bad.OrWeighted = 1
+
+	addEntry("xor_coefficients", &xorCoefficientsCircuit{}, &good, &bad, []ecc.ID{ecc.BN254})
+}
@@ -18,8 +18,20 @@ package compiled
 type SparseR1CS struct {
 	CS
 	Constraints []SparseR1C
+
+	// Tables are the lookup tables of the circuit
+	Tables []LookupTable
+
+	// MLookups maps the ID of a lookup constraint to the ID of its table. A lookup
+	// constraint has no arithmetic part (all its coefficients are 0), it asserts
+	// that the values of its L, R, O wires form a row of the table.
+	MLookups map[int]int
 }
 
+// LookupTable is a table of constant rows, each entry is stored as the ID of
+// a coefficient
+type LookupTable [][3]int
+
 // GetNbConstraints returns the number of constraints
 func (cs *SparseR1CS) GetNbConstraints() int {
 	return len(cs.Constraints)
@@ -0,0 +1,348 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// FormatVersion is the version of the binary format of the constraint systems.
+//
+// A constraint system is encoded as a header:
+//
+//	magic "gnkc" | format version (uint16) | curve ID (uint16) | backend ID (uint16)
+//
+// followed by the sections constraints, coefficients, hints and debug, in this order.
+// Each section is encoded as its ID (uint8), the length of its content (uint64) and its
+// content compressed with DEFLATE. Integers are big endian.
+//
+// The content of the coefficients section is encoded by the curve-typed constraint system,
+// the other sections are encoded with CBOR.
+const FormatVersion = 1
+
+var formatMagic = [4]byte{'g', 'n', 'k', 'c'}
+
+// Section identifies a section of an encoded constraint system
+type Section uint8
+
+const (
+	SectionConstraints  Section = iota + 1 // number of variables and constraints
+	SectionCoefficients                    // coefficients of the constraints
+	SectionHints                           // solver hints
+	SectionDebug                           // logs, debug info and counters
+)
+
+func (s Section) String() string {
+	switch s {
+	case SectionConstraints:
+		return "constraints"
+	case SectionCoefficients:
+		return "coefficients"
+	case SectionHints:
+		return "hints"
+	case SectionDebug:
+		return "debug"
+	default:
+		return fmt.Sprintf("section(%d)", uint8(s))
+	}
+}
+
+// r1csConstraints is the content of the constraints section of a R1CS
+type r1csConstraints struct {
+	NbInternalVariables, NbPublicVariables, NbSecretVariables int
+	Constraints                                               []R1C
+}
+
+// sparseR1CSConstraints is the content of the constraints section of a SparseR1CS
+type sparseR1CSConstraints struct {
+	NbInternalVariables, NbPublicVariables, NbSecretVariables int
+	Constraints                                               []SparseR1C
+	Tables                                                    []LookupTable
+	MLookups                                                  map[int]int
+}
+
+// debugSection is the content of the debug section
+type debugSection struct {
+	Logs, DebugInfo []LogEntry
+	MDebug          map[int]int
+	Counters        []Counter
+}
+
+// Encode writes the R1CS in the binary format described by FormatVersion, with the curve
+// curveID; writeCoefficients writes the content of the coefficients section
+func (r1cs *R1CS) Encode(w io.Writer, curveID ecc.ID, writeCoefficients func(io.Writer) error) (int64, error) {
+	constraints := r1csConstraints{
+		NbInternalVariables: r1cs.NbInternalVariables,
+		NbPublicVariables:   r1cs.NbPublicVariables,
+		NbSecretVariables:   r1cs.NbSecretVariables,
+		Constraints:         r1cs.Constraints,
+	}
+	return r1cs.CS.encode(w, curveID, backend.GROTH16, &constraints, writeCoefficients)
+}
+
+// Decode reads a R1CS written by Encode; readCoefficients reads the content of the
+// coefficients section. It returns an error if the format version, the curve or the
+// backend don't match.
+func (r1cs *R1CS) Decode(r io.Reader, curveID ecc.ID, readCoefficients func(io.Reader) error) (int64, error) {
+	var constraints r1csConstraints
+	n, err := r1cs.CS.decode(r, curveID, backend.GROTH16, &constraints, readCoefficients)
+	if err != nil {
+		return n, err
+	}
+	r1cs.NbInternalVariables = constraints.NbInternalVariables
+	r1cs.NbPublicVariables = constraints.NbPublicVariables
+	r1cs.NbSecretVariables = constraints.NbSecretVariables
+	r1cs.Constraints = constraints.Constraints
+	return n, nil
+}
+
+// Encode writes the SparseR1CS in the binary format described by FormatVersion, with the
+// curve curveID; writeCoefficients writes the content of the coefficients section
+func (cs *SparseR1CS) Encode(w io.Writer, curveID ecc.ID, writeCoefficients func(io.Writer) error) (int64, error) {
+	constraints := sparseR1CSConstraints{
+		NbInternalVariables: cs.NbInternalVariables,
+		NbPublicVariables:   cs.NbPublicVariables,
+		NbSecretVariables:   cs.NbSecretVariables,
+		Constraints:         cs.Constraints,
+		Tables:              cs.Tables,
+		MLookups:            cs.MLookups,
+	}
+	return cs.CS.encode(w, curveID, backend.PLONK, &constraints, writeCoefficients)
+}
+
+// Decode reads a SparseR1CS written by Encode; readCoefficients reads the content of the
+// coefficients section. It returns an error if the format version, the curve or the
+// backend don't match.
+func (cs *SparseR1CS) Decode(r io.Reader, curveID ecc.ID, readCoefficients func(io.Reader) error) (int64, error) {
+	var constraints sparseR1CSConstraints
+	n, err := cs.CS.decode(r, curveID, backend.PLONK, &constraints, readCoefficients)
+	if err != nil {
+		return n, err
+	}
+	cs.NbInternalVariables = constraints.NbInternalVariables
+	cs.NbPublicVariables = constraints.NbPublicVariables
+	cs.NbSecretVariables = constraints.NbSecretVariables
+	cs.Constraints = constraints.Constraints
+	cs.Tables = constraints.Tables
+	cs.MLookups = constraints.MLookups
+	return n, nil
+}
+
+func (cs *CS) encode(w io.Writer, curveID ecc.ID, backendID backend.ID, constraints interface{}, writeCoefficients func(io.Writer) error) (int64, error) {
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	if err := WriteHeader(&_w, curveID, backendID); err != nil {
+		return _w.N, err
+	}
+
+	debug := debugSection{
+		Logs:      cs.Logs,
+		DebugInfo: cs.DebugInfo,
+		MDebug:    cs.MDebug,
+		Counters:  cs.Counters,
+	}
+	sections := []struct {
+		id     Section
+		encode func(io.Writer) error
+	}{
+		{SectionConstraints, EncodeCBOR(constraints)},
+		{SectionCoefficients, writeCoefficients},
+		{SectionHints, EncodeCBOR(cs.MHints)},
+		{SectionDebug, EncodeCBOR(&debug)},
+	}
+	for _, s := range sections {
+		if err := WriteSection(&_w, s.id, s.encode); err != nil {
+			return _w.N, err
+		}
+	}
+	return _w.N, nil
+}
+
+func (cs *CS) decode(r io.Reader, curveID ecc.ID, backendID backend.ID, constraints interface{}, readCoefficients func(io.Reader) error) (int64, error) {
+	_r := ioutils.ReaderCounter{R: r} // wraps reader to count the bytes read
+	if err := ReadHeader(&_r, curveID, backendID); err != nil {
+		return _r.N, err
+	}
+
+	var mHints map[int]*Hint
+	var debug debugSection
+	sections := []struct {
+		id     Section
+		decode func(io.Reader) error
+	}{
+		{SectionConstraints, DecodeCBOR(constraints)},
+		{SectionCoefficients, readCoefficients},
+		{SectionHints, DecodeCBOR(&mHints)},
+		{SectionDebug, DecodeCBOR(&debug)},
+	}
+	for _, s := range sections {
+		if err := ReadSection(&_r, s.id, s.decode); err != nil {
+			return _r.N, err
+		}
+	}
+
+	cs.MHints = mHints
+	cs.Logs = debug.Logs
+	cs.DebugInfo = debug.DebugInfo
+	cs.MDebug = debug.MDebug
+	cs.Counters = debug.Counters
+	return _r.N, nil
+}
+
+// WriteHeader writes the header of a constraint system for the curve curveID and the
+// backend backendID, with the current FormatVersion
+func WriteHeader(w io.Writer, curveID ecc.ID, backendID backend.ID) error {
+	var buf [len(formatMagic) + 6]byte
+	copy(buf[:], formatMagic[:])
+	binary.BigEndian.PutUint16(buf[4:6], FormatVersion)
+	binary.BigEndian.PutUint16(buf[6:8], uint16(curveID))
+	binary.BigEndian.PutUint16(buf[8:10], uint16(backendID))
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// ReadHeader reads the header written by WriteHeader. It returns an error if the format
+// version is not FormatVersion, or if the constraint system was encoded for another curve
+// or backend.
+func ReadHeader(r io.Reader, curveID ecc.ID, backendID backend.ID) error {
+	var buf [len(formatMagic) + 6]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return err
+	}
+	if !bytes.Equal(buf[:4], formatMagic[:]) {
+		return errors.New("invalid constraint system header")
+	}
+	if version := binary.BigEndian.Uint16(buf[4:6]); version != FormatVersion {
+		return fmt.Errorf("constraint system encoded with format version %d, this version of gnark supports version %d", version, FormatVersion)
+	}
+	if id := ecc.ID(binary.BigEndian.Uint16(buf[6:8])); id != curveID {
+		return fmt.Errorf("constraint system encoded for curve %s, expected %s", id, curveID)
+	}
+	if id := backend.ID(binary.BigEndian.Uint16(buf[8:10])); id != backendID {
+		return fmt.Errorf("constraint system encoded for backend %s, expected %s", id, backendID)
+	}
+	return nil
+}
+
+// IsLegacy reports whether r holds a constraint system in the legacy format, encoded with
+// CBOR and without header by previous versions of gnark. Such constraint systems can be
+// migrated by decoding them with the legacy decoder and encoding them again. The returned
+// reader replays the bytes read from r.
+func IsLegacy(r io.Reader) (io.Reader, bool, error) {
+	var magic [len(formatMagic)]byte
+	n, err := io.ReadFull(r, magic[:])
+	replay := io.MultiReader(bytes.NewReader(magic[:n]), r)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// too short for a header, ReadHeader reports the error
+		return replay, false, nil
+	}
+	if err != nil {
+		return replay, false, err
+	}
+	// the legacy encoding of a constraint system starts with a CBOR map, not with the
+	// (CBOR text string) magic
+	return replay, magic != formatMagic, nil
+}
+
+// WriteSection writes the section id: its ID, the length of its compressed content, and
+// the content written by encode, compressed with DEFLATE
+func WriteSection(w io.Writer, id Section, encode func(io.Writer) error) error {
+	var content bytes.Buffer
+	fw, err := flate.NewWriter(&content, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if err := encode(fw); err != nil {
+		return fmt.Errorf("%s section: %w", id, err)
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+
+	var buf [9]byte
+	buf[0] = byte(id)
+	binary.BigEndian.PutUint64(buf[1:], uint64(content.Len()))
+	if _, err := w.Write(buf[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(content.Bytes())
+	return err
+}
+
+// ReadSection reads the section id written by WriteSection, decode reading its
+// uncompressed content. It returns an error if the next section is not id.
+func ReadSection(r io.Reader, id Section, decode func(io.Reader) error) error {
+	var buf [9]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return err
+	}
+	if got := Section(buf[0]); got != id {
+		return fmt.Errorf("invalid constraint system: expected %s section, got %s section", id, got)
+	}
+	length := binary.BigEndian.Uint64(buf[1:])
+
+	lr := &io.LimitedReader{R: r, N: int64(length)}
+	zr := flate.NewReader(lr)
+	if err := decode(zr); err != nil {
+		return fmt.Errorf("%s section: %w", id, err)
+	}
+	if err := zr.Close(); err != nil {
+		return err
+	}
+
+	// skip what the decompressor didn't read
+	if _, err := io.Copy(ioutil.Discard, lr); err != nil {
+		return err
+	}
+	if lr.N != 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// EncodeCBOR returns a function encoding v with CBOR, for WriteSection
+func EncodeCBOR(v interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		enc, err := cbor.CoreDetEncOptions().EncMode()
+		if err != nil {
+			return err
+		}
+		return enc.NewEncoder(w).Encode(v)
+	}
+}
+
+// DecodeCBOR returns a function decoding v with CBOR, for ReadSection
+func DecodeCBOR(v interface{}) func(io.Reader) error {
+	return func(r io.Reader) error {
+		dm, err := cbor.DecOptions{
+			MaxArrayElements: 134217728,
+			MaxMapPairs:      134217728,
+		}.DecMode()
+		if err != nil {
+			return err
+		}
+		return dm.NewDecoder(r).Decode(v)
+	}
+}
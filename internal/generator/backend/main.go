@@ -107,6 +107,7 @@ func main() {
 				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mapped.go"), Templates: []string{"groth16/groth16.mapped.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
@@ -120,11 +121,43 @@ func main() {
 				panic(err) // TODO handle
 			}
 
+			// groth16 aggregation
+			if d.Curve == "BN254" || d.Curve == "BLS12-381" {
+				aggregationDir := filepath.Join(groth16Dir, "aggregation")
+				if err := os.MkdirAll(aggregationDir, 0700); err != nil {
+					panic(err)
+				}
+				entries = []bavard.Entry{
+					{File: filepath.Join(aggregationDir, "srs.go"), Templates: []string{"groth16/aggregation/aggregation.srs.go.tmpl", importCurve}},
+					{File: filepath.Join(aggregationDir, "prove.go"), Templates: []string{"groth16/aggregation/aggregation.prove.go.tmpl", importCurve}},
+					{File: filepath.Join(aggregationDir, "verify.go"), Templates: []string{"groth16/aggregation/aggregation.verify.go.tmpl", importCurve}},
+					{File: filepath.Join(aggregationDir, "marshal.go"), Templates: []string{"groth16/aggregation/aggregation.marshal.go.tmpl", importCurve}},
+				}
+				if err := bgen.Generate(d, "aggregation", "./template/zkpschemes/", entries...); err != nil {
+					panic(err)
+				}
+
+				entries = []bavard.Entry{
+					{File: filepath.Join(aggregationDir, "aggregation_test.go"), Templates: []string{"groth16/aggregation/tests/aggregation.go.tmpl", importCurve}},
+				}
+				if err := bgen.Generate(d, "aggregation_test", "./template/zkpschemes/", entries...); err != nil {
+					panic(err)
+				}
+
+				entries = []bavard.Entry{
+					{File: filepath.Join(aggregationDir, "transcript_test.go"), Templates: []string{"groth16/aggregation/tests/transcript.go.tmpl", importCurve}},
+				}
+				if err := bgen.Generate(d, "aggregation", "./template/zkpschemes/", entries...); err != nil {
+					panic(err)
+				}
+			}
+
 			// plonk
 			entries = []bavard.Entry{
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "lookup.go"), Templates: []string{"plonk/plonk.lookup.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal.go"), Templates: []string{"plonk/plonk.marshal.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal_test.go"), Templates: []string{"plonk/tests/marshal.go.tmpl", importCurve}},
 			}
@@ -37,13 +37,52 @@ const (
 	optOmit   Tag = "-"
 )
 
-func appendName(baseName, name string) string {
+// AppendName returns the full name of the child name of baseName, as given by Visit to the leaves
+func AppendName(baseName, name string) string {
 	if baseName == "" {
 		return name
 	}
 	return baseName + "_" + name
 }
 
+// FieldInfo returns the name and the visibility of a struct field, as resolved by Visit
+// from its gnark tag and the visibility of its parent. ok is false if the field is
+// ignored ("-"). An embedded field ("embed" option) has an empty name.
+func FieldInfo(field reflect.StructField, parentVisibility compiled.Visibility) (name string, visibility compiled.Visibility, ok bool, err error) {
+	// get gnark tag
+	tag := field.Tag.Get(string(tagKey))
+	if tag == string(optOmit) {
+		return "", compiled.Unset, false, nil
+	}
+
+	visibility = compiled.Secret
+	name = field.Name
+
+	if tag != "" {
+		// gnark tag is set
+		var opts tagOptions
+		name, opts = parseTag(tag)
+		if !isValidTag(name) {
+			name = field.Name
+		}
+		opts = tagOptions(strings.TrimSpace(string(opts)))
+		if opts == "" || opts.Contains(string(optSecret)) {
+			visibility = compiled.Secret
+		} else if opts.Contains(string(optPublic)) {
+			visibility = compiled.Public
+		} else if opts.Contains(string(optEmbed)) {
+			name = ""
+			visibility = compiled.Unset
+		} else {
+			return "", compiled.Unset, false, errors.New("invalid gnark struct tag option. must be \"public\", \"secret\",\"embed\" or \"-\"")
+		}
+	}
+	if parentVisibility != compiled.Unset {
+		visibility = parentVisibility // parent visibility overhides
+	}
+	return name, visibility, true, nil
+}
+
 // LeafHandler is the handler function that will be called when Visit reaches leafs of the struct
 type LeafHandler func(visibility compiled.Visibility, name string, tValue reflect.Value) error
 
@@ -61,39 +100,15 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 		for i := 0; i < tValue.NumField(); i++ {
 			field := tValue.Type().Field((i))
 
-			// get gnark tag
-			tag := field.Tag.Get(string(tagKey))
-			if tag == string(optOmit) {
-				continue // skipping "-"
-			}
-
-			visibility := compiled.Secret
-			name := field.Name
-
-			if tag != "" {
-				// gnark tag is set
-				var opts tagOptions
-				name, opts = parseTag(tag)
-				if !isValidTag(name) {
-					name = field.Name
-				}
-				opts = tagOptions(strings.TrimSpace(string(opts)))
-				if opts == "" || opts.Contains(string(optSecret)) {
-					visibility = compiled.Secret
-				} else if opts.Contains(string(optPublic)) {
-					visibility = compiled.Public
-				} else if opts.Contains(string(optEmbed)) {
-					name = ""
-					visibility = compiled.Unset
-				} else {
-					return errors.New("invalid gnark struct tag option. must be \"public\", \"secret\",\"embed\" or \"-\"")
-				}
+			name, visibility, ok, err := FieldInfo(field, parentVisibility)
+			if err != nil {
+				return err
 			}
-			if parentVisibility != compiled.Unset {
-				visibility = parentVisibility // parent visibility overhides
+			if !ok {
+				continue // skipping "-"
 			}
 
-			fullName := appendName(baseName, name)
+			fullName := AppendName(baseName, name)
 
 			f := tValue.FieldByName(field.Name)
 			if f.CanAddr() && f.Addr().CanInterface() {
@@ -134,7 +149,7 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 
 			val := tValue.Index(j)
 			if val.CanAddr() && val.Addr().CanInterface() {
-				if err := Visit(val.Addr().Interface(), appendName(baseName, strconv.Itoa(j)), parentVisibility, handler, target); err != nil {
+				if err := Visit(val.Addr().Interface(), AppendName(baseName, strconv.Itoa(j)), parentVisibility, handler, target); err != nil {
 					return err
 				}
 			}
@@ -0,0 +1,82 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merkle
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// The functions below work on complete binary trees of a fixed depth, with 2^depth leaves.
+// A leaf node is h(leaf) and an inner node is h(left, right), h being reset before each
+// hash: the roots are the same as the ones of gitlab.com/NebulousLabs/merkletree (or
+// gnark-crypto/accumulator/merkletree) built with the same hash on 2^depth leaves.
+//
+// A path is given by the siblings of the nodes from the leaf to the root (path[0] is the
+// sibling of the leaf node) and by the bits of the index of the leaf, least significant
+// bit first: index[i] is 1 if the node at height i is a right child. The bits can be
+// obtained from an index variable with api.ToBinary(index, depth).
+
+// ComputeRoot returns the root of the fixed-depth tree containing leaf at the position
+// given by the index bits, path being the siblings of the nodes from the leaf to the root.
+func ComputeRoot(api frontend.API, h hash.Hash, leaf frontend.Variable, path, index []frontend.Variable) (frontend.Variable, error) {
+	if len(path) != len(index) {
+		return nil, errors.New("path and index must have the same length")
+	}
+
+	h.Reset()
+	h.Write(leaf)
+	node := h.Sum()
+
+	for i := 0; i < len(path); i++ {
+		api.AssertIsBoolean(index[i])
+		left := api.Select(index[i], path[i], node)
+		right := api.Select(index[i], node, path[i])
+		h.Reset()
+		h.Write(left, right)
+		node = h.Sum()
+	}
+
+	return node, nil
+}
+
+// VerifyPath asserts that leaf is in the fixed-depth tree of root root, at the position
+// given by the index bits.
+func VerifyPath(api frontend.API, h hash.Hash, root, leaf frontend.Variable, path, index []frontend.Variable) error {
+	res, err := ComputeRoot(api, h, leaf, path, index)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res, root)
+	return nil
+}
+
+// UpdateLeaf asserts that oldLeaf is in the fixed-depth tree of root root, at the position
+// given by the index bits, and returns the root of the tree where oldLeaf is replaced with
+// newLeaf.
+//
+// The siblings do not change with the update, so the same path is used for both roots.
+// Successive updates of a tree (for instance the sender and then the receiver account of
+// a transfer) must use paths in the intermediate trees.
+func UpdateLeaf(api frontend.API, h hash.Hash, root, oldLeaf, newLeaf frontend.Variable, path, index []frontend.Variable) (frontend.Variable, error) {
+	if err := VerifyPath(api, h, root, oldLeaf, path, index); err != nil {
+		return nil, err
+	}
+	return ComputeRoot(api, h, newLeaf, path, index)
+}
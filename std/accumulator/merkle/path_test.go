@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merkle
+
+import (
+	"bytes"
+	gohash "hash"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/accumulator/merkletree"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/hash/poseidon"
+	"github.com/consensys/gnark/test"
+)
+
+const testDepth = 4
+
+type updateCircuit struct {
+	RootBefore, RootAfter frontend.Variable `gnark:",public"`
+	OldLeaf, NewLeaf      frontend.Variable
+	Path                  [testDepth]frontend.Variable
+	Index                 frontend.Variable
+
+	usePoseidon bool
+}
+
+func (circuit *updateCircuit) Define(api frontend.API) error {
+	var h hash.Hash
+	if circuit.usePoseidon {
+		p, err := poseidon.NewPoseidon(api)
+		if err != nil {
+			return err
+		}
+		h = &p
+	} else {
+		m, err := mimc.NewMiMC("seed", api)
+		if err != nil {
+			return err
+		}
+		h = &m
+	}
+
+	index := api.ToBinary(circuit.Index, testDepth)
+	root, err := UpdateLeaf(api, h, circuit.RootBefore, circuit.OldLeaf, circuit.NewLeaf, circuit.Path[:], index)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(root, circuit.RootAfter)
+	return nil
+}
+
+// nativeRoot returns the root of the complete tree of leaves and the path of leaves[index]
+func nativeRoot(h gohash.Hash, leaves []fr.Element, index int) (root []byte, path [][]byte) {
+	level := make([][]byte, len(leaves))
+	for i := range leaves {
+		b := leaves[i].Bytes()
+		h.Reset()
+		h.Write(b[:])
+		level[i] = h.Sum(nil)
+	}
+	for len(level) > 1 {
+		path = append(path, level[index^1])
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			h.Reset()
+			h.Write(level[2*i])
+			h.Write(level[2*i+1])
+			next[i] = h.Sum(nil)
+		}
+		level = next
+		index /= 2
+	}
+	return level[0], path
+}
+
+func TestUpdateLeaf(t *testing.T) {
+	poseidonHash, err := poseidon.NewNativeHash(ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		h           gohash.Hash
+		usePoseidon bool
+	}{
+		{"mimc", bn254.NewMiMC("seed"), false},
+		{"poseidon", poseidonHash, true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert := test.NewAssert(t)
+
+			leaves := make([]fr.Element, 1<<testDepth)
+			var buf bytes.Buffer
+			for i := range leaves {
+				leaves[i].SetRandom()
+				b := leaves[i].Bytes()
+				buf.Write(b[:])
+			}
+			index := 5
+			rootBefore, path := nativeRoot(tc.h, leaves, index)
+
+			// the root is the one of the NebulousLabs tree
+			expected, err := merkletree.ReaderRoot(&buf, tc.h, fr.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected, rootBefore) {
+				t.Fatal("roots mismatch")
+			}
+
+			oldLeaf := leaves[index]
+			leaves[index].SetUint64(42)
+			rootAfter, _ := nativeRoot(tc.h, leaves, index)
+
+			witness := updateCircuit{
+				RootBefore: rootBefore,
+				RootAfter:  rootAfter,
+				OldLeaf:    oldLeaf,
+				NewLeaf:    leaves[index],
+				Index:      index,
+			}
+			for i := range path {
+				witness.Path[i] = path[i]
+			}
+
+			circuit := updateCircuit{usePoseidon: tc.usePoseidon}
+			assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+			// wrong position
+			witness.Index = index + 1
+			assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+			// wrong old leaf
+			witness.Index = index
+			witness.OldLeaf = leaves[index]
+			assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package smt provides ZKP-circuit functions to prove that a key is or is not in a
+// sparse merkle tree, and to insert a key in the tree.
+//
+// The tree has a fixed depth and 2^depth leaves, the leaf of a key being at the position
+// given by the key. The leaf of a key in the tree is h(key, value), and the leaves of the
+// other keys are 0. An inner node is h(left, right), h being reset before each hash.
+//
+// A path is given by the siblings of the nodes from the leaf to the root (path[0] is
+// the sibling of the leaf), see Tree.Path to build it. The keys must be in [0, 2^depth),
+// which the gadgets enforce. The depth must be strictly less than the bit size of the
+// modulus r of the field, otherwise the bits of both k and k + r would be accepted for a
+// key k, giving it two leaves.
+package smt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// VerifyInclusion asserts that key is in the tree of root root, with the given value
+func VerifyInclusion(api frontend.API, h hash.Hash, root, key, value frontend.Variable, path []frontend.Variable) error {
+	index, err := toIndex(api, key, path)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(computeRoot(api, h, index, leaf(h, key, value), path), root)
+	return nil
+}
+
+// VerifyExclusion asserts that key is not in the tree of root root
+func VerifyExclusion(api frontend.API, h hash.Hash, root, key frontend.Variable, path []frontend.Variable) error {
+	index, err := toIndex(api, key, path)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(computeRoot(api, h, index, 0, path), root)
+	return nil
+}
+
+// Insert asserts that key is not in the tree of root root, and returns the root of
+// the tree where key is added with the given value.
+//
+// The siblings do not change with the insertion, so the same path is used for both roots.
+func Insert(api frontend.API, h hash.Hash, root, key, value frontend.Variable, path []frontend.Variable) (frontend.Variable, error) {
+	index, err := toIndex(api, key, path)
+	if err != nil {
+		return nil, err
+	}
+	api.AssertIsEqual(computeRoot(api, h, index, 0, path), root)
+	return computeRoot(api, h, index, leaf(h, key, value), path), nil
+}
+
+// toIndex returns the bits of key, the decomposition ensuring that key < 2^depth (see the
+// package documentation for the bound on the depth)
+func toIndex(api frontend.API, key frontend.Variable, path []frontend.Variable) ([]frontend.Variable, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty path")
+	}
+	if nbBits := api.Curve().Info().Fr.Modulus().BitLen(); len(path) >= nbBits {
+		return nil, fmt.Errorf("path too long: the depth must be less than %d", nbBits)
+	}
+	return api.ToBinary(key, len(path)), nil
+}
+
+func leaf(h hash.Hash, key, value frontend.Variable) frontend.Variable {
+	h.Reset()
+	h.Write(key, value)
+	return h.Sum()
+}
+
+// computeRoot returns the root of the tree whose leaf at the position given by the
+// index bits is leaf
+func computeRoot(api frontend.API, h hash.Hash, index []frontend.Variable, leaf frontend.Variable, path []frontend.Variable) frontend.Variable {
+	node := leaf
+	for i := 0; i < len(path); i++ {
+		left := api.Select(index[i], path[i], node)
+		right := api.Select(index[i], node, path[i])
+		h.Reset()
+		h.Write(left, right)
+		node = h.Sum()
+	}
+	return node
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smt
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const testDepth = 8
+
+type smtCircuit struct {
+	RootBefore, RootAfter frontend.Variable `gnark:",public"`
+
+	// Present is in the tree before the insertion of Nullifier
+	Present, PresentValue frontend.Variable
+	PresentPath           [testDepth]frontend.Variable
+
+	Nullifier, NullifierValue frontend.Variable
+	NullifierPath             [testDepth]frontend.Variable
+}
+
+func (circuit *smtCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", api)
+	if err != nil {
+		return err
+	}
+	if err := VerifyInclusion(api, &h, circuit.RootBefore, circuit.Present, circuit.PresentValue, circuit.PresentPath[:]); err != nil {
+		return err
+	}
+	root, err := Insert(api, &h, circuit.RootBefore, circuit.Nullifier, circuit.NullifierValue, circuit.NullifierPath[:])
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(root, circuit.RootAfter)
+	return VerifyExclusion(api, &h, circuit.RootBefore, circuit.Nullifier, circuit.NullifierPath[:])
+}
+
+func TestTree(t *testing.T) {
+	tree, err := New(ecc.BN254, bn254.NewMiMC("seed"), testDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyRoot := tree.Root()
+
+	if err := tree.Insert(big.NewInt(1<<testDepth), big.NewInt(1)); err != errKeyOutOfRange {
+		t.Fatal("key out of range accepted")
+	}
+	if err := tree.Insert(big.NewInt(3), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Insert(big.NewInt(3), big.NewInt(2)); err != errKeyExists {
+		t.Fatal("key inserted twice")
+	}
+	if !tree.Contains(big.NewInt(3)) || tree.Contains(big.NewInt(2)) {
+		t.Fatal("wrong keys in the tree")
+	}
+
+	// the root doesn't depend on the order of the insertions
+	other, err := New(ecc.BN254, bn254.NewMiMC("seed"), testDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Insert(big.NewInt(200), big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Insert(big.NewInt(3), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Insert(big.NewInt(200), big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tree.Root(), other.Root()) || bytes.Equal(tree.Root(), emptyRoot) {
+		t.Fatal("wrong root")
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tree, err := New(ecc.BN254, bn254.NewMiMC("seed"), testDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []int64{1, 17, 42, 255} {
+		if err := tree.Insert(big.NewInt(key), big.NewInt(key*key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	present, nullifier := big.NewInt(42), big.NewInt(43)
+	witness := smtCircuit{
+		RootBefore:     tree.Root(),
+		Present:        present,
+		PresentValue:   42 * 42,
+		Nullifier:      nullifier,
+		NullifierValue: 1,
+	}
+	presentPath, err := tree.Path(present)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullifierPath, err := tree.Path(nullifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < testDepth; i++ {
+		witness.PresentPath[i] = presentPath[i]
+		witness.NullifierPath[i] = nullifierPath[i]
+	}
+	if err := tree.Insert(nullifier, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	witness.RootAfter = tree.Root()
+
+	assert := test.NewAssert(t)
+	assert.ProverSucceeded(&smtCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// wrong value
+	witness.PresentValue = 42
+	assert.ProverFailed(&smtCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// the nullifier is already in the tree
+	witness.PresentValue = 42 * 42
+	witness.RootBefore = tree.Root()
+	if presentPath, err = tree.Path(present); err != nil {
+		t.Fatal(err)
+	}
+	if nullifierPath, err = tree.Path(nullifier); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < testDepth; i++ {
+		witness.PresentPath[i] = presentPath[i]
+		witness.NullifierPath[i] = nullifierPath[i]
+	}
+	assert.ProverFailed(&smtCircuit{}, &witness, test.WithCurves(ecc.BN254))
+}
+
+type exclusionCircuit struct {
+	Root frontend.Variable `gnark:",public"`
+	Key  frontend.Variable
+	Path []frontend.Variable
+}
+
+func (circuit *exclusionCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", api)
+	if err != nil {
+		return err
+	}
+	return VerifyExclusion(api, &h, circuit.Root, circuit.Key, circuit.Path)
+}
+
+// TestAliasedIndex tries to prove that a key of the tree is excluded, with the bits of
+// key + r as index
+func TestAliasedIndex(t *testing.T) {
+	nbBits := fr.Modulus().BitLen()
+
+	// with nbBits bits, key and key + r < 2^nbBits would have two decompositions
+	if _, err := New(ecc.BN254, bn254.NewMiMC("seed"), nbBits); err == nil {
+		t.Fatal("tree deeper than the field accepted")
+	}
+	for _, depth := range []int{nbBits, nbBits + 1} {
+		circuit := exclusionCircuit{Path: make([]frontend.Variable, depth)}
+		if _, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit); err == nil {
+			t.Fatalf("path of length %d accepted", depth)
+		}
+	}
+
+	// at the maximal depth, key + r is reduced to key
+	depth := nbBits - 1
+	tree, err := New(ecc.BN254, bn254.NewMiMC("seed"), depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := big.NewInt(5)
+	if err := tree.Insert(key, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	aliased := new(big.Int).Add(key, fr.Modulus())
+	if aliased.BitLen() != nbBits {
+		t.Fatal("key + r should fit in the bit size of the field")
+	}
+	path, err := tree.Path(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := exclusionCircuit{Root: tree.Root(), Key: aliased, Path: make([]frontend.Variable, depth)}
+	for i := range path {
+		witness.Path[i] = path[i]
+	}
+
+	assert := test.NewAssert(t)
+	assert.ProverFailed(&exclusionCircuit{Path: make([]frontend.Variable, depth)}, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
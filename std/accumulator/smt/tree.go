@@ -0,0 +1,151 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smt
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+var (
+	errKeyOutOfRange = errors.New("key out of range")
+	errKeyExists     = errors.New("key already in the tree")
+)
+
+// Tree is the pure-go implementation of a sparse merkle tree, used to build the roots
+// and the paths checked by the gadgets of this package.
+//
+// Only the non empty nodes are stored, the empty subtrees being all the same.
+type Tree struct {
+	h     hash.Hash
+	depth int
+	empty [][]byte            // empty[i] is the root of an empty subtree of height i
+	nodes []map[string][]byte // nodes[i] maps the index of a non empty node of height i to its hash
+}
+
+// New returns an empty tree of the given depth, the keys being in [0, 2^depth).
+//
+// h must be the native counterpart of the hash used in the circuit, reading the data as
+// a sequence of h.Size() bytes big-endian field elements (like the MiMC and Poseidon
+// hashes do). depth must be strictly less than the bit size of the modulus of the scalar
+// field of curveID, so that the keys are canonical field elements (see toIndex).
+func New(curveID ecc.ID, h hash.Hash, depth int) (*Tree, error) {
+	if depth <= 0 || depth >= curveID.Info().Fr.Modulus().BitLen() {
+		return nil, errors.New("invalid depth")
+	}
+	t := &Tree{
+		h:     h,
+		depth: depth,
+		empty: make([][]byte, depth+1),
+		nodes: make([]map[string][]byte, depth+1),
+	}
+	t.empty[0] = make([]byte, h.Size())
+	for i := 0; i < depth; i++ {
+		t.empty[i+1] = t.sum(t.empty[i], t.empty[i])
+	}
+	for i := range t.nodes {
+		t.nodes[i] = make(map[string][]byte)
+	}
+	return t, nil
+}
+
+// Depth returns the depth of the tree
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() []byte {
+	return t.node(t.depth, new(big.Int))
+}
+
+// Contains returns true if key is in the tree
+func (t *Tree) Contains(key *big.Int) bool {
+	_, ok := t.nodes[0][key.String()]
+	return ok
+}
+
+// Insert adds key to the tree with the given value. The leaf is h(key, value), the
+// empty leaves being 0.
+func (t *Tree) Insert(key, value *big.Int) error {
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	if t.Contains(key) {
+		return errKeyExists
+	}
+
+	index := new(big.Int).Set(key)
+	t.nodes[0][index.String()] = t.sum(t.bytes(key), t.bytes(value))
+	for i := 0; i < t.depth; i++ {
+		var left, right big.Int
+		left.SetBit(index, 0, 0)
+		right.SetBit(index, 0, 1)
+		parent := t.sum(t.node(i, &left), t.node(i, &right))
+		index.Rsh(index, 1)
+		t.nodes[i+1][index.String()] = parent
+	}
+	return nil
+}
+
+// Path returns the siblings of the nodes from the leaf of key to the root, as expected
+// by the gadgets. The path is the same whether key is in the tree or not.
+func (t *Tree) Path(key *big.Int) ([][]byte, error) {
+	if err := t.checkKey(key); err != nil {
+		return nil, err
+	}
+	path := make([][]byte, t.depth)
+	index := new(big.Int).Set(key)
+	for i := 0; i < t.depth; i++ {
+		var sibling big.Int
+		sibling.SetBit(index, 0, index.Bit(0)^1)
+		path[i] = t.node(i, &sibling)
+		index.Rsh(index, 1)
+	}
+	return path, nil
+}
+
+// node returns the hash of the node of height i at the given index
+func (t *Tree) node(i int, index *big.Int) []byte {
+	if n, ok := t.nodes[i][index.String()]; ok {
+		return n
+	}
+	return t.empty[i]
+}
+
+func (t *Tree) checkKey(key *big.Int) error {
+	if key.Sign() < 0 || key.BitLen() > t.depth {
+		return errKeyOutOfRange
+	}
+	return nil
+}
+
+// bytes returns v as a h.Size() bytes big-endian integer
+func (t *Tree) bytes(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, t.h.Size()))
+}
+
+func (t *Tree) sum(data ...[]byte) []byte {
+	t.h.Reset()
+	for _, d := range data {
+		t.h.Write(d)
+	}
+	return t.h.Sum(nil)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bounded provides variable-length slices for gnark circuits, whose length is
+// a Variable bounded by a compile-time maximum, and gadgets to use them.
+//
+// A Slice can be a circuit input: its Values and Length are visited by the parser like
+// the other fields of the circuit (e.g. Msg_Values_0, ..., Msg_Length), with the
+// visibility of the field. The circuit definition allocates it with New(maxLen), and
+// the witness with Assign, which pads the values with zeros.
+//
+// Only the first Length values are part of the slice: the gadgets ignore the other
+// values (the padding), whatever they are, and fail if Length > maxLen.
+package bounded
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// Slice is a slice of Length elements, Length being at most len(Values)
+type Slice struct {
+	Values []frontend.Variable
+	Length frontend.Variable
+}
+
+// New returns a Slice of at most maxLen elements, to be used in the circuit definition
+func New(maxLen int) Slice {
+	return Slice{Values: make([]frontend.Variable, maxLen)}
+}
+
+// Assign returns a Slice of at most maxLen elements holding values, to be used in the
+// witness. It panics if len(values) > maxLen.
+func Assign(maxLen int, values ...frontend.Variable) Slice {
+	if len(values) > maxLen {
+		panic("too many values")
+	}
+	s := Slice{Values: make([]frontend.Variable, maxLen), Length: len(values)}
+	copy(s.Values, values)
+	for i := len(values); i < maxLen; i++ {
+		s.Values[i] = 0
+	}
+	return s
+}
+
+// MaxLen returns the maximum length of the slice
+func (s Slice) MaxLen() int {
+	return len(s.Values)
+}
+
+// Mask returns the flags of the elements of the slice: the i-th flag is 1 if
+// i < Length, 0 otherwise. It fails if Length > MaxLen.
+func (s Slice) Mask(api frontend.API) []frontend.Variable {
+	maxLen := len(s.Values)
+
+	if api.IsConstant(s.Length) {
+		l := api.ConstantValue(s.Length)
+		if !l.IsUint64() || l.Uint64() > uint64(maxLen) {
+			panic("length is larger than the maximum length")
+		}
+		mask := make([]frontend.Variable, maxLen)
+		for i := range mask {
+			if uint64(i) < l.Uint64() {
+				mask[i] = 1
+			} else {
+				mask[i] = 0
+			}
+		}
+		return mask
+	}
+
+	// isLen[i] == 1 iff Length == i, exactly one of them is set if Length <= maxLen
+	isLen := make([]frontend.Variable, maxLen+1)
+	for i := 0; i <= maxLen; i++ {
+		isLen[i] = api.IsZero(api.Sub(s.Length, i))
+	}
+	api.AssertIsEqual(sum(api, isLen), 1)
+
+	mask := make([]frontend.Variable, maxLen)
+	var isIn frontend.Variable = 1
+	for i := 0; i < maxLen; i++ {
+		isIn = api.Sub(isIn, isLen[i])
+		mask[i] = isIn
+	}
+	return mask
+}
+
+// Padded returns the values of the slice followed by zeros, as MaxLen elements.
+// It fails if Length > MaxLen.
+func (s Slice) Padded(api frontend.API) []frontend.Variable {
+	mask := s.Mask(api)
+	res := make([]frontend.Variable, len(s.Values))
+	for i := range res {
+		res[i] = api.Mul(mask[i], s.Values[i])
+	}
+	return res
+}
+
+// AssertIsEqual fails if the slices a and b don't have the same length and elements.
+// The maximum lengths may differ.
+func AssertIsEqual(api frontend.API, a, b Slice) {
+	api.AssertIsEqual(a.Length, b.Length)
+	pa, pb := a.Padded(api), b.Padded(api)
+	if len(pa) < len(pb) {
+		pa, pb = pb, pa
+	}
+	for i := range pa {
+		if i < len(pb) {
+			api.AssertIsEqual(pa[i], pb[i])
+		} else {
+			// the longest slice has no element beyond the maximum length of the other
+			api.AssertIsEqual(pa[i], 0)
+		}
+	}
+}
+
+// Hash returns the hash of the slice, as h(Length, values..., 0, ..., 0), the values
+// being padded with zeros to MaxLen elements. h is reset before hashing.
+//
+// As the length is hashed, slices which differ only by trailing zeros don't have the
+// same hash, but the hash depends on MaxLen.
+func Hash(api frontend.API, h hash.Hash, s Slice) frontend.Variable {
+	padded := s.Padded(api)
+	h.Reset()
+	h.Write(s.Length)
+	h.Write(padded...)
+	return h.Sum()
+}
+
+// Fold returns f(...f(f(init, Values[0]), Values[1])..., Values[Length-1]).
+//
+// f is called on every element up to MaxLen, with isIn set to 1 for the elements of
+// the slice and to 0 for the padding. Its result is ignored for the padding, but not
+// the constraints it adds: an assertion in f must hold on the padding too, which f
+// ensures by guarding it with isIn, e.g. api.RangeCheck(api.Mul(isIn, v), 8).
+func Fold(api frontend.API, s Slice, init frontend.Variable, f func(acc, v, isIn frontend.Variable) frontend.Variable) frontend.Variable {
+	mask := s.Mask(api)
+	acc := init
+	for i := range s.Values {
+		acc = api.Select(mask[i], f(acc, s.Values[i], mask[i]), acc)
+	}
+	return acc
+}
+
+// sum returns the sum of in, which may have less than 2 elements
+func sum(api frontend.API, in []frontend.Variable) frontend.Variable {
+	switch len(in) {
+	case 0:
+		return 0
+	case 1:
+		return in[0]
+	default:
+		return api.Add(in[0], in[1], in[2:]...)
+	}
+}
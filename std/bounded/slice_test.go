@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bounded
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+type sliceCircuit struct {
+	Msg, Copy   Slice
+	Digest, Sum frontend.Variable `gnark:",public"`
+}
+
+func (circuit *sliceCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", api)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(Hash(api, &h, circuit.Msg), circuit.Digest)
+
+	// the elements are bytes, the check being skipped on the padding
+	sum := Fold(api, circuit.Msg, 0, func(acc, v, isIn frontend.Variable) frontend.Variable {
+		api.RangeCheck(api.Mul(isIn, v), 8)
+		return api.Add(acc, v)
+	})
+	api.AssertIsEqual(sum, circuit.Sum)
+
+	AssertIsEqual(api, circuit.Msg, circuit.Copy)
+	return nil
+}
+
+// nativeHash returns the MiMC hash of length, values and maxLen - len(values) zeros
+func nativeHash(maxLen int, values ...uint64) []byte {
+	h := bn254.NewMiMC("seed")
+	elements := make([]fr.Element, maxLen+1)
+	elements[0].SetUint64(uint64(len(values)))
+	for i, v := range values {
+		elements[i+1].SetUint64(v)
+	}
+	for i := range elements {
+		b := elements[i].Bytes()
+		h.Write(b[:])
+	}
+	return h.Sum(nil)
+}
+
+func TestSlice(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := sliceCircuit{Msg: New(8), Copy: New(10)}
+
+	values := []frontend.Variable{3, 1, 4, 1, 5}
+	witness := sliceCircuit{
+		Msg:    Assign(8, values...),
+		Copy:   Assign(10, values...),
+		Digest: nativeHash(8, 3, 1, 4, 1, 5),
+		Sum:    14,
+	}
+	// the padding is ignored, even if it is not a byte
+	witness.Msg.Values[6] = 1000
+	witness.Copy.Values[9] = 42
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// empty and full slices
+	empty := sliceCircuit{Msg: Assign(8), Copy: Assign(10), Digest: nativeHash(8), Sum: 0}
+	assert.ProverSucceeded(&circuit, &empty, test.WithCurves(ecc.BN254))
+
+	full := []frontend.Variable{1, 2, 3, 4, 5, 6, 7, 8}
+	witness = sliceCircuit{
+		Msg:    Assign(8, full...),
+		Copy:   Assign(10, full...),
+		Digest: nativeHash(8, 1, 2, 3, 4, 5, 6, 7, 8),
+		Sum:    36,
+	}
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// the copy has a different length
+	witness = sliceCircuit{
+		Msg:    Assign(8, 1, 2, 0),
+		Copy:   Assign(10, 1, 2),
+		Digest: nativeHash(8, 1, 2, 0),
+		Sum:    3,
+	}
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// an element is not a byte
+	witness = sliceCircuit{
+		Msg:    Assign(8, 1, 1000),
+		Copy:   Assign(10, 1, 1000),
+		Digest: nativeHash(8, 1, 1000),
+		Sum:    1001,
+	}
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	// the length is larger than the maximum length
+	witness = sliceCircuit{
+		Msg:    Assign(8, full...),
+		Copy:   Assign(10, append(full, 0)...),
+		Digest: nativeHash(8, 1, 2, 3, 4, 5, 6, 7, 8),
+		Sum:    36,
+	}
+	witness.Msg.Length = 9
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,213 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package keccak provides a ZKP-circuit function to compute a Keccak-256 digest,
+// as used by Ethereum (keccak256), that is the original Keccak padding and not the
+// FIPS-202 (SHA3-256) one.
+//
+// The gadget operates on bytes: each Variable written to the hash must be in [0, 256),
+// which is enforced when the digest is computed. The Keccak-f[1600] permutation
+// works on 25 lanes of 64 bits, each bit being a Variable. Rotations and lane
+// permutations are free, θ costs an api.Xor per bit and χ an api.Mul and an
+// api.Xor per bit.
+//
+// See golang.org/x/crypto/sha3.NewLegacyKeccak256 for the reference implementation.
+package keccak
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+const (
+	// Size is the size of a Keccak-256 digest in bytes
+	Size = 32
+
+	// BlockSize is the rate of the Keccak-256 sponge in bytes
+	BlockSize = 136
+
+	nbRounds = 24
+)
+
+var roundConstants = [nbRounds]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotations[x][y] is the offset of the ρ step for lane (x, y)
+var rotations = [5][5]int{
+	{0, 36, 3, 41, 18},
+	{1, 44, 10, 45, 2},
+	{62, 6, 43, 15, 61},
+	{28, 55, 25, 21, 56},
+	{27, 20, 39, 8, 14},
+}
+
+// lane is a 64-bit word, as little-endian bits
+type lane [64]frontend.Variable
+
+// state of the permutation, lane (x, y) is at index x+5*y
+type state [25]lane
+
+// Keccak256 computes a Keccak-256 digest in a gnark circuit
+type Keccak256 struct {
+	data []frontend.Variable // bytes written so far
+	api  frontend.API        // underlying constraint system
+}
+
+// New returns a Keccak256 instance, than can be used in a gnark circuit
+func New(api frontend.API) Keccak256 {
+	return Keccak256{api: api}
+}
+
+// Write adds bytes to the running hash.
+func (h *Keccak256) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset resets the Hash to its initial state.
+func (h *Keccak256) Reset() {
+	h.data = nil
+}
+
+// Sum returns the Keccak-256 digest of the written bytes, as Size bytes.
+func (h *Keccak256) Sum() []frontend.Variable {
+	api := h.api
+
+	// pad10*1 with the Keccak domain separation bit
+	padded := make([]frontend.Variable, (len(h.data)/BlockSize+1)*BlockSize)
+	copy(padded, h.data)
+	for i := len(h.data); i < len(padded); i++ {
+		padded[i] = 0
+	}
+	if len(h.data) == len(padded)-1 {
+		padded[len(h.data)] = 0x81
+	} else {
+		padded[len(h.data)] = 0x01
+		padded[len(padded)-1] = 0x80
+	}
+
+	var s state
+	for i := 0; i < len(s); i++ {
+		s[i] = constantLane(0)
+	}
+
+	for b := 0; b < len(padded); b += BlockSize {
+		for i := 0; i < BlockSize/8; i++ {
+			var l lane
+			for j := 0; j < 8; j++ {
+				copy(l[8*j:8*(j+1)], api.ToBinary(padded[b+8*i+j], 8))
+			}
+			s[i] = h.xor(s[i], l)
+		}
+		s = h.permutation(s)
+	}
+
+	res := make([]frontend.Variable, Size)
+	for i := 0; i < Size; i++ {
+		res[i] = api.FromBinary(s[i/8][8*(i%8) : 8*(i%8+1)]...)
+	}
+	return res
+}
+
+// permutation applies Keccak-f[1600] to s
+func (h *Keccak256) permutation(s state) state {
+	for r := 0; r < nbRounds; r++ {
+		// θ
+		var c [5]lane
+		for x := 0; x < 5; x++ {
+			c[x] = h.xor(s[x], s[x+5], s[x+10], s[x+15], s[x+20])
+		}
+		for x := 0; x < 5; x++ {
+			d := h.xor(c[(x+4)%5], rotl(c[(x+1)%5], 1))
+			for y := 0; y < 5; y++ {
+				s[x+5*y] = h.xor(s[x+5*y], d)
+			}
+		}
+
+		// ρ and π
+		var b state
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = rotl(s[x+5*y], rotations[x][y])
+			}
+		}
+
+		// χ
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				s[x+5*y] = h.chi(b[x+5*y], b[(x+1)%5+5*y], b[(x+2)%5+5*y])
+			}
+		}
+
+		// ι
+		s[0] = h.xor(s[0], constantLane(roundConstants[r]))
+	}
+	return s
+}
+
+// xor returns the bitwise xor of the lanes
+func (h *Keccak256) xor(a lane, in ...lane) lane {
+	res := a
+	for _, l := range in {
+		for i := 0; i < 64; i++ {
+			res[i] = xorBit(h.api, res[i], l[i])
+		}
+	}
+	return res
+}
+
+// chi returns a ^ (^b & c)
+func (h *Keccak256) chi(a, b, c lane) lane {
+	var res lane
+	for i := 0; i < 64; i++ {
+		res[i] = xorBit(h.api, a[i], h.api.Mul(h.api.Sub(1, b[i]), c[i]))
+	}
+	return res
+}
+
+// xorBit returns a ^ b, without adding constraints if one of the bits is a constant
+func xorBit(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if api.IsConstant(a) {
+		a, b = b, a
+	}
+	if api.IsConstant(b) {
+		if api.ConstantValue(b).Sign() == 0 {
+			return a
+		}
+		return api.Sub(1, a)
+	}
+	return api.Xor(a, b)
+}
+
+func rotl(l lane, n int) lane {
+	var res lane
+	for i := 0; i < 64; i++ {
+		res[(i+n)%64] = l[i]
+	}
+	return res
+}
+
+func constantLane(v uint64) lane {
+	var res lane
+	for i := 0; i < 64; i++ {
+		res[i] = (v >> i) & 1
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keccak
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+type keccakCircuit struct {
+	ExpectedResult [Size]frontend.Variable `gnark:"data,public"`
+	Data           []frontend.Variable
+}
+
+func (circuit *keccakCircuit) Define(api frontend.API) error {
+	h := New(api)
+	h.Write(circuit.Data...)
+	result := h.Sum()
+	for i := 0; i < Size; i++ {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestKeccak256(t *testing.T) {
+	// sizes around the padding corner cases
+	for _, size := range []int{0, 3, BlockSize - 1, BlockSize} {
+		// the compiled circuit cache is per circuit type, circuits of different sizes need their own
+		assert := test.NewAssert(t)
+
+		data := make([]byte, size)
+		for i := 0; i < size; i++ {
+			data[i] = byte(7*i + 3)
+		}
+
+		h := sha3.NewLegacyKeccak256()
+		h.Write(data)
+		digest := h.Sum(nil)
+
+		circuit := keccakCircuit{Data: make([]frontend.Variable, size)}
+		witness := keccakCircuit{Data: make([]frontend.Variable, size)}
+		for i := 0; i < size; i++ {
+			witness.Data[i] = data[i]
+		}
+		for i := 0; i < Size; i++ {
+			witness.ExpectedResult[i] = digest[i]
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		witness.ExpectedResult[0] = digest[0] ^ 1
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// digest is the pure-go reference implementation of the Poseidon gadget
+type digest struct {
+	params    *params
+	state     [width]big.Int
+	data      []byte // data to hash
+	blockSize int
+}
+
+// NewNativeHash returns a pure-go implementation of Poseidon, computing the same
+// hashes as the gadget returned by NewPoseidon on the same curve.
+//
+// The written bytes are split into chunks of fr.Bytes, each chunk being read as a
+// big-endian integer, reduced modulo fr. If the data size is not a multiple of
+// fr.Bytes, the last chunk is shorter.
+func NewNativeHash(curveID ecc.ID) (hash.Hash, error) {
+	p, err := newParams(curveID)
+	if err != nil {
+		return nil, err
+	}
+	d := &digest{params: p, blockSize: curveID.Info().Fr.Bytes}
+	d.Reset()
+	return d, nil
+}
+
+// Reset resets the Hash to its initial state.
+func (d *digest) Reset() {
+	d.data = nil
+	for i := 0; i < width; i++ {
+		d.state[i].SetUint64(0)
+	}
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+func (d *digest) Sum(b []byte) []byte {
+	var elements []big.Int
+	for i := 0; i < len(d.data); i += d.blockSize {
+		end := i + d.blockSize
+		if end > len(d.data) {
+			end = len(d.data)
+		}
+		var e big.Int
+		e.SetBytes(d.data[i:end]).Mod(&e, d.params.modulus)
+		elements = append(elements, e)
+	}
+	d.data = nil // flush the data already hashed
+
+	// pad with 10* so that the number of elements is a multiple of the rate
+	elements = append(elements, *big.NewInt(1))
+	for len(elements)%rate != 0 {
+		elements = append(elements, big.Int{})
+	}
+
+	for i := 0; i < len(elements); i += rate {
+		for j := 0; j < rate; j++ {
+			d.state[1+j].Add(&d.state[1+j], &elements[i+j]).Mod(&d.state[1+j], d.params.modulus)
+		}
+		d.permutation()
+	}
+
+	res := make([]byte, d.blockSize)
+	d.state[1].FillBytes(res)
+	return append(b, res...)
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int {
+	return d.blockSize
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *digest) BlockSize() int {
+	return d.blockSize
+}
+
+// Write (via the embedded io.Writer interface) adds more data to the running hash.
+// It never returns an error.
+func (d *digest) Write(p []byte) (n int, err error) {
+	d.data = append(d.data, p...)
+	return len(p), nil
+}
+
+func (d *digest) permutation() {
+	var alpha big.Int
+	alpha.SetUint64(d.params.alpha)
+
+	for r := 0; r < len(d.params.constants); r++ {
+		for i := 0; i < width; i++ {
+			d.state[i].Add(&d.state[i], &d.params.constants[r][i]).Mod(&d.state[i], d.params.modulus)
+		}
+		if isFullRound(r) {
+			for i := 0; i < width; i++ {
+				d.state[i].Exp(&d.state[i], &alpha, d.params.modulus)
+			}
+		} else {
+			d.state[0].Exp(&d.state[0], &alpha, d.params.modulus)
+		}
+
+		var res [width]big.Int
+		var tmp big.Int
+		for i := 0; i < width; i++ {
+			for j := 0; j < width; j++ {
+				tmp.Mul(&d.params.mds[i][j], &d.state[j])
+				res[i].Add(&res[i], &tmp)
+			}
+			res[i].Mod(&res[i], d.params.modulus)
+		}
+		d.state = res
+	}
+}
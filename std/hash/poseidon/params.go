@@ -0,0 +1,189 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+const (
+	width           = 3         // number of field elements in the state
+	rate            = width - 1 // number of field elements absorbed per permutation
+	nbFullRounds    = 8         // R_F, half of them before the partial rounds and half after
+	nbPartialRounds = 57        // R_P, the reference value for α=5 and 128 bits of security (conservative for larger α)
+)
+
+// sBoxExponents stores, for each supported curve, the smallest α such that
+// x -> x**α is a permutation of the scalar field (i.e gcd(α, r-1) == 1)
+var sBoxExponents = map[ecc.ID]uint64{
+	ecc.BN254:     5,
+	ecc.BLS12_381: 5,
+	ecc.BLS12_377: 11,
+	ecc.BW6_761:   5,
+	ecc.BW6_633:   5,
+	ecc.BLS24_315: 7,
+}
+
+// params contains the constants of a Poseidon permutation instance
+type params struct {
+	alpha     uint64                // exponent of the S-box
+	constants [][width]big.Int      // round constants, one row per round
+	mds       [width][width]big.Int // MDS matrix used in the linear layer
+	modulus   *big.Int              // modulus of the scalar field
+}
+
+// newParams generates the Poseidon constants for the given curve.
+//
+// The round constants and the MDS matrix are drawn from the Grain LFSR, as done by
+// generate_parameters_grain.sage in the reference implementation of the paper: the
+// constants are rejection-sampled below the modulus, then the MDS matrix is the Cauchy
+// matrix M[i][j] = 1 / (x[i] + y[j]) built from the next 2*width field elements.
+// On BN254 this is the instance used by circomlib.
+//
+// The reference script additionally regenerates the MDS matrix if it admits an
+// infinitely long invariant subspace trail; this check is not performed here.
+func newParams(curveID ecc.ID) (*params, error) {
+	alpha, ok := sBoxExponents[curveID]
+	if !ok {
+		return nil, errors.New("unknown curve id")
+	}
+
+	p := &params{
+		alpha:     alpha,
+		constants: make([][width]big.Int, nbFullRounds+nbPartialRounds),
+		modulus:   curveID.Info().Fr.Modulus(),
+	}
+	n := p.modulus.BitLen()
+
+	g := newGrain(n)
+	for i := 0; i < len(p.constants); i++ {
+		for j := 0; j < width; j++ {
+			for {
+				g.readBits(&p.constants[i][j], n)
+				if p.constants[i][j].Cmp(p.modulus) < 0 {
+					break
+				}
+			}
+		}
+	}
+
+	ok = p.sampleMDS(g, n)
+	for !ok {
+		ok = p.sampleMDS(g, n)
+	}
+
+	return p, nil
+}
+
+// sampleMDS draws x[0..width-1] and y[0..width-1] from g and sets p.mds to the Cauchy
+// matrix they define. It returns false if the elements are not pairwise distinct or
+// if some x[i]+y[j] is zero, in which case new elements must be drawn.
+func (p *params) sampleMDS(g *grain, n int) bool {
+	var xy [2 * width]big.Int
+	for i := 0; i < len(xy); i++ {
+		g.readBits(&xy[i], n)
+		xy[i].Mod(&xy[i], p.modulus)
+	}
+	for i := 0; i < len(xy); i++ {
+		for j := 0; j < i; j++ {
+			if xy[i].Cmp(&xy[j]) == 0 {
+				return false
+			}
+		}
+	}
+
+	for i := 0; i < width; i++ {
+		for j := 0; j < width; j++ {
+			p.mds[i][j].Add(&xy[i], &xy[width+j]).Mod(&p.mds[i][j], p.modulus)
+			if p.mds[i][j].Sign() == 0 {
+				return false
+			}
+			p.mds[i][j].ModInverse(&p.mds[i][j], p.modulus)
+		}
+	}
+	return true
+}
+
+// grain is the self-shrinking Grain LFSR used to generate the Poseidon parameters
+type grain struct {
+	state [80]uint8
+}
+
+// newGrain initializes the LFSR with the description of the instance (prime field,
+// x**α S-box, field size in bits, width and number of rounds) and discards the
+// first 160 bits.
+func newGrain(n int) *grain {
+	g := &grain{}
+	i := 0
+	write := func(v, nbBits int) {
+		for k := nbBits - 1; k >= 0; k-- {
+			g.state[i] = uint8(v>>k) & 1
+			i++
+		}
+	}
+	write(1, 2) // prime field
+	write(0, 4) // S-box x**α
+	write(n, 12)
+	write(width, 12)
+	write(nbFullRounds, 10)
+	write(nbPartialRounds, 10)
+	for ; i < len(g.state); i++ {
+		g.state[i] = 1
+	}
+
+	for i := 0; i < 160; i++ {
+		g.next()
+	}
+	return g
+}
+
+// next updates the LFSR and returns the new bit
+func (g *grain) next() uint8 {
+	b := g.state[62] ^ g.state[51] ^ g.state[38] ^ g.state[23] ^ g.state[13] ^ g.state[0]
+	copy(g.state[:], g.state[1:])
+	g.state[len(g.state)-1] = b
+	return b
+}
+
+// bit returns the next output bit: bits are read in pairs, and the second one is
+// output only if the first one is set
+func (g *grain) bit() uint {
+	for {
+		if g.next() == 1 {
+			return uint(g.next())
+		}
+		g.next()
+	}
+}
+
+// readBits sets res to the integer formed by the next nbBits output bits, most
+// significant first
+func (g *grain) readBits(res *big.Int, nbBits int) {
+	res.SetUint64(0)
+	for i := 0; i < nbBits; i++ {
+		res.Lsh(res, 1)
+		res.SetBit(res, 0, g.bit())
+	}
+}
+
+// isFullRound returns true if the S-box is applied to the whole state in round r
+func isFullRound(r int) bool {
+	return r < nbFullRounds/2 || r >= nbFullRounds/2+nbPartialRounds
+}
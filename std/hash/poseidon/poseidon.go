@@ -0,0 +1,136 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package poseidon provides a ZKP-circuit function to compute a Poseidon hash.
+//
+// The permutation has a width of 3 field elements, used in a sponge construction
+// with a rate of 2. The data is padded with a single 1 followed by zeroes, so that
+// its length is a multiple of the rate, and the hash is the first element of the
+// rate part of the state.
+//
+// The permutation is the reference one: its round constants and MDS matrix are
+// generated with the Grain LFSR as in the reference implementation of the paper,
+// with R_F=8 full rounds and R_P=57 partial rounds. On BN254 it is the permutation
+// used by circomlib for two inputs, so that circomlib's Poseidon(a, b) is the first
+// element of the permutation of (0, a, b). The sponge built on top of it pads its
+// input and is specific to this package.
+//
+// See NewNativeHash for the reference implementation, which produces the same hashes
+// off-circuit.
+package poseidon
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Poseidon contains the params of the Poseidon hash func and the sponge state
+type Poseidon struct {
+	params *params                  // constants of the permutation
+	state  [width]frontend.Variable // sponge state, state[0] is the capacity
+	data   []frontend.Variable      // state storage. data is updated when Write() is called. Sum sums the data.
+	api    frontend.API             // underlying constraint system
+}
+
+// NewPoseidon returns a Poseidon instance, than can be used in a gnark circuit
+func NewPoseidon(api frontend.API) (Poseidon, error) {
+	p, err := newParams(api.Curve())
+	if err != nil {
+		return Poseidon{}, err
+	}
+	res := Poseidon{params: p, api: api}
+	res.Reset()
+	return res, nil
+}
+
+// Write adds more data to the running hash.
+func (h *Poseidon) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset resets the Hash to its initial state.
+func (h *Poseidon) Reset() {
+	h.data = nil
+	for i := 0; i < width; i++ {
+		h.state[i] = 0
+	}
+}
+
+// Sum absorbs the data written since the last call to Sum into the sponge
+// and returns the first element of its rate.
+func (h *Poseidon) Sum() frontend.Variable {
+
+	data := make([]frontend.Variable, 0, len(h.data)+rate)
+	data = append(data, h.data...)
+	data = append(data, 1)
+	for len(data)%rate != 0 {
+		data = append(data, 0)
+	}
+
+	for i := 0; i < len(data); i += rate {
+		for j := 0; j < rate; j++ {
+			h.state[1+j] = h.api.Add(h.state[1+j], data[i+j])
+		}
+		h.permutation()
+	}
+
+	h.data = nil // flush the data already hashed
+
+	return h.state[1]
+}
+
+// permutation applies the Poseidon permutation to the state
+func (h *Poseidon) permutation() {
+	for r := 0; r < len(h.params.constants); r++ {
+		for i := 0; i < width; i++ {
+			h.state[i] = h.api.Add(h.state[i], h.params.constants[r][i])
+		}
+		if isFullRound(r) {
+			for i := 0; i < width; i++ {
+				h.state[i] = h.sBox(h.state[i])
+			}
+		} else {
+			h.state[0] = h.sBox(h.state[0])
+		}
+		h.mix()
+	}
+}
+
+// sBox returns x**α, using a left to right square and multiply
+func (h *Poseidon) sBox(x frontend.Variable) frontend.Variable {
+	res := x
+	for i := bits.Len64(h.params.alpha) - 2; i >= 0; i-- {
+		res = h.api.Mul(res, res)
+		if (h.params.alpha>>i)&1 == 1 {
+			res = h.api.Mul(res, x)
+		}
+	}
+	return res
+}
+
+// mix multiplies the state by the MDS matrix
+func (h *Poseidon) mix() {
+	var res [width]frontend.Variable
+	for i := 0; i < width; i++ {
+		res[i] = h.api.Add(
+			h.api.Mul(h.state[0], h.params.mds[i][0]),
+			h.api.Mul(h.state[1], h.params.mds[i][1]),
+			h.api.Mul(h.state[2], h.params.mds[i][2]),
+		)
+	}
+	h.state = res
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidonCircuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           [3]frontend.Variable
+}
+
+func (circuit *poseidonCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidon(api)
+	if err != nil {
+		return err
+	}
+	poseidon.Write(circuit.Data[:]...)
+	result := poseidon.Sum()
+	api.AssertIsEqual(result, circuit.ExpectedResult)
+	return nil
+}
+
+func TestPoseidonAll(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// input
+	var data [3]big.Int
+	data[0].SetString("7808462342289447506325013279997289618334122576263655295146895675168642919487", 10)
+	data[1].SetUint64(42)
+	data[2].SetUint64(0)
+
+	curves := []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BLS12_377, ecc.BW6_761, ecc.BW6_633, ecc.BLS24_315}
+
+	for _, curve := range curves {
+
+		// minimal cs res = hash(data)
+		var circuit, witness, wrongWitness poseidonCircuit
+
+		// running Poseidon (Go)
+		goPoseidon, err := NewNativeHash(curve)
+		assert.NoError(err)
+		buf := make([]byte, goPoseidon.BlockSize())
+		for i := 0; i < len(data); i++ {
+			data[i].FillBytes(buf)
+			goPoseidon.Write(buf)
+		}
+		b := goPoseidon.Sum(nil)
+
+		// assert correctness against correct witness
+		for i := 0; i < len(data); i++ {
+			witness.Data[i] = data[i]
+			wrongWitness.Data[i] = data[i]
+		}
+		witness.ExpectedResult = b
+		assert.ProverSucceeded(&circuit, &witness, test.WithCurves(curve))
+
+		// assert failure against wrong witness
+		wrongWitness.Data[2] = 1
+		wrongWitness.ExpectedResult = b
+		assert.ProverFailed(&circuit, &wrongWitness, test.WithCurves(curve))
+	}
+
+}
+
+// permutationVectors are the images of (0, 1, 2) by the permutation, from the test
+// vectors of the reference implementation (poseidonperm_x5_254_3 and
+// poseidonperm_x5_255_3). On BN254, the first element is circomlib's Poseidon(1, 2).
+var permutationVectors = map[ecc.ID][width]string{
+	ecc.BN254: {
+		"0x115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a",
+		"0x0fca49b798923ab0239de1c9e7a4a9a2210312b6a2f616d18b5a87f9b628ae29",
+		"0x0e7ae82e40091e63cbd4f16a6d16310b3729d4b6e138fcf54110e2867045a30c",
+	},
+	ecc.BLS12_381: {
+		"0x28ce19420fc246a05553ad1e8c98f5c9d67166be2c18e9e4cb4b4e317dd2a78a",
+		"0x51f3e312c95343a896cfd8945ea82ba956c1118ce9b9859b6ea56637b4b1ddc4",
+		"0x3b2b69139b235626a0bfb56c9527ae66a7bf486ad8c11c14d1da0c69bbe0f79a",
+	},
+}
+
+type permutationCircuit struct {
+	Input  [width]frontend.Variable
+	Output [width]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *permutationCircuit) Define(api frontend.API) error {
+	poseidon, err := NewPoseidon(api)
+	if err != nil {
+		return err
+	}
+	poseidon.state = circuit.Input
+	poseidon.permutation()
+	for i := 0; i < width; i++ {
+		api.AssertIsEqual(poseidon.state[i], circuit.Output[i])
+	}
+	return nil
+}
+
+func TestPermutationVectors(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for curve, vector := range permutationVectors {
+		var expected [width]big.Int
+		for i := 0; i < width; i++ {
+			_, ok := expected[i].SetString(vector[i], 0)
+			assert.True(ok)
+		}
+
+		h, err := NewNativeHash(curve)
+		assert.NoError(err)
+		d := h.(*digest)
+		for i := 0; i < width; i++ {
+			d.state[i].SetUint64(uint64(i))
+		}
+		d.permutation()
+		for i := 0; i < width; i++ {
+			assert.Equal(0, d.state[i].Cmp(&expected[i]), "%s: state[%d] differs from the reference", curve, i)
+		}
+
+		var circuit, witness permutationCircuit
+		for i := 0; i < width; i++ {
+			witness.Input[i] = i
+			witness.Output[i] = expected[i]
+		}
+		assert.ProverSucceeded(&circuit, &witness, test.WithCurves(curve))
+	}
+}
@@ -0,0 +1,351 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sha2 provides a ZKP-circuit function to compute a SHA-256 digest.
+//
+// The gadget operates on bytes: each Variable written to the hash must be in [0, 256),
+// which is enforced when the digest is computed. Internally, 32-bit words are
+// decomposed in bits with api.ToBinary and the boolean functions of SHA-256 are
+// expressed with api.Xor, api.Select and api.Mul. Additions modulo 2**32 are done
+// on the packed words (api.FromBinary) and reduced by decomposing the result.
+//
+// See crypto/sha256 for the reference implementation.
+package sha2
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+const (
+	// Size is the size of a SHA-256 digest in bytes
+	Size = 32
+
+	// BlockSize is the block size of SHA-256 in bytes
+	BlockSize = 64
+)
+
+var iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var k = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+// word is a 32-bit word, as little-endian bits
+type word [32]frontend.Variable
+
+// SHA256 computes a SHA-256 digest in a gnark circuit
+type SHA256 struct {
+	data []frontend.Variable // bytes written so far
+	api  frontend.API        // underlying constraint system
+}
+
+// New returns a SHA256 instance, than can be used in a gnark circuit
+func New(api frontend.API) SHA256 {
+	return SHA256{api: api}
+}
+
+// Write adds bytes to the running hash.
+func (h *SHA256) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset resets the Hash to its initial state.
+func (h *SHA256) Reset() {
+	h.data = nil
+}
+
+// Sum returns the SHA-256 digest of the written bytes, as Size bytes.
+//
+// The padding is computed at compile time.
+func (h *SHA256) Sum() []frontend.Variable {
+	return h.sum(len(h.data))
+}
+
+// SumWithLength returns the SHA-256 digest of the first length bytes written, as Size bytes.
+//
+// The written bytes act as a buffer whose size is the compile-time maximum of the
+// message length, the bytes after length are ignored. The padding is computed in
+// the circuit and the circuit is not satisfied if length > number of bytes written.
+func (h *SHA256) SumWithLength(length frontend.Variable) []frontend.Variable {
+	api := h.api
+
+	if api.IsConstant(length) {
+		l := api.ConstantValue(length)
+		if !l.IsUint64() || l.Uint64() > uint64(len(h.data)) {
+			panic("length is larger than the number of bytes written")
+		}
+		return h.sum(int(l.Uint64()))
+	}
+
+	maxLen := len(h.data)
+	nbBlocks := (maxLen+8)/BlockSize + 1
+
+	// isLen[i] == 1 iff length == i, exactly one of them is set if length <= maxLen
+	isLen := make([]frontend.Variable, maxLen+1)
+	for i := 0; i <= maxLen; i++ {
+		isLen[i] = api.IsZero(api.Sub(length, i))
+	}
+	api.AssertIsEqual(sum(api, isLen), 1)
+
+	// isLastBlock[b] == 1 iff the padded message ends with block b
+	isLastBlock := make([]frontend.Variable, nbBlocks)
+	for b := 0; b < nbBlocks; b++ {
+		var in []frontend.Variable
+		for i := 0; i <= maxLen; i++ {
+			if (i+8)/BlockSize == b {
+				in = append(in, isLen[i])
+			}
+		}
+		isLastBlock[b] = sum(api, in)
+	}
+
+	// the message length in bits, as 8 big-endian bytes
+	lengthBits := api.ToBinary(api.Mul(length, 8), 64)
+	var lengthBytes [8]frontend.Variable
+	for i := 0; i < 8; i++ {
+		lengthBytes[i] = api.FromBinary(lengthBits[8*(7-i) : 8*(8-i)]...)
+	}
+
+	// padded[i] = data[i] if i < length, 0x80 if i == length, 0 otherwise,
+	// except for the last 8 bytes of the last block, which store the length
+	padded := make([]frontend.Variable, nbBlocks*BlockSize)
+	var isBefore frontend.Variable = 1
+	for i := 0; i < len(padded); i++ {
+		var in []frontend.Variable
+		if i <= maxLen {
+			isBefore = api.Sub(isBefore, isLen[i])
+			in = append(in, api.Mul(isLen[i], 0x80))
+		}
+		if i < maxLen {
+			in = append(in, api.Mul(isBefore, h.data[i]))
+		}
+		if j := i % BlockSize; j >= BlockSize-8 {
+			in = append(in, api.Mul(isLastBlock[i/BlockSize], lengthBytes[j-(BlockSize-8)]))
+		}
+		padded[i] = sum(api, in)
+	}
+
+	// the digest is the state after the last block
+	state := h.initialState()
+	digest := make([]frontend.Variable, 8)
+	for i := range digest {
+		digest[i] = 0
+	}
+	for b := 0; b < nbBlocks; b++ {
+		state = h.compress(state, padded[b*BlockSize:(b+1)*BlockSize])
+		for i := 0; i < 8; i++ {
+			digest[i] = api.Add(digest[i], api.Mul(isLastBlock[b], api.FromBinary(state[i][:]...)))
+		}
+	}
+
+	for i := 0; i < 8; i++ {
+		copy(state[i][:], api.ToBinary(digest[i], 32))
+	}
+
+	return h.toBytes(state)
+}
+
+// sum computes the digest of the first length bytes of data, with the padding
+// known at compile time.
+func (h *SHA256) sum(length int) []frontend.Variable {
+	nbBlocks := (length+8)/BlockSize + 1
+
+	padded := make([]frontend.Variable, nbBlocks*BlockSize)
+	copy(padded, h.data[:length])
+	padded[length] = 0x80
+	for i := length + 1; i < len(padded); i++ {
+		padded[i] = 0
+	}
+	bitLength := uint64(length) * 8
+	for i := 0; i < 8; i++ {
+		padded[len(padded)-1-i] = (bitLength >> (8 * i)) & 0xff
+	}
+
+	state := h.initialState()
+	for b := 0; b < nbBlocks; b++ {
+		state = h.compress(state, padded[b*BlockSize:(b+1)*BlockSize])
+	}
+
+	return h.toBytes(state)
+}
+
+func (h *SHA256) initialState() [8]word {
+	var state [8]word
+	for i := 0; i < 8; i++ {
+		state[i] = constantWord(iv[i])
+	}
+	return state
+}
+
+// compress applies the SHA-256 compression function to the state and a block of BlockSize bytes
+func (h *SHA256) compress(state [8]word, block []frontend.Variable) [8]word {
+	api := h.api
+
+	// message schedule
+	var w [64]word
+	for i := 0; i < 16; i++ {
+		for j := 0; j < 4; j++ {
+			// words are read in big-endian
+			copy(w[i][8*(3-j):8*(4-j)], api.ToBinary(block[4*i+j], 8))
+		}
+	}
+	for i := 16; i < 64; i++ {
+		s0 := h.xor(rotr(w[i-15], 7), rotr(w[i-15], 18), shr(w[i-15], 3))
+		s1 := h.xor(rotr(w[i-2], 17), rotr(w[i-2], 19), shr(w[i-2], 10))
+		w[i] = h.reduce(api.Add(h.pack(w[i-16]), h.pack(s0), h.pack(w[i-7]), h.pack(s1)), 4)
+	}
+
+	a, b, c, d, e, f, g, hh := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
+	for i := 0; i < 64; i++ {
+		s1 := h.xor(rotr(e, 6), rotr(e, 11), rotr(e, 25))
+		t1 := api.Add(h.pack(hh), h.pack(s1), h.pack(h.ch(e, f, g)), k[i], h.pack(w[i]))
+		s0 := h.xor(rotr(a, 2), rotr(a, 13), rotr(a, 22))
+		t2 := api.Add(h.pack(s0), h.pack(h.maj(a, b, c)))
+
+		hh = g
+		g = f
+		f = e
+		e = h.reduce(api.Add(h.pack(d), t1), 6)
+		d = c
+		c = b
+		b = a
+		a = h.reduce(api.Add(t1, t2), 7)
+	}
+
+	var res [8]word
+	for i, v := range []word{a, b, c, d, e, f, g, hh} {
+		res[i] = h.reduce(api.Add(h.pack(state[i]), h.pack(v)), 2)
+	}
+	return res
+}
+
+// toBytes returns the big-endian byte representation of the state
+func (h *SHA256) toBytes(state [8]word) []frontend.Variable {
+	res := make([]frontend.Variable, Size)
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 4; j++ {
+			res[4*i+j] = h.api.FromBinary(state[i][8*(3-j) : 8*(4-j)]...)
+		}
+	}
+	return res
+}
+
+// pack returns the word as a field element
+func (h *SHA256) pack(w word) frontend.Variable {
+	return h.api.FromBinary(w[:]...)
+}
+
+// reduce returns v mod 2**32, where v is the sum of at most nbWords words
+func (h *SHA256) reduce(v frontend.Variable, nbWords int) word {
+	var res word
+	copy(res[:], h.api.ToBinary(v, 32+bits.Len(uint(nbWords-1))))
+	return res
+}
+
+// xor returns a ^ b ^ c
+func (h *SHA256) xor(a, b, c word) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		res[i] = xorBit(h.api, xorBit(h.api, a[i], b[i]), c[i])
+	}
+	return res
+}
+
+// ch returns (e & f) ^ (^e & g)
+func (h *SHA256) ch(e, f, g word) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		res[i] = h.api.Select(e[i], f[i], g[i])
+	}
+	return res
+}
+
+// maj returns (a & b) ^ (a & c) ^ (b & c)
+func (h *SHA256) maj(a, b, c word) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		// maj = b*c + a*(b + c - 2*b*c)
+		bc := h.api.Mul(b[i], c[i])
+		t := h.api.Sub(h.api.Add(b[i], c[i]), h.api.Mul(bc, 2))
+		res[i] = h.api.Add(bc, h.api.Mul(a[i], t))
+	}
+	return res
+}
+
+// xorBit returns a ^ b, without adding constraints if one of the bits is a constant
+func xorBit(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if api.IsConstant(a) {
+		a, b = b, a
+	}
+	if api.IsConstant(b) {
+		if api.ConstantValue(b).Sign() == 0 {
+			return a
+		}
+		return api.Sub(1, a)
+	}
+	return api.Xor(a, b)
+}
+
+func rotr(w word, n int) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		res[i] = w[(i+n)%32]
+	}
+	return res
+}
+
+func shr(w word, n int) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		if i+n < 32 {
+			res[i] = w[i+n]
+		} else {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+func constantWord(v uint32) word {
+	var res word
+	for i := 0; i < 32; i++ {
+		res[i] = (v >> i) & 1
+	}
+	return res
+}
+
+// sum returns the sum of in, which may have less than 2 elements
+func sum(api frontend.API, in []frontend.Variable) frontend.Variable {
+	switch len(in) {
+	case 0:
+		return 0
+	case 1:
+		return in[0]
+	default:
+		return api.Add(in[0], in[1], in[2:]...)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sha2
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type sha256Circuit struct {
+	ExpectedResult [Size]frontend.Variable `gnark:"data,public"`
+	Data           [3]frontend.Variable
+}
+
+func (circuit *sha256Circuit) Define(api frontend.API) error {
+	h := New(api)
+	h.Write(circuit.Data[:]...)
+	result := h.Sum()
+	for i := 0; i < Size; i++ {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestSHA256(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	data := []byte("abc")
+	digest := sha256.Sum256(data)
+
+	var circuit, witness, wrongWitness sha256Circuit
+	for i := 0; i < len(data); i++ {
+		witness.Data[i] = data[i]
+		wrongWitness.Data[i] = data[i]
+	}
+	for i := 0; i < Size; i++ {
+		witness.ExpectedResult[i] = digest[i]
+		wrongWitness.ExpectedResult[i] = digest[i]
+	}
+	wrongWitness.Data[2] = 'd'
+
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+	assert.ProverFailed(&circuit, &wrongWitness, test.WithCurves(ecc.BN254))
+}
+
+type sha256VariableLengthCircuit struct {
+	ExpectedResult [Size]frontend.Variable `gnark:"data,public"`
+	Data           [70]frontend.Variable
+	Length         frontend.Variable
+}
+
+func (circuit *sha256VariableLengthCircuit) Define(api frontend.API) error {
+	h := New(api)
+	h.Write(circuit.Data[:]...)
+	result := h.SumWithLength(circuit.Length)
+	for i := 0; i < Size; i++ {
+		api.AssertIsEqual(result[i], circuit.ExpectedResult[i])
+	}
+	return nil
+}
+
+func TestSHA256VariableLength(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var buf [70]byte
+	for i := 0; i < len(buf); i++ {
+		buf[i] = byte(3*i + 1)
+	}
+
+	var circuit sha256VariableLengthCircuit
+
+	// lengths around the block boundaries
+	for _, length := range []int{0, 3, 55, 56, 64, 70} {
+		digest := sha256.Sum256(buf[:length])
+
+		var witness sha256VariableLengthCircuit
+		for i := 0; i < len(buf); i++ {
+			witness.Data[i] = buf[i]
+		}
+		witness.Length = length
+		for i := 0; i < Size; i++ {
+			witness.ExpectedResult[i] = digest[i]
+		}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		// the bytes after length are not hashed
+		if length < len(buf) {
+			witness.Data[len(buf)-1] = 0
+			assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+		}
+
+		// but the length is
+		if length != 0 {
+			witness.Length = length - 1
+			assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+		}
+	}
+
+	// length can't exceed the buffer size
+	var witness sha256VariableLengthCircuit
+	for i := 0; i < len(buf); i++ {
+		witness.Data[i] = 0
+	}
+	for i := 0; i < Size; i++ {
+		witness.ExpectedResult[i] = 0
+	}
+	witness.Length = len(buf) + 1
+	assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+}
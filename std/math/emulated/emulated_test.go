@@ -0,0 +1,199 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulated
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func randomElement(t *testing.T, p *Params) *big.Int {
+	v, err := rand.Int(rand.Reader, p.Modulus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+type arithmeticCircuit struct {
+	A, B, C Element
+	params  *Params
+}
+
+// Define checks (a+b)·a - b = c, with enough additions to trigger the lazy reductions
+func (circuit *arithmeticCircuit) Define(api frontend.API) error {
+	f, err := NewField(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	sum := f.Add(&circuit.A, &circuit.B)
+	res := f.Sub(f.Mul(sum, &circuit.A), &circuit.B)
+
+	acc := f.Zero()
+	for i := 0; i < 200; i++ {
+		acc = f.Add(acc, &circuit.A)
+		acc = f.Sub(acc, &circuit.A)
+	}
+	f.AssertIsEqual(f.Add(res, acc), &circuit.C)
+	return nil
+}
+
+func TestArithmetic(t *testing.T) {
+	for name, params := range map[string]*Params{"secp256k1": Secp256k1Fp(), "bn254": BN254Fp()} {
+		params := params
+		// the compiled circuits are cached by type, so each modulus needs its own Assert
+		t.Run(name, func(t *testing.T) {
+			assert := test.NewAssert(t)
+			p := params.Modulus()
+			a, b := randomElement(t, params), randomElement(t, params)
+			var c big.Int
+			c.Add(a, b).Mul(&c, a).Sub(&c, b).Mod(&c, p)
+
+			circuit := arithmeticCircuit{A: params.Placeholder(), B: params.Placeholder(), C: params.Placeholder(), params: params}
+			witness := arithmeticCircuit{A: params.ValueOf(a), B: params.ValueOf(b), C: params.ValueOf(&c)}
+			assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+
+			c.Add(&c, big.NewInt(1))
+			witness.C = params.ValueOf(&c)
+			assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+		})
+	}
+}
+
+type inverseCircuit struct {
+	A, B   Element
+	params *Params
+}
+
+func (circuit *inverseCircuit) Define(api frontend.API) error {
+	f, err := NewField(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	f.AssertIsEqual(f.Inverse(&circuit.A), &circuit.B)
+	f.AssertIsEqual(f.Div(f.One(), &circuit.B), &circuit.A)
+	return nil
+}
+
+func TestInverse(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := Secp256k1Fr()
+	a := randomElement(t, params)
+	var b big.Int
+	b.ModInverse(a, params.Modulus())
+
+	circuit := inverseCircuit{A: params.Placeholder(), B: params.Placeholder(), params: params}
+	witness := inverseCircuit{A: params.ValueOf(a), B: params.ValueOf(&b)}
+	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+	witness.B = params.ValueOf(a)
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
+
+type toBitsCircuit struct {
+	A      Element
+	Bits   []frontend.Variable
+	params *Params
+}
+
+func (circuit *toBitsCircuit) Define(api frontend.API) error {
+	f, err := NewField(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	bits := f.ToBits(f.Add(&circuit.A, f.Zero()))
+	for i := range bits {
+		api.AssertIsEqual(bits[i], circuit.Bits[i])
+	}
+	f.AssertIsEqual(f.FromBits(bits), &circuit.A)
+	return nil
+}
+
+func TestToBits(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := Secp256k1Fp()
+	nbBits := params.Modulus().BitLen()
+	placeholder := toBitsCircuit{A: params.Placeholder(), Bits: make([]frontend.Variable, nbBits), params: params}
+
+	for _, a := range []*big.Int{randomElement(t, params), big.NewInt(0), new(big.Int).Sub(params.Modulus(), big.NewInt(1))} {
+		witness := toBitsCircuit{A: params.ValueOf(a), Bits: make([]frontend.Variable, nbBits)}
+		for i := 0; i < nbBits; i++ {
+			witness.Bits[i] = a.Bit(i)
+		}
+		assert.ProverSucceeded(&placeholder, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	}
+
+	// p has the same residue as 0, but its bits aren't reduced
+	p := params.Modulus()
+	witness := toBitsCircuit{A: params.ValueOf(0), Bits: make([]frontend.Variable, nbBits)}
+	for i := 0; i < nbBits; i++ {
+		witness.Bits[i] = p.Bit(i)
+	}
+	assert.ProverFailed(&placeholder, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
+
+type rangeCheckCircuit struct {
+	A, B   Element
+	params *Params
+}
+
+func (circuit *rangeCheckCircuit) Define(api frontend.API) error {
+	f, err := NewField(api, circuit.params)
+	if err != nil {
+		return err
+	}
+	f.AssertIsEqual(&circuit.A, &circuit.B)
+	return nil
+}
+
+func TestLimbsRangeCheck(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := Secp256k1Fp()
+	circuit := rangeCheckCircuit{A: params.Placeholder(), B: params.Placeholder(), params: params}
+
+	// A and B both represent 2^84, but the first limb of A doesn't fit in 64 bits
+	witness := rangeCheckCircuit{A: params.ValueOf(0), B: params.ValueOf(0)}
+	witness.A.Limbs[0] = new(big.Int).Lsh(big.NewInt(1), 84)
+	witness.B.Limbs[1] = big.NewInt(1 << 20)
+	assert.ProverFailed(&circuit, &witness, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
+
+func TestDecompose(t *testing.T) {
+	params := Secp256k1Fp()
+	p := params.Modulus()
+	x := new(big.Int).Lsh(p, 70)
+	x.Add(x, big.NewInt(12345))
+
+	limbs := make([]*big.Int, 6)
+	if err := decompose(x, 64, limbs); err != nil {
+		t.Fatal(err)
+	}
+	var y big.Int
+	recompose(limbs, 64, &y)
+	if y.Cmp(x) != 0 {
+		t.Fatal("recompose(decompose(x)) != x")
+	}
+	if err := decompose(x, 64, make([]*big.Int, 5)); err == nil {
+		t.Fatal("decompose should fail when the value doesn't fit")
+	}
+}
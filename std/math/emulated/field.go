@@ -0,0 +1,541 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package emulated implements the arithmetic of a field of arbitrary modulus
+// (the emulated field) inside a circuit defined over a different field (the
+// native field).
+//
+// An element is represented by limbs of NbBits bits (little endian), each
+// limb being a frontend.Variable. Additions and subtractions are performed
+// limb-wise, without reduction: the limbs grow and their overflow (the number
+// of bits exceeding NbBits) is tracked, until it would make the native
+// computations wrap around, in which case the element is reduced first.
+//
+// Reductions and multiplications are checked with a quotient and a remainder
+// computed by a hint: the equality x = q·p + r is asserted over the integers
+// by propagating the carries between the limbs.
+package emulated
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Element is an element of the emulated field. Its value is Σ Limbs[i]·2^(NbBits·i),
+// which isn't necessarily reduced.
+//
+// Elements declared in a circuit are range checked when first used by a Field;
+// the elements returned by a Field are not.
+type Element struct {
+	Limbs []frontend.Variable
+
+	// overflow is the number of bits by which the limbs may exceed NbBits
+	overflow uint
+
+	// internal is set if the limbs are known to fit in NbBits+overflow bits
+	internal bool
+}
+
+// Field performs the operations of the emulated field in a circuit
+type Field struct {
+	api    frontend.API
+	params *Params
+
+	// maxOverflow is the largest overflow of an element such that the product
+	// of two elements can be checked without wrapping around the native modulus
+	maxOverflow uint
+
+	// checked records the elements of the circuit whose limbs are range checked
+	checked map[*Element]struct{}
+}
+
+// NewField returns a Field emulating params in the circuit of api
+func NewField(api frontend.API, params *Params) (*Field, error) {
+	nativeBits := uint(api.Curve().Info().Fr.Modulus().BitLen())
+	// the coefficients of a product are bounded by nbLimbs·2^(2·nbBits+overflow),
+	// and the carry check needs them below 2^(nativeBits-3)
+	used := 3 + 2*params.nbBits + uint(bits.Len(params.nbLimbs))
+	if nativeBits < used+2 {
+		return nil, errors.New("limbs are too large for the native field")
+	}
+	return &Field{
+		api:         api,
+		params:      params,
+		maxOverflow: nativeBits - used,
+		checked:     make(map[*Element]struct{}),
+	}, nil
+}
+
+// Params returns the parameters of the emulated field
+func (f *Field) Params() *Params {
+	return f.params
+}
+
+// Constant returns the element of value v, which must be convertible to *big.Int
+func (f *Field) Constant(v interface{}) *Element {
+	limbs := f.params.limbs(v)
+	e := &Element{Limbs: make([]frontend.Variable, len(limbs)), internal: true}
+	for i := range limbs {
+		e.Limbs[i] = limbs[i]
+	}
+	return e
+}
+
+// Zero returns the element 0
+func (f *Field) Zero() *Element {
+	return f.Constant(0)
+}
+
+// One returns the element 1
+func (f *Field) One() *Element {
+	return f.Constant(1)
+}
+
+// Add returns a+b
+func (f *Field) Add(a, b *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	if max(a.overflow, b.overflow)+1 > f.maxOverflow {
+		if a.overflow > b.overflow {
+			a = f.Reduce(a)
+		} else {
+			b = f.Reduce(b)
+		}
+	}
+	res := &Element{
+		Limbs:    make([]frontend.Variable, f.params.nbLimbs),
+		overflow: max(a.overflow, b.overflow) + 1,
+		internal: true,
+	}
+	for i := range res.Limbs {
+		res.Limbs[i] = f.api.Add(a.Limbs[i], b.Limbs[i])
+	}
+	return res
+}
+
+// Sub returns a-b
+func (f *Field) Sub(a, b *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	if max(a.overflow, b.overflow)+2 > f.maxOverflow {
+		if a.overflow > b.overflow {
+			a = f.Reduce(a)
+		} else {
+			b = f.Reduce(b)
+		}
+		if max(a.overflow, b.overflow)+2 > f.maxOverflow {
+			a, b = f.Reduce(a), f.Reduce(b)
+		}
+	}
+	// we compute a + pad - b, where pad is a multiple of p whose limbs are larger than those of b
+	pad := f.subPadding(b.overflow)
+	res := &Element{
+		Limbs:    make([]frontend.Variable, f.params.nbLimbs),
+		overflow: max(a.overflow, b.overflow) + 2,
+		internal: true,
+	}
+	for i := range res.Limbs {
+		res.Limbs[i] = f.api.Sub(f.api.Add(a.Limbs[i], pad[i]), b.Limbs[i])
+	}
+	return res
+}
+
+// Neg returns -a
+func (f *Field) Neg(a *Element) *Element {
+	return f.Sub(f.Zero(), a)
+}
+
+// Mul returns a·b, reduced
+func (f *Field) Mul(a, b *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	if a.overflow+b.overflow > f.maxOverflow {
+		if a.overflow > b.overflow {
+			a = f.Reduce(a)
+		} else {
+			b = f.Reduce(b)
+		}
+	}
+
+	w, n := f.params.nbBits, f.params.nbLimbs
+	prod := f.mulLimbs(a.Limbs, b.Limbs)
+
+	// a < 2^(w·n+overflow+1), same for b
+	valueBits := 2*w*n + a.overflow + b.overflow + 2
+	coefBits := 2*w + a.overflow + b.overflow + uint(bits.Len(n))
+	_, r := f.divMod(prod, valueBits, coefBits, true)
+	return r
+}
+
+// Reduce returns an element equal to a modulo p, with no overflow. The result
+// isn't necessarily smaller than p.
+func (f *Field) Reduce(a *Element) *Element {
+	f.enforceWidth(a)
+	if a.overflow == 0 {
+		return a
+	}
+	return f.reduce(a)
+}
+
+// Inverse returns 1/a. a must be invertible.
+func (f *Field) Inverse(a *Element) *Element {
+	f.enforceWidth(a)
+	w, n := f.params.nbBits, f.params.nbLimbs
+	inputs := []frontend.Variable{w, n}
+	inputs = append(inputs, f.modulusLimbs()...)
+	inputs = append(inputs, a.Limbs...)
+	inv := f.newElement(f.hint(InverseHint, n, inputs))
+	f.assertMulIsEqual(a, inv, f.One())
+	return inv
+}
+
+// Div returns a/b. b must be invertible.
+func (f *Field) Div(a, b *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	w, n := f.params.nbBits, f.params.nbLimbs
+	inputs := []frontend.Variable{w, n}
+	inputs = append(inputs, f.modulusLimbs()...)
+	inputs = append(inputs, len(a.Limbs))
+	inputs = append(inputs, a.Limbs...)
+	inputs = append(inputs, b.Limbs...)
+	div := f.newElement(f.hint(DivHint, n, inputs))
+	f.assertMulIsEqual(div, b, a)
+	return div
+}
+
+// Select returns a if b is true, c otherwise. b must be 0 or 1.
+func (f *Field) Select(b frontend.Variable, a, c *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(c)
+	res := &Element{
+		Limbs:    make([]frontend.Variable, f.params.nbLimbs),
+		overflow: max(a.overflow, c.overflow),
+		internal: true,
+	}
+	for i := range res.Limbs {
+		res.Limbs[i] = f.api.Select(b, a.Limbs[i], c.Limbs[i])
+	}
+	return res
+}
+
+// Lookup2 returns a if b0=b1=0, b if b0=1 and b1=0, c if b0=0 and b1=1, d if b0=b1=1.
+// b0 and b1 must be 0 or 1.
+func (f *Field) Lookup2(b0, b1 frontend.Variable, a, b, c, d *Element) *Element {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	f.enforceWidth(c)
+	f.enforceWidth(d)
+	res := &Element{
+		Limbs:    make([]frontend.Variable, f.params.nbLimbs),
+		overflow: max(max(a.overflow, b.overflow), max(c.overflow, d.overflow)),
+		internal: true,
+	}
+	for i := range res.Limbs {
+		res.Limbs[i] = f.api.Lookup2(b0, b1, a.Limbs[i], b.Limbs[i], c.Limbs[i], d.Limbs[i])
+	}
+	return res
+}
+
+// AssertIsEqual fails if a and b are not equal modulo p
+func (f *Field) AssertIsEqual(a, b *Element) {
+	diff := f.Sub(a, b)
+	// diff = a - b + k·p is non negative, and must be a multiple of p
+	w, n := f.params.nbBits, f.params.nbLimbs
+	valueBits := w*n + diff.overflow + 1
+	coefBits := w + diff.overflow
+	f.divMod(diff.Limbs, valueBits, coefBits, false)
+}
+
+// assertMulIsEqual asserts a·b = c modulo p. It is cheaper than AssertIsEqual(Mul(a, b), c)
+// as it checks a single division by p.
+func (f *Field) assertMulIsEqual(a, b, c *Element) {
+	f.enforceWidth(a)
+	f.enforceWidth(b)
+	f.enforceWidth(c)
+	// one more bit than Mul, for the addition of the padding
+	if a.overflow+b.overflow+1 > f.maxOverflow {
+		if a.overflow > b.overflow {
+			a = f.Reduce(a)
+		} else {
+			b = f.Reduce(b)
+		}
+	}
+
+	// a·b + pad - c is non negative, and must be a multiple of p
+	w, n := f.params.nbBits, f.params.nbLimbs
+	x := f.mulLimbs(a.Limbs, b.Limbs)
+	pad := f.subPadding(c.overflow)
+	for i := range c.Limbs {
+		x[i] = f.api.Sub(f.api.Add(x[i], pad[i]), c.Limbs[i])
+	}
+	valueBits := max(2*w*n+a.overflow+b.overflow+2, w*n+c.overflow+2) + 1
+	coefBits := max(2*w+a.overflow+b.overflow+uint(bits.Len(n)), w+c.overflow+1) + 1
+	f.divMod(x, valueBits, coefBits, false)
+}
+
+// ToBits returns the bits of the reduced value of a (little endian), on as many
+// bits as the modulus
+func (f *Field) ToBits(a *Element) []frontend.Variable {
+	f.enforceWidth(a)
+	r := f.reduce(a)
+	w := int(f.params.nbBits)
+	res := make([]frontend.Variable, 0, len(r.Limbs)*w)
+	for i := range r.Limbs {
+		res = append(res, f.api.ToBinary(r.Limbs[i], w)...)
+	}
+	// the remainder fits in the limbs but must also be smaller than p
+	var bound big.Int
+	bound.Sub(f.params.modulus, big.NewInt(1))
+	f.assertBitsLessOrEqual(res, &bound)
+	return res[:f.params.modulus.BitLen()]
+}
+
+// FromBits returns the element of value Σ b[i]·2^i. The bits must be boolean, and
+// there must be at most NbLimbs·NbBits of them.
+func (f *Field) FromBits(b []frontend.Variable) *Element {
+	w, n := int(f.params.nbBits), int(f.params.nbLimbs)
+	if len(b) > w*n {
+		panic("emulated: too many bits")
+	}
+	res := &Element{Limbs: make([]frontend.Variable, n), internal: true}
+	for i := range res.Limbs {
+		switch {
+		case w*i >= len(b):
+			res.Limbs[i] = 0
+		case w*(i+1) >= len(b):
+			res.Limbs[i] = f.api.FromBinary(b[w*i:]...)
+		default:
+			res.Limbs[i] = f.api.FromBinary(b[w*i : w*(i+1)]...)
+		}
+	}
+	return res
+}
+
+// enforceWidth range checks the limbs of a, if a wasn't computed by the Field
+func (f *Field) enforceWidth(a *Element) {
+	if len(a.Limbs) != int(f.params.nbLimbs) {
+		panic("emulated: element has the wrong number of limbs")
+	}
+	if a.internal {
+		return
+	}
+	if _, ok := f.checked[a]; ok {
+		return
+	}
+	for i := range a.Limbs {
+		f.api.RangeCheck(a.Limbs[i], int(f.params.nbBits))
+	}
+	f.checked[a] = struct{}{}
+}
+
+// newElement returns an element of the given limbs, which are range checked.
+// It is also used for the quotients, which may have more limbs than an element.
+func (f *Field) newElement(limbs []frontend.Variable) *Element {
+	for i := range limbs {
+		f.api.RangeCheck(limbs[i], int(f.params.nbBits))
+	}
+	return &Element{Limbs: limbs, internal: true}
+}
+
+// reduce returns the remainder of the division of a by p
+func (f *Field) reduce(a *Element) *Element {
+	w, n := f.params.nbBits, f.params.nbLimbs
+	valueBits := w*n + a.overflow + 1
+	coefBits := w + a.overflow
+	_, r := f.divMod(a.Limbs, valueBits, coefBits, true)
+	return r
+}
+
+// divMod computes the quotient q and the remainder r of the division by p of the
+// value x = Σ x[i]·2^(nbBits·i), and asserts x = q·p + r (x = q·p if withRemainder is false).
+//
+// valueBits bounds the bit length of x, and coefBits the bit length of its coefficients.
+func (f *Field) divMod(x []frontend.Variable, valueBits, coefBits uint, withRemainder bool) (q, r *Element) {
+	w, n := f.params.nbBits, f.params.nbLimbs
+	nbQ := uint(1)
+	if pBits := uint(f.params.modulus.BitLen()); valueBits+1 > pBits {
+		nbQ = (valueBits + 1 - pBits + w - 1) / w
+	}
+	nbR := n
+	if !withRemainder {
+		nbR = 0
+	}
+
+	inputs := []frontend.Variable{w, nbQ, nbR, n}
+	inputs = append(inputs, f.modulusLimbs()...)
+	inputs = append(inputs, x...)
+	res := f.hint(DivModHint, nbQ+nbR, inputs)
+
+	q = f.newElement(res[:nbQ])
+	right := f.mulLimbs(q.Limbs, f.modulusLimbs())
+	if withRemainder {
+		r = f.newElement(res[nbQ : nbQ+nbR])
+		for i := range r.Limbs {
+			right[i] = f.api.Add(right[i], r.Limbs[i])
+		}
+	}
+
+	// the coefficients of q·p + r are bounded by min(nbQ, n)·2^(2w) + 2^w
+	rightBits := 2*w + uint(bits.Len(min(nbQ, n))) + 1
+	f.assertLimbsEqual(x, right, max(coefBits, rightBits))
+	return q, r
+}
+
+// mulLimbs returns the coefficients of the product of the polynomials of coefficients a and b
+func (f *Field) mulLimbs(a, b []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(a)+len(b)-1)
+	for i := range res {
+		res[i] = 0
+	}
+	for i := range a {
+		for j := range b {
+			res[i+j] = f.api.Add(res[i+j], f.api.Mul(a[i], b[j]))
+		}
+	}
+	return res
+}
+
+// assertLimbsEqual asserts Σ left[i]·2^(nbBits·i) = Σ right[i]·2^(nbBits·i) over the integers,
+// where all the coefficients are non-negative and smaller than 2^maxBits.
+//
+// The carries c_k satisfy left_k - right_k + c_(k-1) = c_k·2^nbBits and |c_k| < 2^(maxBits-nbBits+1);
+// they are given by a hint, shifted to be positive and range checked.
+func (f *Field) assertLimbsEqual(left, right []frontend.Variable, maxBits uint) {
+	w := f.params.nbBits
+	nbLimbs := len(left)
+	if len(right) > nbLimbs {
+		nbLimbs = len(right)
+	}
+	offsetBits := maxBits - w + 1
+	offset := new(big.Int).Lsh(big.NewInt(1), offsetBits)
+	base := new(big.Int).Lsh(big.NewInt(1), w)
+
+	inputs := []frontend.Variable{w, offsetBits, len(left)}
+	inputs = append(inputs, left...)
+	inputs = append(inputs, right...)
+	carries := f.hint(CarryHint, uint(nbLimbs-1), inputs)
+
+	var carry frontend.Variable = 0
+	for k := 0; k < nbLimbs; k++ {
+		var d frontend.Variable = carry
+		if k < len(left) {
+			d = f.api.Add(d, left[k])
+		}
+		if k < len(right) {
+			d = f.api.Sub(d, right[k])
+		}
+		if k == nbLimbs-1 {
+			f.api.AssertIsEqual(d, 0)
+			break
+		}
+		f.api.RangeCheck(carries[k], int(offsetBits)+1)
+		carry = f.api.Sub(carries[k], offset)
+		f.api.AssertIsEqual(d, f.api.Mul(carry, base))
+	}
+}
+
+// hint returns the first nbOutputs outputs of the hint fn called on inputs.
+//
+// The hints of the package have as many outputs as inputs: the inputs are padded
+// with zeros if needed (the hints ignore the extra high limbs), and the unused
+// outputs, which are 0, are constrained by a single linear constraint.
+func (f *Field) hint(fn hint.Function, nbOutputs uint, inputs []frontend.Variable) []frontend.Variable {
+	for uint(len(inputs)) < nbOutputs {
+		inputs = append(inputs, 0)
+	}
+	res, err := f.api.NewHint(fn, inputs...)
+	if err != nil {
+		panic(err)
+	}
+	if unused := res[nbOutputs:]; len(unused) == 1 {
+		f.api.AssertIsEqual(unused[0], 0)
+	} else if len(unused) > 1 {
+		f.api.AssertIsEqual(f.api.Add(unused[0], unused[1], unused[2:]...), 0)
+	}
+	return res[:nbOutputs]
+}
+
+// modulusLimbs returns the limbs of p as constants
+func (f *Field) modulusLimbs() []frontend.Variable {
+	limbs := make([]*big.Int, f.params.nbLimbs)
+	decompose(f.params.modulus, f.params.nbBits, limbs)
+	res := make([]frontend.Variable, len(limbs))
+	for i := range limbs {
+		res[i] = limbs[i]
+	}
+	return res
+}
+
+// subPadding returns the limbs of a multiple of p such that each limb is at
+// least 2^(nbBits+overflow)
+func (f *Field) subPadding(overflow uint) []frontend.Variable {
+	w, n := f.params.nbBits, f.params.nbLimbs
+	limb := new(big.Int).Lsh(big.NewInt(1), w+overflow)
+
+	// pad = Σ limb·2^(w·i) + (p - (Σ limb·2^(w·i) mod p))
+	var sum big.Int
+	for i := uint(0); i < n; i++ {
+		sum.Add(&sum, new(big.Int).Lsh(limb, w*i))
+	}
+	sum.Mod(&sum, f.params.modulus)
+	sum.Sub(f.params.modulus, &sum)
+	corr := make([]*big.Int, n)
+	decompose(&sum, w, corr)
+
+	res := make([]frontend.Variable, n)
+	for i := range res {
+		res[i] = new(big.Int).Add(limb, corr[i])
+	}
+	return res
+}
+
+// assertBitsLessOrEqual asserts the value of the bits (little endian) is at most bound
+func (f *Field) assertBitsLessOrEqual(b []frontend.Variable, bound *big.Int) {
+	nbBits := len(b)
+
+	// p[i] == 1 --> b[j] == bound[j] for all j >= i
+	p := make([]frontend.Variable, nbBits+1)
+	p[nbBits] = 1
+	for i := nbBits - 1; i >= 0; i-- {
+		if bound.Bit(i) == 0 {
+			p[i] = p[i+1]
+			// (1 - p[i+1] - b[i]) * b[i] == 0
+			l := f.api.Sub(1, p[i+1], b[i])
+			f.api.AssertIsEqual(f.api.Mul(l, b[i]), 0)
+		} else {
+			p[i] = f.api.Mul(p[i+1], b[i])
+		}
+	}
+}
+
+func max(a, b uint) uint {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,188 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulated
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"runtime"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+)
+
+func init() {
+	hint.Register(DivModHint)
+	hint.Register(CarryHint)
+	hint.Register(InverseHint)
+	hint.Register(DivHint)
+}
+
+// The hints used by the package, registered in the hint registry.
+//
+// The number of limbs of their results depends on the emulated field, which is given
+// as leading inputs. For the solver to allocate the results from the number of inputs
+// only, each hint has as many outputs as inputs; the unused outputs are set to 0
+// (see Field.hint).
+var (
+	// DivModHint computes the quotient and the remainder of the division of x by p.
+	// Inputs: nbBits, nbQuotientLimbs, nbRemainderLimbs, nbLimbs, p limbs, x limbs.
+	// Outputs: quotient limbs, remainder limbs.
+	DivModHint hint.Function = variadicHint{divModHint}
+
+	// CarryHint computes the carries of the limb-wise difference between left and right,
+	// both representing the same integer, shifted by 2^offsetBits.
+	// Inputs: nbBits, offsetBits, nbLeftLimbs, left limbs, right limbs.
+	// Outputs: the nbLimbs-1 shifted carries, nbLimbs being the largest of the two.
+	CarryHint hint.Function = variadicHint{carryHint}
+
+	// InverseHint computes the inverse of x modulo p.
+	// Inputs: nbBits, nbLimbs, p limbs, x limbs.
+	// Outputs: inverse limbs.
+	InverseHint hint.Function = variadicHint{inverseHint}
+
+	// DivHint computes x/y modulo p.
+	// Inputs: nbBits, nbLimbs, p limbs, nbXLimbs, x limbs, y limbs.
+	// Outputs: quotient limbs.
+	DivHint hint.Function = variadicHint{divHint}
+)
+
+// variadicHint is a hint function with as many outputs as inputs
+type variadicHint struct {
+	fn hint.StaticFunction
+}
+
+func (h variadicHint) UUID() hint.ID {
+	return hint.UUID(h.fn)
+}
+
+func (h variadicHint) Call(curveID ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	for i := range res {
+		res[i].SetUint64(0)
+	}
+	return h.fn(curveID, inputs, res)
+}
+
+func (h variadicHint) NbOutputs(_ ecc.ID, nbInputs int) int {
+	return nbInputs
+}
+
+func (h variadicHint) String() string {
+	fnptr := reflect.ValueOf(h.fn).Pointer()
+	name := runtime.FuncForPC(fnptr).Name()
+	return fmt.Sprintf("%s(variadic) at (%x)", name, fnptr)
+}
+
+func divModHint(_ ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	nbBits := uint(inputs[0].Uint64())
+	nbQ, nbR, nbLimbs := int(inputs[1].Uint64()), int(inputs[2].Uint64()), int(inputs[3].Uint64())
+	var p, x big.Int
+	recompose(inputs[4:4+nbLimbs], nbBits, &p)
+	recompose(inputs[4+nbLimbs:], nbBits, &x)
+	if p.Sign() == 0 {
+		return errors.New("modulus is zero")
+	}
+
+	var q, r big.Int
+	q.QuoRem(&x, &p, &r)
+	if err := decompose(&q, nbBits, res[:nbQ]); err != nil {
+		return err
+	}
+	return decompose(&r, nbBits, res[nbQ:nbQ+nbR])
+}
+
+func carryHint(_ ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	nbBits := uint(inputs[0].Uint64())
+	offset := new(big.Int).Lsh(big.NewInt(1), uint(inputs[1].Uint64()))
+	nbLeft := int(inputs[2].Uint64())
+	left, right := inputs[3:3+nbLeft], inputs[3+nbLeft:]
+	nbLimbs := len(left)
+	if len(right) > nbLimbs {
+		nbLimbs = len(right)
+	}
+
+	var carry, d big.Int
+	for k := 0; k < nbLimbs-1; k++ {
+		d.Set(&carry)
+		if k < len(left) {
+			d.Add(&d, left[k])
+		}
+		if k < len(right) {
+			d.Sub(&d, right[k])
+		}
+		// the division must be exact (arithmetic shift on negative values)
+		carry.Rsh(&d, nbBits)
+		res[k].Add(&carry, offset)
+	}
+	return nil
+}
+
+func inverseHint(_ ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	nbBits := uint(inputs[0].Uint64())
+	nbLimbs := int(inputs[1].Uint64())
+	var p, x big.Int
+	recompose(inputs[2:2+nbLimbs], nbBits, &p)
+	recompose(inputs[2+nbLimbs:], nbBits, &x)
+	if x.ModInverse(&x, &p) == nil {
+		return errors.New("element is not invertible")
+	}
+	return decompose(&x, nbBits, res[:nbLimbs])
+}
+
+func divHint(_ ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	nbBits := uint(inputs[0].Uint64())
+	nbLimbs := int(inputs[1].Uint64())
+	nbX := int(inputs[2+nbLimbs].Uint64())
+	var p, x, y big.Int
+	recompose(inputs[2:2+nbLimbs], nbBits, &p)
+	recompose(inputs[3+nbLimbs:3+nbLimbs+nbX], nbBits, &x)
+	recompose(inputs[3+nbLimbs+nbX:], nbBits, &y)
+	if y.ModInverse(&y, &p) == nil {
+		return errors.New("element is not invertible")
+	}
+	x.Mul(&x, &y).Mod(&x, &p)
+	return decompose(&x, nbBits, res[:nbLimbs])
+}
+
+// recompose sets res to Σ limbs[i]·2^(nbBits·i); limbs may exceed nbBits bits
+func recompose(limbs []*big.Int, nbBits uint, res *big.Int) {
+	res.SetUint64(0)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		res.Lsh(res, nbBits)
+		res.Add(res, limbs[i])
+	}
+}
+
+// decompose sets res to the limbs of x, which must be non-negative and fit in len(res) limbs of nbBits bits
+func decompose(x *big.Int, nbBits uint, res []*big.Int) error {
+	if x.Sign() < 0 || uint(x.BitLen()) > uint(len(res))*nbBits {
+		return errors.New("value doesn't fit in the limbs")
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), nbBits)
+	mask.Sub(mask, big.NewInt(1))
+	tmp := new(big.Int).Set(x)
+	for i := 0; i < len(res); i++ {
+		if res[i] == nil {
+			res[i] = new(big.Int)
+		}
+		res[i].And(tmp, mask)
+		tmp.Rsh(tmp, nbBits)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emulated
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// Params defines an emulated field: its modulus, and the representation of its
+// elements as NbLimbs limbs of NbBits bits each (little endian).
+type Params struct {
+	modulus *big.Int
+	nbLimbs uint
+	nbBits  uint
+}
+
+// NewParams returns the parameters of the emulated field of the given modulus,
+// with elements represented by nbLimbs limbs of nbBits bits
+func NewParams(modulus *big.Int, nbLimbs, nbBits uint) (*Params, error) {
+	if modulus.Sign() <= 0 || modulus.Cmp(big.NewInt(2)) < 0 {
+		return nil, errors.New("modulus must be greater than 1")
+	}
+	if nbLimbs == 0 || nbBits == 0 {
+		return nil, errors.New("number of limbs and bits per limb must be positive")
+	}
+	if uint(modulus.BitLen()) > nbLimbs*nbBits {
+		return nil, errors.New("modulus doesn't fit in the limbs")
+	}
+	return &Params{
+		modulus: new(big.Int).Set(modulus),
+		nbLimbs: nbLimbs,
+		nbBits:  nbBits,
+	}, nil
+}
+
+func mustParams(modulus string, nbLimbs, nbBits uint) *Params {
+	m, ok := new(big.Int).SetString(modulus, 16)
+	if !ok {
+		panic("invalid modulus")
+	}
+	p, err := NewParams(m, nbLimbs, nbBits)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Secp256k1Fp returns the parameters of the base field of secp256k1
+func Secp256k1Fp() *Params {
+	return mustParams("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 4, 64)
+}
+
+// Secp256k1Fr returns the parameters of the scalar field of secp256k1
+func Secp256k1Fr() *Params {
+	return mustParams("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 4, 64)
+}
+
+// BN254Fp returns the parameters of the base field of BN254
+func BN254Fp() *Params {
+	return mustParams("30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47", 4, 64)
+}
+
+// BN254Fr returns the parameters of the scalar field of BN254
+func BN254Fr() *Params {
+	return mustParams("30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001", 4, 64)
+}
+
+// P256Fp returns the parameters of the base field of P-256
+func P256Fp() *Params {
+	return mustParams("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 4, 64)
+}
+
+// P256Fr returns the parameters of the scalar field of P-256
+func P256Fr() *Params {
+	return mustParams("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 4, 64)
+}
+
+// Modulus returns the modulus of the emulated field
+func (p *Params) Modulus() *big.Int {
+	return new(big.Int).Set(p.modulus)
+}
+
+// NbLimbs returns the number of limbs of an element
+func (p *Params) NbLimbs() uint {
+	return p.nbLimbs
+}
+
+// NbBits returns the number of bits per limb
+func (p *Params) NbBits() uint {
+	return p.nbBits
+}
+
+// Placeholder returns an element with allocated limbs, to be used in the circuit
+// definition given to frontend.Compile
+func (p *Params) Placeholder() Element {
+	return Element{Limbs: make([]frontend.Variable, p.nbLimbs)}
+}
+
+// ValueOf returns the element representing v (reduced modulo the emulated modulus),
+// to be used in a witness assignment. v must be convertible to *big.Int.
+func (p *Params) ValueOf(v interface{}) Element {
+	limbs := p.limbs(v)
+	e := Element{Limbs: make([]frontend.Variable, len(limbs))}
+	for i := range limbs {
+		e.Limbs[i] = limbs[i]
+	}
+	return e
+}
+
+// limbs returns the limbs of v mod p
+func (p *Params) limbs(v interface{}) []*big.Int {
+	b := utils.FromInterface(v)
+	b.Mod(&b, p.modulus)
+	res := make([]*big.Int, p.nbLimbs)
+	decompose(&b, p.nbBits, res)
+	return res
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import "github.com/consensys/gnark/frontend"
+
+// U32 is a 32-bit unsigned integer, as little-endian bits
+type U32 [32]frontend.Variable
+
+// NewU32 returns the constant v as a U32
+func NewU32(v uint32) U32 {
+	var res U32
+	copy(res[:], constant(uint64(v), 32))
+	return res
+}
+
+// Unpack sets z to the bits of v, which must be in [0, 2^32), and returns z
+func (z *U32) Unpack(api frontend.API, v frontend.Variable) *U32 {
+	copy(z[:], unpack(api, v, 32))
+	return z
+}
+
+// Pack returns x as a field element
+func (x U32) Pack(api frontend.API) frontend.Variable {
+	return pack(api, x[:])
+}
+
+// FromBytesBE sets z to the word whose big-endian bytes are b, and returns z.
+// Each byte must be in [0, 256).
+func (z *U32) FromBytesBE(api frontend.API, b [4]frontend.Variable) *U32 {
+	copy(z[:], fromBytes(api, b[:], true))
+	return z
+}
+
+// FromBytesLE sets z to the word whose little-endian bytes are b, and returns z.
+// Each byte must be in [0, 256).
+func (z *U32) FromBytesLE(api frontend.API, b [4]frontend.Variable) *U32 {
+	copy(z[:], fromBytes(api, b[:], false))
+	return z
+}
+
+// BytesBE returns the big-endian bytes of x
+func (x U32) BytesBE(api frontend.API) [4]frontend.Variable {
+	var res [4]frontend.Variable
+	copy(res[:], toBytes(api, x[:], true))
+	return res
+}
+
+// BytesLE returns the little-endian bytes of x
+func (x U32) BytesLE(api frontend.API) [4]frontend.Variable {
+	var res [4]frontend.Variable
+	copy(res[:], toBytes(api, x[:], false))
+	return res
+}
+
+// Add sets z to the sum of a, b and in modulo 2^32, and returns z
+func (z *U32) Add(api frontend.API, a, b U32, in ...U32) *U32 {
+	copy(z[:], add(api, 32, words32(a, b, in)...))
+	return z
+}
+
+// Xor sets z to a ^ b ^ in[0] ^ in[1] ^ ..., and returns z
+func (z *U32) Xor(api frontend.API, a, b U32, in ...U32) *U32 {
+	copy(z[:], bitwise(api, xorBit, words32(a, b, in)...))
+	return z
+}
+
+// And sets z to a & b & in[0] & in[1] & ..., and returns z
+func (z *U32) And(api frontend.API, a, b U32, in ...U32) *U32 {
+	copy(z[:], bitwise(api, andBit, words32(a, b, in)...))
+	return z
+}
+
+// Or sets z to a | b | in[0] | in[1] | ..., and returns z
+func (z *U32) Or(api frontend.API, a, b U32, in ...U32) *U32 {
+	copy(z[:], bitwise(api, orBit, words32(a, b, in)...))
+	return z
+}
+
+// Not sets z to ^a and returns z
+func (z *U32) Not(api frontend.API, a U32) *U32 {
+	copy(z[:], not(api, a[:]))
+	return z
+}
+
+// Rotl sets z to a rotated left by n bits and returns z. A negative n rotates right.
+func (z *U32) Rotl(a U32, n int) *U32 {
+	copy(z[:], rotl(a[:], n))
+	return z
+}
+
+// Rotr sets z to a rotated right by n bits and returns z. A negative n rotates left.
+func (z *U32) Rotr(a U32, n int) *U32 {
+	copy(z[:], rotl(a[:], -n))
+	return z
+}
+
+// Shl sets z to a << n and returns z
+func (z *U32) Shl(a U32, n int) *U32 {
+	copy(z[:], shl(a[:], n))
+	return z
+}
+
+// Shr sets z to a >> n and returns z
+func (z *U32) Shr(a U32, n int) *U32 {
+	copy(z[:], shr(a[:], n))
+	return z
+}
+
+// AssertIsEqual fails if x != y
+func (x U32) AssertIsEqual(api frontend.API, y U32) {
+	assertIsEqual(api, x[:], y[:])
+}
+
+func words32(a, b U32, in []U32) [][]frontend.Variable {
+	res := make([][]frontend.Variable, 0, 2+len(in))
+	res = append(res, a[:], b[:])
+	for i := range in {
+		res = append(res, in[i][:])
+	}
+	return res
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import "github.com/consensys/gnark/frontend"
+
+// U64 is a 64-bit unsigned integer, as little-endian bits
+type U64 [64]frontend.Variable
+
+// NewU64 returns the constant v as a U64
+func NewU64(v uint64) U64 {
+	var res U64
+	copy(res[:], constant(uint64(v), 64))
+	return res
+}
+
+// Unpack sets z to the bits of v, which must be in [0, 2^64), and returns z
+func (z *U64) Unpack(api frontend.API, v frontend.Variable) *U64 {
+	copy(z[:], unpack(api, v, 64))
+	return z
+}
+
+// Pack returns x as a field element
+func (x U64) Pack(api frontend.API) frontend.Variable {
+	return pack(api, x[:])
+}
+
+// FromBytesBE sets z to the word whose big-endian bytes are b, and returns z.
+// Each byte must be in [0, 256).
+func (z *U64) FromBytesBE(api frontend.API, b [8]frontend.Variable) *U64 {
+	copy(z[:], fromBytes(api, b[:], true))
+	return z
+}
+
+// FromBytesLE sets z to the word whose little-endian bytes are b, and returns z.
+// Each byte must be in [0, 256).
+func (z *U64) FromBytesLE(api frontend.API, b [8]frontend.Variable) *U64 {
+	copy(z[:], fromBytes(api, b[:], false))
+	return z
+}
+
+// BytesBE returns the big-endian bytes of x
+func (x U64) BytesBE(api frontend.API) [8]frontend.Variable {
+	var res [8]frontend.Variable
+	copy(res[:], toBytes(api, x[:], true))
+	return res
+}
+
+// BytesLE returns the little-endian bytes of x
+func (x U64) BytesLE(api frontend.API) [8]frontend.Variable {
+	var res [8]frontend.Variable
+	copy(res[:], toBytes(api, x[:], false))
+	return res
+}
+
+// Add sets z to the sum of a, b and in modulo 2^64, and returns z
+func (z *U64) Add(api frontend.API, a, b U64, in ...U64) *U64 {
+	copy(z[:], add(api, 64, words64(a, b, in)...))
+	return z
+}
+
+// Xor sets z to a ^ b ^ in[0] ^ in[1] ^ ..., and returns z
+func (z *U64) Xor(api frontend.API, a, b U64, in ...U64) *U64 {
+	copy(z[:], bitwise(api, xorBit, words64(a, b, in)...))
+	return z
+}
+
+// And sets z to a & b & in[0] & in[1] & ..., and returns z
+func (z *U64) And(api frontend.API, a, b U64, in ...U64) *U64 {
+	copy(z[:], bitwise(api, andBit, words64(a, b, in)...))
+	return z
+}
+
+// Or sets z to a | b | in[0] | in[1] | ..., and returns z
+func (z *U64) Or(api frontend.API, a, b U64, in ...U64) *U64 {
+	copy(z[:], bitwise(api, orBit, words64(a, b, in)...))
+	return z
+}
+
+// Not sets z to ^a and returns z
+func (z *U64) Not(api frontend.API, a U64) *U64 {
+	copy(z[:], not(api, a[:]))
+	return z
+}
+
+// Rotl sets z to a rotated left by n bits and returns z. A negative n rotates right.
+func (z *U64) Rotl(a U64, n int) *U64 {
+	copy(z[:], rotl(a[:], n))
+	return z
+}
+
+// Rotr sets z to a rotated right by n bits and returns z. A negative n rotates left.
+func (z *U64) Rotr(a U64, n int) *U64 {
+	copy(z[:], rotl(a[:], -n))
+	return z
+}
+
+// Shl sets z to a << n and returns z
+func (z *U64) Shl(a U64, n int) *U64 {
+	copy(z[:], shl(a[:], n))
+	return z
+}
+
+// Shr sets z to a >> n and returns z
+func (z *U64) Shr(a U64, n int) *U64 {
+	copy(z[:], shr(a[:], n))
+	return z
+}
+
+// AssertIsEqual fails if x != y
+func (x U64) AssertIsEqual(api frontend.API, y U64) {
+	assertIsEqual(api, x[:], y[:])
+}
+
+func words64(a, b U64, in []U64) [][]frontend.Variable {
+	res := make([][]frontend.Variable, 0, 2+len(in))
+	res = append(res, a[:], b[:])
+	for i := range in {
+		res = append(res, in[i][:])
+	}
+	return res
+}
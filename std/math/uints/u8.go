@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import "github.com/consensys/gnark/frontend"
+
+// U8 is a 8-bit unsigned integer, as little-endian bits
+type U8 [8]frontend.Variable
+
+// NewU8 returns the constant v as a U8
+func NewU8(v uint8) U8 {
+	var res U8
+	copy(res[:], constant(uint64(v), 8))
+	return res
+}
+
+// Unpack sets z to the bits of v, which must be in [0, 2^8), and returns z
+func (z *U8) Unpack(api frontend.API, v frontend.Variable) *U8 {
+	copy(z[:], unpack(api, v, 8))
+	return z
+}
+
+// Pack returns x as a field element
+func (x U8) Pack(api frontend.API) frontend.Variable {
+	return pack(api, x[:])
+}
+
+// Add sets z to the sum of a, b and in modulo 2^8, and returns z
+func (z *U8) Add(api frontend.API, a, b U8, in ...U8) *U8 {
+	copy(z[:], add(api, 8, words8(a, b, in)...))
+	return z
+}
+
+// Xor sets z to a ^ b ^ in[0] ^ in[1] ^ ..., and returns z
+func (z *U8) Xor(api frontend.API, a, b U8, in ...U8) *U8 {
+	copy(z[:], bitwise(api, xorBit, words8(a, b, in)...))
+	return z
+}
+
+// And sets z to a & b & in[0] & in[1] & ..., and returns z
+func (z *U8) And(api frontend.API, a, b U8, in ...U8) *U8 {
+	copy(z[:], bitwise(api, andBit, words8(a, b, in)...))
+	return z
+}
+
+// Or sets z to a | b | in[0] | in[1] | ..., and returns z
+func (z *U8) Or(api frontend.API, a, b U8, in ...U8) *U8 {
+	copy(z[:], bitwise(api, orBit, words8(a, b, in)...))
+	return z
+}
+
+// Not sets z to ^a and returns z
+func (z *U8) Not(api frontend.API, a U8) *U8 {
+	copy(z[:], not(api, a[:]))
+	return z
+}
+
+// Rotl sets z to a rotated left by n bits and returns z. A negative n rotates right.
+func (z *U8) Rotl(a U8, n int) *U8 {
+	copy(z[:], rotl(a[:], n))
+	return z
+}
+
+// Rotr sets z to a rotated right by n bits and returns z. A negative n rotates left.
+func (z *U8) Rotr(a U8, n int) *U8 {
+	copy(z[:], rotl(a[:], -n))
+	return z
+}
+
+// Shl sets z to a << n and returns z
+func (z *U8) Shl(a U8, n int) *U8 {
+	copy(z[:], shl(a[:], n))
+	return z
+}
+
+// Shr sets z to a >> n and returns z
+func (z *U8) Shr(a U8, n int) *U8 {
+	copy(z[:], shr(a[:], n))
+	return z
+}
+
+// AssertIsEqual fails if x != y
+func (x U8) AssertIsEqual(api frontend.API, y U8) {
+	assertIsEqual(api, x[:], y[:])
+}
+
+func words8(a, b U8, in []U8) [][]frontend.Variable {
+	res := make([][]frontend.Variable, 0, 2+len(in))
+	res = append(res, a[:], b[:])
+	for i := range in {
+		res = append(res, in[i][:])
+	}
+	return res
+}
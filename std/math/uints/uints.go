@@ -0,0 +1,234 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package uints provides fixed-size unsigned integers (U8, U32 and U64) for gnark
+// circuits, with the wraparound arithmetic and bitwise operations used by hash
+// functions such as SHA-256, Keccak or Blake2.
+//
+// A word is stored as its little-endian bits, so that rotations and shifts are free
+// and bitwise operations cost at most one constraint per bit. Additions modulo 2^n are
+// done on the packed words (api.FromBinary) and reduced by decomposing the result,
+// which costs n + log2(number of terms) + 1 constraints.
+//
+// The operations don't add constraints on constant bits, so that mixing words with
+// constants (e.g. round constants) is cheap.
+//
+// A word can be obtained from a constant with NewU8, NewU32 and NewU64, from a
+// field element with Unpack, or from bytes with FromBytesBE and FromBytesLE; these
+// ensure that the bits are boolean. Setting the bits of a word directly, e.g. from
+// the witness, doesn't constrain them.
+//
+// Like the field extensions of std/algebra, the operations set their receiver and
+// return it, so that calls can be chained. The receiver may alias the arguments, but
+// a temporary must not be used twice in the arguments of a call, e.g.
+// z.Xor(api, *t.Rotr(a, 2), *t.Rotr(a, 13)) is wrong.
+package uints
+
+import (
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// constant returns the width lowest bits of v
+func constant(v uint64, width int) []frontend.Variable {
+	res := make([]frontend.Variable, width)
+	for i := 0; i < width; i++ {
+		res[i] = (v >> i) & 1
+	}
+	return res
+}
+
+// unpack returns the width bits of v, which must be in [0, 2^width)
+func unpack(api frontend.API, v frontend.Variable, width int) []frontend.Variable {
+	if api.IsConstant(v) {
+		if api.ConstantValue(v).BitLen() > width {
+			panic("constant doesn't fit in the word")
+		}
+	}
+	return api.ToBinary(v, width)
+}
+
+// pack returns the field element whose little-endian bits are x
+func pack(api frontend.API, x []frontend.Variable) frontend.Variable {
+	return api.FromBinary(x...)
+}
+
+// add returns the sum of in modulo 2^width
+func add(api frontend.API, width int, in ...[]frontend.Variable) []frontend.Variable {
+	if width+bits.Len(uint(len(in)-1)) >= api.Curve().Info().Fr.Bits {
+		panic("too many terms in the addition")
+	}
+	packed := make([]frontend.Variable, len(in))
+	for i := range in {
+		packed[i] = pack(api, in[i])
+	}
+	var s frontend.Variable
+	if len(packed) == 1 {
+		s = packed[0]
+	} else {
+		s = api.Add(packed[0], packed[1], packed[2:]...)
+	}
+	if api.IsConstant(s) {
+		var r big.Int
+		r.SetUint64(1).Lsh(&r, uint(width)).Sub(&r, big.NewInt(1)).And(&r, api.ConstantValue(s))
+		return api.ToBinary(&r, width)
+	}
+	// the sum is less than len(in) * 2^width, the carry being ignored
+	return api.ToBinary(s, width+bits.Len(uint(len(in)-1)))[:width]
+}
+
+// bitwise applies op bit by bit to the words of in
+func bitwise(api frontend.API, op func(frontend.API, frontend.Variable, frontend.Variable) frontend.Variable, in ...[]frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(in[0]))
+	copy(res, in[0])
+	for _, x := range in[1:] {
+		for i := range res {
+			res[i] = op(api, res[i], x[i])
+		}
+	}
+	return res
+}
+
+// not returns the complement of x
+func not(api frontend.API, x []frontend.Variable) []frontend.Variable {
+	res := make([]frontend.Variable, len(x))
+	for i := range x {
+		res[i] = api.Sub(1, x[i])
+	}
+	return res
+}
+
+// rotl returns x rotated left by n bits, n being taken modulo len(x)
+func rotl(x []frontend.Variable, n int) []frontend.Variable {
+	width := len(x)
+	n %= width
+	if n < 0 {
+		n += width
+	}
+	res := make([]frontend.Variable, width)
+	for i := 0; i < width; i++ {
+		res[(i+n)%width] = x[i]
+	}
+	return res
+}
+
+// shl returns x shifted left by n bits, n being non negative
+func shl(x []frontend.Variable, n int) []frontend.Variable {
+	if n < 0 {
+		panic("negative shift amount")
+	}
+	res := make([]frontend.Variable, len(x))
+	for i := range res {
+		if i >= n {
+			res[i] = x[i-n]
+		} else {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+// shr returns x shifted right by n bits, n being non negative
+func shr(x []frontend.Variable, n int) []frontend.Variable {
+	if n < 0 {
+		panic("negative shift amount")
+	}
+	res := make([]frontend.Variable, len(x))
+	for i := range res {
+		if i+n < len(x) {
+			res[i] = x[i+n]
+		} else {
+			res[i] = 0
+		}
+	}
+	return res
+}
+
+// fromBytes returns the bits of the word whose bytes are b, in big-endian order if
+// bigEndian is set. Each byte must be in [0, 256).
+func fromBytes(api frontend.API, b []frontend.Variable, bigEndian bool) []frontend.Variable {
+	res := make([]frontend.Variable, 8*len(b))
+	for i := range b {
+		j := i
+		if bigEndian {
+			j = len(b) - 1 - i
+		}
+		copy(res[8*j:8*(j+1)], unpack(api, b[i], 8))
+	}
+	return res
+}
+
+// toBytes returns the bytes of x, in big-endian order if bigEndian is set
+func toBytes(api frontend.API, x []frontend.Variable, bigEndian bool) []frontend.Variable {
+	res := make([]frontend.Variable, len(x)/8)
+	for i := range res {
+		j := i
+		if bigEndian {
+			j = len(res) - 1 - i
+		}
+		res[i] = pack(api, x[8*j:8*(j+1)])
+	}
+	return res
+}
+
+// assertIsEqual asserts that the words x and y are equal
+func assertIsEqual(api frontend.API, x, y []frontend.Variable) {
+	api.AssertIsEqual(pack(api, x), pack(api, y))
+}
+
+// xorBit returns a ^ b, without adding constraints if one of the bits is a constant
+func xorBit(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if api.IsConstant(a) {
+		a, b = b, a
+	}
+	if api.IsConstant(b) {
+		if api.ConstantValue(b).Sign() == 0 {
+			return a
+		}
+		return api.Sub(1, a)
+	}
+	return api.Xor(a, b)
+}
+
+// andBit returns a & b, without adding constraints if one of the bits is a constant
+func andBit(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if api.IsConstant(a) {
+		a, b = b, a
+	}
+	if api.IsConstant(b) {
+		if api.ConstantValue(b).Sign() == 0 {
+			return 0
+		}
+		return a
+	}
+	return api.And(a, b)
+}
+
+// orBit returns a | b, without adding constraints if one of the bits is a constant
+func orBit(api frontend.API, a, b frontend.Variable) frontend.Variable {
+	if api.IsConstant(a) {
+		a, b = b, a
+	}
+	if api.IsConstant(b) {
+		if api.ConstantValue(b).Sign() == 0 {
+			return a
+		}
+		return 1
+	}
+	return api.Or(a, b)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uints
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const k32 = 0x428a2f98
+
+type u32Circuit struct {
+	A, B, C frontend.Variable
+	Bytes   [4]frontend.Variable
+	Res     frontend.Variable `gnark:",public"`
+}
+
+// Define computes (a + b + c + k32) ^ rotr(a, 7) ^ (b >> 3), then
+// ((res & ^c) | (a << 5)) + bytes
+func (circuit *u32Circuit) Define(api frontend.API) error {
+	var a, b, c, t, u, v U32
+	a.Unpack(api, circuit.A)
+	b.Unpack(api, circuit.B)
+	c.Unpack(api, circuit.C)
+
+	t.Add(api, a, b, c, NewU32(k32))
+	t.Xor(api, t, *u.Rotr(a, 7), *v.Shr(b, 3))
+	t.And(api, t, *u.Not(api, c))
+	t.Or(api, t, *u.Shl(a, 5))
+
+	var w U32
+	w.FromBytesBE(api, circuit.Bytes)
+	t.Add(api, t, w)
+
+	api.AssertIsEqual(t.Pack(api), circuit.Res)
+
+	// the bytes round trip
+	bytes := w.BytesBE(api)
+	for i := range bytes {
+		api.AssertIsEqual(bytes[i], circuit.Bytes[i])
+	}
+	return nil
+}
+
+func u32Witness(a, b, c uint32, bytes [4]byte) u32Circuit {
+	t := (a + b + c + k32) ^ bits.RotateLeft32(a, -7) ^ (b >> 3)
+	t = (t &^ c) | (a << 5)
+	t += uint32(bytes[0])<<24 | uint32(bytes[1])<<16 | uint32(bytes[2])<<8 | uint32(bytes[3])
+
+	witness := u32Circuit{A: a, B: b, C: c, Res: t}
+	for i := range bytes {
+		witness.Bytes[i] = bytes[i]
+	}
+	return witness
+}
+
+func TestU32(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for i := 0; i < 5; i++ {
+		var bytes [4]byte
+		rand.Read(bytes[:])
+		witness := u32Witness(rand.Uint32(), rand.Uint32(), rand.Uint32(), bytes)
+		assert.ProverSucceeded(&u32Circuit{}, &witness, test.WithCurves(ecc.BN254))
+	}
+
+	witness := u32Witness(0xffffffff, 0xffffffff, 0, [4]byte{0xff, 0xff, 0xff, 0xff})
+	assert.ProverSucceeded(&u32Circuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// wrong result
+	witness.Res = 0
+	assert.ProverFailed(&u32Circuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// input out of range
+	witness = u32Witness(1, 2, 3, [4]byte{})
+	witness.A = uint64(1) << 32
+	assert.ProverFailed(&u32Circuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// byte out of range
+	witness = u32Witness(1, 2, 3, [4]byte{})
+	witness.Bytes[3] = 256
+	witness.Res = witness.Res.(uint32) + 256
+	assert.ProverFailed(&u32Circuit{}, &witness, test.WithCurves(ecc.BN254))
+}
+
+type u64Circuit struct {
+	Bytes [8]frontend.Variable
+	B     frontend.Variable
+	Res   [8]frontend.Variable `gnark:",public"`
+}
+
+// Define computes rotl(a, 44) ^ (^b & rotl(b, -3)) + b, a being read from little-endian bytes
+func (circuit *u64Circuit) Define(api frontend.API) error {
+	var a, b, t, u U64
+	a.FromBytesLE(api, circuit.Bytes)
+	b.Unpack(api, circuit.B)
+
+	t.And(api, *t.Not(api, b), *u.Rotl(b, -3))
+	t.Xor(api, *u.Rotl(a, 44), t)
+	t.Add(api, t, b)
+
+	res := t.BytesLE(api)
+	for i := range res {
+		api.AssertIsEqual(res[i], circuit.Res[i])
+	}
+	return nil
+}
+
+func TestU64(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for i := 0; i < 5; i++ {
+		a, b := rand.Uint64(), rand.Uint64()
+		res := bits.RotateLeft64(a, 44) ^ (^b & bits.RotateLeft64(b, -3))
+		res += b
+
+		var witness u64Circuit
+		witness.B = b
+		for j := 0; j < 8; j++ {
+			witness.Bytes[j] = (a >> (8 * j)) & 0xff
+			witness.Res[j] = (res >> (8 * j)) & 0xff
+		}
+		assert.ProverSucceeded(&u64Circuit{}, &witness, test.WithCurves(ecc.BN254))
+
+		witness.Res[0] = ((res & 0xff) + 1) & 0xff
+		assert.ProverFailed(&u64Circuit{}, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+type u8Circuit struct {
+	A, B frontend.Variable
+	Res  frontend.Variable `gnark:",public"`
+}
+
+// Define computes (a + b + 200) ^ rotl(a, 3)
+func (circuit *u8Circuit) Define(api frontend.API) error {
+	var a, b, t, u U8
+	a.Unpack(api, circuit.A)
+	b.Unpack(api, circuit.B)
+	t.Add(api, a, b, NewU8(200))
+	t.Xor(api, t, *u.Rotl(a, 3))
+	t.AssertIsEqual(api, *u.Unpack(api, circuit.Res))
+	return nil
+}
+
+func TestU8(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	a, b := uint8(0xf1), uint8(0x7e)
+	res := (a + b + 200) ^ bits.RotateLeft8(a, 3)
+	assert.ProverSucceeded(&u8Circuit{}, &u8Circuit{A: a, B: b, Res: res}, test.WithCurves(ecc.BN254))
+	assert.ProverFailed(&u8Circuit{}, &u8Circuit{A: a, B: b, Res: res + 1}, test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bls provides a ZKP-circuit function to verify BLS12-377 BLS signatures
+// inside a BW6-761 circuit.
+//
+// Public keys are in G1 and signatures in G2: a signature S of a message hashed
+// to the point H(m) of G2 is valid for the public key A if e(A, H(m)) = e(G, S),
+// G being the generator of G1. The signatures of a same message aggregate as the
+// sum of the signatures, verified with the sum of the public keys.
+package bls
+
+import (
+	"errors"
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls12377"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+)
+
+// PublicKey stores a BLS public key [sk]G (to be used in gnark circuit)
+type PublicKey struct {
+	A sw_bls12377.G1Affine
+}
+
+// Signature stores a BLS signature [sk]H(m) (to be used in gnark circuit)
+type Signature struct {
+	S sw_bls12377.G2Affine
+}
+
+// Verify verifies a BLS signature of the message hashed to msg.
+//
+// The public key is asserted to be on the curve, and the signature to be in G2. msg must be a
+// point of G2 (e.g. computed with bls12377.HashToCurveG2Svdw), and the subgroup membership of
+// the public key is left to the caller, as part of the proof-of-possession of the key.
+func Verify(api frontend.API, pairingInfo sw_bls12377.PairingContext, sig Signature, msg sw_bls12377.G2Affine, pubKey PublicKey) error {
+	assertIsOnCurve(api, pubKey.A)
+	assertIsInG2(api, sig.S, pairingInfo)
+	return verify(api, pairingInfo, sig, msg, pubKey)
+}
+
+// VerifyAggregate verifies an aggregated BLS signature (the sum of the signatures) of
+// the message hashed to msg, by all the pubKeys.
+//
+// Summing the public keys is open to rogue-key attacks: each key must come with a
+// proof-of-possession of its secret key, checked by the caller (which also ensures it is in G1).
+// The keys are added with incomplete formulas, so no key may be equal to, or the opposite of,
+// the sum of the previous ones; this is asserted.
+func VerifyAggregate(api frontend.API, pairingInfo sw_bls12377.PairingContext, sig Signature, msg sw_bls12377.G2Affine, pubKeys []PublicKey) error {
+	if len(pubKeys) == 0 {
+		return errors.New("no public key to aggregate")
+	}
+	assertIsInG2(api, sig.S, pairingInfo)
+	for i := 0; i < len(pubKeys); i++ {
+		assertIsOnCurve(api, pubKeys[i].A)
+	}
+	aggregated := pubKeys[0]
+	for i := 1; i < len(pubKeys); i++ {
+		// the slope of the addition is (y₁-y₀)/(x₁-x₀)
+		api.AssertIsDifferent(aggregated.A.X, pubKeys[i].A.X)
+		aggregated.A.AddAssign(api, pubKeys[i].A)
+	}
+	return verify(api, pairingInfo, sig, msg, aggregated)
+}
+
+// verify checks e(A, H(m)) == e(G, S)
+func verify(api frontend.API, pairingInfo sw_bls12377.PairingContext, sig Signature, msg sw_bls12377.G2Affine, pubKey PublicKey) error {
+
+	// -G
+	_, _, g1, _ := bls12377.Generators()
+	var g1Neg sw_bls12377.G1Affine
+	g1Neg.Assign(&g1)
+	g1Neg.Neg(api, g1Neg)
+
+	// e(A, H(m))·e(-G, S) == 1
+	var ml1, ml2 fields_bls12377.E12
+	sw_bls12377.MillerLoop(api, pubKey.A, msg, &ml1, pairingInfo)
+	sw_bls12377.MillerLoop(api, g1Neg, sig.S, &ml2, pairingInfo)
+	ml1.Mul(api, ml1, ml2, pairingInfo.Extension)
+
+	var res, one fields_bls12377.E12
+	res.FinalExponentiation(api, ml1, pairingInfo.AteLoop, pairingInfo.Extension)
+	one.SetOne(api)
+	res.MustBeEqual(api, one)
+
+	return nil
+}
+
+// assertIsOnCurve asserts y² == x³ + 1
+func assertIsOnCurve(api frontend.API, p sw_bls12377.G1Affine) {
+	api.AssertIsEqual(api.Mul(p.Y, p.Y), api.Add(api.Mul(p.X, p.X, p.X), 1))
+}
+
+// assertIsInG2 asserts p is on the twist, y² == x³ + b', and in G2: ψ(p) == [x₀]p, x₀ being the
+// seed of BLS12-377 (see https://eprint.iacr.org/2021/1130)
+func assertIsInG2(api frontend.API, p sw_bls12377.G2Affine, pairingInfo sw_bls12377.PairingContext) {
+	ext := pairingInfo.Extension
+
+	var y2, x3 fields_bls12377.E2
+	y2.Square(api, p.Y, ext)
+	x3.Square(api, p.X, ext).Mul(api, x3, p.X, ext).Add(api, x3, pairingInfo.BTwistCoeff)
+	y2.MustBeEqual(api, x3)
+
+	// [x₀]p, by double and add. The formulas divide by 2y and by the difference of the x
+	// coordinates, which are asserted non-zero; this holds for p in G2, of prime order r > x₀.
+	res := p
+	for i := seed.BitLen() - 2; i >= 0; i-- {
+		assertIsNonZero(api, res.Y)
+		res.Double(api, &res, ext)
+		if seed.Bit(i) == 1 {
+			var dx fields_bls12377.E2
+			dx.Sub(api, res.X, p.X)
+			assertIsNonZero(api, dx)
+			res.AddAssign(api, &p, ext)
+		}
+	}
+
+	// ψ(x, y) = (u·conj(x), v·conj(y))
+	var psi sw_bls12377.G2Affine
+	psi.X.Conjugate(api, p.X).MulByFp(api, psi.X, psiU)
+	psi.Y.Conjugate(api, p.Y).MulByFp(api, psi.Y, psiV)
+	psi.MustBeEqual(api, res)
+}
+
+// assertIsNonZero asserts e ≠ 0, e being in Fp2 = Fp[u]/(u²+5): its norm e₀² + 5e₁² is non-zero
+func assertIsNonZero(api frontend.API, e fields_bls12377.E2) {
+	api.AssertIsDifferent(api.Add(api.Mul(e.A0, e.A0), api.Mul(5, e.A1, e.A1)), 0)
+}
+
+var (
+	// seed x₀ of BLS12-377
+	seed, _ = new(big.Int).SetString("9586122913090633729", 10)
+
+	// coefficients of the endomorphism ψ on G2
+	psiU = "80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410946"
+	psiV = "216465761340224619389371505802605247630151569547285782856803747159100223055385581585702401816380679166954762214499"
+)
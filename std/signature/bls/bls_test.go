@@ -0,0 +1,264 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls12377"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+	"github.com/consensys/gnark/test"
+)
+
+const nbValidators = 3
+
+type aggregateCircuit struct {
+	PublicKeys [nbValidators]PublicKey `gnark:",public"`
+	Message    sw_bls12377.G2Affine    `gnark:",public"`
+	Signature  Signature
+}
+
+func (circuit *aggregateCircuit) Define(api frontend.API) error {
+	return VerifyAggregate(api, newPairingContext(api), circuit.Signature, circuit.Message, circuit.PublicKeys[:])
+}
+
+func newPairingContext(api frontend.API) sw_bls12377.PairingContext {
+	ateLoop := uint64(9586122913090633729)
+	ext := fields_bls12377.GetBLS12377ExtensionFp12(api)
+	pairingInfo := sw_bls12377.PairingContext{AteLoop: ateLoop, Extension: ext}
+	pairingInfo.BTwistCoeff.A0 = 0
+	pairingInfo.BTwistCoeff.A1 = "155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906"
+	return pairingInfo
+}
+
+func TestVerifyAggregate(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	msg, err := bls12377.HashToCurveG2Svdw([]byte("block 42"), []byte("BLS_SIG_BLS12377G2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, g1, _ := bls12377.Generators()
+
+	// each validator signs the message, the signatures are added
+	var witness aggregateCircuit
+	var aggregated, lastSig bls12377.G2Jac
+	for i := 0; i < nbValidators; i++ {
+		var sk fr.Element
+		var skBig big.Int
+		if _, err := sk.SetRandom(); err != nil {
+			t.Fatal(err)
+		}
+		sk.ToBigIntRegular(&skBig)
+
+		var pub bls12377.G1Affine
+		pub.ScalarMultiplication(&g1, &skBig)
+		witness.PublicKeys[i].A.Assign(&pub)
+
+		var sig bls12377.G2Jac
+		sig.FromAffine(&msg)
+		sig.ScalarMultiplication(&sig, &skBig)
+		aggregated.AddAssign(&sig)
+		lastSig = sig
+	}
+	var sig bls12377.G2Affine
+	sig.FromJacobian(&aggregated)
+	witness.Signature.S.Assign(&sig)
+	witness.Message.Assign(&msg)
+
+	assert.SolvingSucceeded(&aggregateCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// signature of another message
+	other, err := bls12377.HashToCurveG2Svdw([]byte("block 43"), []byte("BLS_SIG_BLS12377G2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness.Message.Assign(&other)
+	assert.SolvingFailed(&aggregateCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// a missing signature
+	witness.Message.Assign(&msg)
+	witness.PublicKeys[0] = witness.PublicKeys[1]
+	witness.PublicKeys[1].A.Assign(&g1)
+	assert.SolvingFailed(&aggregateCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// a public key repeated, with the matching signature: the keys can't be added
+	witness.PublicKeys[0] = witness.PublicKeys[2]
+	witness.PublicKeys[1] = witness.PublicKeys[2]
+	lastSig.ScalarMultiplication(&lastSig, big.NewInt(nbValidators))
+	sig.FromJacobian(&lastSig)
+	witness.Signature.S.Assign(&sig)
+	assert.SolvingFailed(&aggregateCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
+
+type signatureCircuit struct {
+	PublicKey PublicKey            `gnark:",public"`
+	Message   sw_bls12377.G2Affine `gnark:",public"`
+	Signature Signature
+}
+
+func (circuit *signatureCircuit) Define(api frontend.API) error {
+	return Verify(api, newPairingContext(api), circuit.Signature, circuit.Message, circuit.PublicKey)
+}
+
+func TestVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	msg, err := bls12377.HashToCurveG2Svdw([]byte("block 42"), []byte("BLS_SIG_BLS12377G2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, g1, _ := bls12377.Generators()
+
+	var sk fr.Element
+	var skBig big.Int
+	if _, err := sk.SetRandom(); err != nil {
+		t.Fatal(err)
+	}
+	sk.ToBigIntRegular(&skBig)
+	var pub bls12377.G1Affine
+	var sig bls12377.G2Affine
+	pub.ScalarMultiplication(&g1, &skBig)
+	sig.ScalarMultiplication(&msg, &skBig)
+
+	var witness signatureCircuit
+	witness.PublicKey.A.Assign(&pub)
+	witness.Message.Assign(&msg)
+	witness.Signature.S.Assign(&sig)
+	assert.SolvingSucceeded(&signatureCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// signature off the curve
+	offCurve := sig
+	offCurve.Y.A0.SetOne()
+	if offCurve.IsOnCurve() {
+		t.Fatal("expected a point off the curve")
+	}
+	witness.Signature.S.Assign(&offCurve)
+	assert.SolvingFailed(&signatureCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// public key off the curve
+	witness.Signature.S.Assign(&sig)
+	offCurveKey := pub
+	offCurveKey.Y.SetOne()
+	witness.PublicKey.A.Assign(&offCurveKey)
+	assert.SolvingFailed(&signatureCircuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
+
+type g1Circuit struct {
+	P sw_bls12377.G1Affine
+}
+
+func (circuit *g1Circuit) Define(api frontend.API) error {
+	assertIsOnCurve(api, circuit.P)
+	return nil
+}
+
+func TestAssertIsOnCurve(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, g1, _ := bls12377.Generators()
+	var witness g1Circuit
+	witness.P.Assign(&g1)
+	assert.SolvingSucceeded(&g1Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	offCurve := g1
+	offCurve.Y.SetOne()
+	witness.P.Assign(&offCurve)
+	assert.SolvingFailed(&g1Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
+
+type g2Circuit struct {
+	P sw_bls12377.G2Affine
+}
+
+func (circuit *g2Circuit) Define(api frontend.API) error {
+	assertIsInG2(api, circuit.P, newPairingContext(api))
+	return nil
+}
+
+func TestAssertIsInG2(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2 := bls12377.Generators()
+	var p bls12377.G2Affine
+	p.ScalarMultiplication(&g2, big.NewInt(42))
+	var witness g2Circuit
+	witness.P.Assign(&p)
+	assert.SolvingSucceeded(&g2Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// off the curve
+	offCurve := p
+	offCurve.Y.A0.SetOne()
+	witness.P.Assign(&offCurve)
+	assert.SolvingFailed(&g2Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// on the curve, out of G2
+	witness.P.Assign(notInG2(t))
+	assert.SolvingFailed(&g2Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// p + T, T being in the cofactor subgroup
+	var torsion, q bls12377.G2Jac
+	q.FromAffine(notInG2(t))
+	r := fr.Modulus()
+	for i := r.BitLen() - 1; i >= 0; i-- {
+		// ScalarMultiplication reduces the scalar modulo r
+		torsion.DoubleAssign()
+		if r.Bit(i) == 1 {
+			torsion.AddAssign(&q)
+		}
+	}
+	q.FromAffine(&p)
+	q.AddAssign(&torsion)
+	var outOfG2 bls12377.G2Affine
+	outOfG2.FromJacobian(&q)
+	if !outOfG2.IsOnCurve() || outOfG2.IsInSubGroup() {
+		t.Fatal("expected a point on the curve, out of G2")
+	}
+	witness.P.Assign(&outOfG2)
+	assert.SolvingFailed(&g2Circuit{}, &witness, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+}
+
+// notInG2 returns a point of the twist which is not in G2
+func notInG2(t *testing.T) *bls12377.G2Affine {
+	_, _, _, g2 := bls12377.Generators()
+	var b bls12377.E2
+	var res bls12377.G2Affine
+	var y2 bls12377.E2
+	// b' = y² - x³ for the generator of G2
+	b.Square(&g2.Y)
+	y2.Square(&g2.X).Mul(&y2, &g2.X)
+	b.Sub(&b, &y2)
+	for i := uint64(1); i < 100; i++ {
+		res.X.A0.SetUint64(i)
+		y2.Square(&res.X).Mul(&y2, &res.X).Add(&y2, &b)
+		if y2.Legendre() != 1 {
+			continue
+		}
+		res.Y.Sqrt(&y2)
+		if res.IsOnCurve() && !res.IsInSubGroup() {
+			return &res
+		}
+	}
+	t.Fatal("no point found")
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// Curve is a short Weierstrass curve y² = x³ + A·x + B over Fp, with a
+// generator (Gx, Gy) of prime order, the modulus of Fr.
+type Curve struct {
+	Fp, Fr *emulated.Params
+	A, B   *big.Int
+	Gx, Gy *big.Int
+}
+
+// Secp256k1 returns the parameters of secp256k1
+func Secp256k1() Curve {
+	gx, _ := new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	gy, _ := new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	return Curve{
+		Fp: emulated.Secp256k1Fp(),
+		Fr: emulated.Secp256k1Fr(),
+		A:  big.NewInt(0),
+		B:  big.NewInt(7),
+		Gx: gx,
+		Gy: gy,
+	}
+}
+
+// P256 returns the parameters of P-256 (secp256r1)
+func P256() Curve {
+	params := elliptic.P256().Params()
+	return Curve{
+		Fp: emulated.P256Fp(),
+		Fr: emulated.P256Fr(),
+		A:  new(big.Int).Sub(params.P, big.NewInt(3)),
+		B:  new(big.Int).Set(params.B),
+		Gx: new(big.Int).Set(params.Gx),
+		Gy: new(big.Int).Set(params.Gy),
+	}
+}
+
+// affine is a point of the curve outside of a circuit; the point at infinity is (nil, nil)
+type affine struct {
+	x, y *big.Int
+}
+
+func (c Curve) isInfinity(p affine) bool {
+	return p.x == nil
+}
+
+func (c Curve) add(p1, p2 affine) affine {
+	if c.isInfinity(p1) {
+		return p2
+	}
+	if c.isInfinity(p2) {
+		return p1
+	}
+	p := c.Fp.Modulus()
+	var lambda, t big.Int
+	if p1.x.Cmp(p2.x) == 0 {
+		if t.Add(p1.y, p2.y).Mod(&t, p).Sign() == 0 {
+			return affine{}
+		}
+		// λ = (3x² + a)/(2y)
+		lambda.Mul(p1.x, p1.x).Mul(&lambda, big.NewInt(3)).Add(&lambda, c.A)
+		t.Lsh(p1.y, 1).ModInverse(&t, p)
+	} else {
+		// λ = (y2 - y1)/(x2 - x1)
+		lambda.Sub(p2.y, p1.y)
+		t.Sub(p2.x, p1.x).Mod(&t, p).ModInverse(&t, p)
+	}
+	lambda.Mul(&lambda, &t).Mod(&lambda, p)
+
+	x := new(big.Int).Mul(&lambda, &lambda)
+	x.Sub(x, p1.x).Sub(x, p2.x).Mod(x, p)
+	y := new(big.Int).Sub(p1.x, x)
+	y.Mul(y, &lambda).Sub(y, p1.y).Mod(y, p)
+	return affine{x, y}
+}
+
+func (c Curve) neg(p1 affine) affine {
+	if c.isInfinity(p1) {
+		return p1
+	}
+	y := new(big.Int).Neg(p1.y)
+	return affine{new(big.Int).Set(p1.x), y.Mod(y, c.Fp.Modulus())}
+}
+
+func (c Curve) scalarMul(p1 affine, s *big.Int) affine {
+	var res affine
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		res = c.add(res, res)
+		if s.Bit(i) == 1 {
+			res = c.add(res, p1)
+		}
+	}
+	return res
+}
+
+// offsetPoint returns a point of unknown discrete logarithm, used to avoid the
+// exceptional cases of the incomplete addition formulas in the circuit
+func (c Curve) offsetPoint() affine {
+	p := c.Fp.Modulus()
+	var rhs big.Int
+	for counter := byte(0); ; counter++ {
+		h := sha256.Sum256([]byte{'g', 'n', 'a', 'r', 'k', '-', 'e', 'c', 'd', 's', 'a', counter})
+		x := new(big.Int).SetBytes(h[:])
+		x.Mod(x, p)
+		rhs.Mul(x, x).Add(&rhs, c.A).Mul(&rhs, x).Add(&rhs, c.B).Mod(&rhs, p)
+		if y := new(big.Int).ModSqrt(&rhs, p); y != nil {
+			return affine{x, y}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ecdsa provides a ZKP-circuit function to verify an ECDSA signature
+// over secp256k1 or P-256.
+//
+// The arithmetic of the curves is emulated with std/math/emulated, so the
+// signatures can be verified in a circuit over any (large enough) field.
+package ecdsa
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// PublicKey stores an ecdsa public key (to be used in gnark circuit)
+type PublicKey struct {
+	X, Y  emulated.Element
+	Curve Curve
+}
+
+// Signature stores a signature (to be used in gnark circuit)
+type Signature struct {
+	R, S emulated.Element
+}
+
+// NewPublicKey returns a public key on curve with allocated limbs, to be used
+// in the circuit definition given to frontend.Compile
+func NewPublicKey(curve Curve) PublicKey {
+	return PublicKey{X: curve.Fp.Placeholder(), Y: curve.Fp.Placeholder(), Curve: curve}
+}
+
+// NewSignature returns a signature on curve with allocated limbs, to be used
+// in the circuit definition given to frontend.Compile
+func NewSignature(curve Curve) Signature {
+	return Signature{R: curve.Fr.Placeholder(), S: curve.Fr.Placeholder()}
+}
+
+// Assign sets the public key to (x, y), to be used in a witness assignment
+func (pubKey *PublicKey) Assign(curve Curve, x, y *big.Int) {
+	pubKey.X = curve.Fp.ValueOf(x)
+	pubKey.Y = curve.Fp.ValueOf(y)
+	pubKey.Curve = curve
+}
+
+// Assign sets the signature to (r, s), to be used in a witness assignment
+func (sig *Signature) Assign(curve Curve, r, s *big.Int) {
+	sig.R = curve.Fr.ValueOf(r)
+	sig.S = curve.Fr.ValueOf(s)
+}
+
+// Verify verifies an ecdsa signature of msgHash, the hashed message converted
+// to an element of Fr (see HashToInt)
+// cf https://en.wikipedia.org/wiki/Elliptic_Curve_Digital_Signature_Algorithm
+func Verify(api frontend.API, sig Signature, msgHash emulated.Element, pubKey PublicKey) error {
+	c, err := newCurveAPI(api, pubKey.Curve)
+	if err != nil {
+		return err
+	}
+	fr, err := emulated.NewField(api, pubKey.Curve.Fr)
+	if err != nil {
+		return err
+	}
+
+	q := point{&pubKey.X, &pubKey.Y}
+	c.assertIsOnCurve(q)
+
+	// r and s must be non zero; Inverse fails otherwise
+	fr.Inverse(&sig.R)
+	sInv := fr.Inverse(&sig.S)
+
+	// u1 = H(m)/s, u2 = r/s
+	u1 := fr.ToBits(fr.Mul(&msgHash, sInv))
+	u2 := fr.ToBits(fr.Mul(&sig.R, sInv))
+
+	// R = [u1]G + [u2]Q, with Shamir's trick. The addition formulas are incomplete,
+	// so each step adds an offset point D of unknown discrete logarithm.
+	curve := pubKey.Curve
+	d := curve.offsetPoint()
+	g := affine{curve.Gx, curve.Gy}
+	dPoint := c.constant(d)
+	gd := c.constant(curve.add(g, d))
+	qd := c.add(q, dPoint)
+	gqd := c.add(qd, c.constant(g))
+
+	acc := dPoint
+	for i := len(u1) - 1; i >= 0; i-- {
+		acc = c.double(acc)
+		acc = c.add(acc, c.lookup2(u1[i], u2[i], dPoint, gd, qd, gqd))
+	}
+
+	// acc = [u1]G + [u2]Q + [2^(n+1) - 1]D, where n = len(u1)
+	k := new(big.Int).Lsh(big.NewInt(1), uint(len(u1)+1))
+	k.Sub(k, big.NewInt(1))
+	acc = c.add(acc, c.constant(curve.neg(curve.scalarMul(d, k))))
+
+	// x(R) mod n == r
+	x := c.fp.ToBits(acc.x)
+	fr.AssertIsEqual(fr.FromBits(x), &sig.R)
+
+	return nil
+}
+
+// point is a point of the curve in a circuit, in affine coordinates
+type point struct {
+	x, y *emulated.Element
+}
+
+// curveAPI performs the operations of the curve in a circuit
+type curveAPI struct {
+	fp    *emulated.Field
+	curve Curve
+}
+
+func newCurveAPI(api frontend.API, curve Curve) (*curveAPI, error) {
+	fp, err := emulated.NewField(api, curve.Fp)
+	if err != nil {
+		return nil, err
+	}
+	return &curveAPI{fp: fp, curve: curve}, nil
+}
+
+func (c *curveAPI) constant(p affine) point {
+	return point{c.fp.Constant(p.x), c.fp.Constant(p.y)}
+}
+
+// assertIsOnCurve fails if y² != x³ + a·x + b
+func (c *curveAPI) assertIsOnCurve(p point) {
+	x2 := c.fp.Mul(p.x, p.x)
+	rhs := c.fp.Mul(c.fp.Add(x2, c.fp.Constant(c.curve.A)), p.x)
+	rhs = c.fp.Add(rhs, c.fp.Constant(c.curve.B))
+	c.fp.AssertIsEqual(c.fp.Mul(p.y, p.y), rhs)
+}
+
+// add returns p1+p2. The points must have different x coordinates, the
+// constraint system is not satisfiable otherwise.
+func (c *curveAPI) add(p1, p2 point) point {
+	// λ = (y2 - y1)/(x2 - x1); Inverse ensures x2 != x1
+	inv := c.fp.Inverse(c.fp.Sub(p2.x, p1.x))
+	lambda := c.fp.Mul(c.fp.Sub(p2.y, p1.y), inv)
+	return c.fromSlope(lambda, p1, p2.x)
+}
+
+// double returns 2p. p.y must be non zero, the constraint system is not
+// satisfiable otherwise.
+func (c *curveAPI) double(p point) point {
+	// λ = (3x² + a)/(2y)
+	x2 := c.fp.Mul(p.x, p.x)
+	num := c.fp.Add(c.fp.Add(x2, x2), c.fp.Add(x2, c.fp.Constant(c.curve.A)))
+	inv := c.fp.Inverse(c.fp.Add(p.y, p.y))
+	lambda := c.fp.Mul(num, inv)
+	return c.fromSlope(lambda, p, p.x)
+}
+
+// fromSlope returns the third point of the line of slope lambda through p1 and a
+// point of x coordinate x2, negated
+func (c *curveAPI) fromSlope(lambda *emulated.Element, p1 point, x2 *emulated.Element) point {
+	// x3 = λ² - x1 - x2
+	x3 := c.fp.Sub(c.fp.Mul(lambda, lambda), c.fp.Add(p1.x, x2))
+	// y3 = λ(x1 - x3) - y1
+	y3 := c.fp.Sub(c.fp.Mul(lambda, c.fp.Sub(p1.x, x3)), p1.y)
+	return point{x3, y3}
+}
+
+func (c *curveAPI) lookup2(b0, b1 frontend.Variable, p0, p1, p2, p3 point) point {
+	return point{
+		c.fp.Lookup2(b0, b1, p0.x, p1.x, p2.x, p3.x),
+		c.fp.Lookup2(b0, b1, p0.y, p1.y, p2.y, p3.y),
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecdsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type ecdsaCircuit struct {
+	PublicKey PublicKey `gnark:",public"`
+	Signature Signature
+	MsgHash   emulated.Element `gnark:",public"`
+}
+
+func (circuit *ecdsaCircuit) Define(api frontend.API) error {
+	return Verify(api, circuit.Signature, circuit.MsgHash, circuit.PublicKey)
+}
+
+func newCircuit(curve Curve) *ecdsaCircuit {
+	return &ecdsaCircuit{
+		PublicKey: NewPublicKey(curve),
+		Signature: NewSignature(curve),
+		MsgHash:   curve.Fr.Placeholder(),
+	}
+}
+
+func newWitness(curve Curve, x, y, r, s *big.Int, hash []byte) *ecdsaCircuit {
+	var witness ecdsaCircuit
+	witness.PublicKey.Assign(curve, x, y)
+	witness.Signature.Assign(curve, r, s)
+	witness.MsgHash = curve.Fr.ValueOf(HashToInt(curve, hash))
+	return &witness
+}
+
+func TestSign(t *testing.T) {
+	// signatures of the native signer must be accepted by crypto/ecdsa
+	curve := P256()
+	privKey, err := GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("testing ECDSA"))
+	r, s, err := privKey.Sign(hash[:], rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := ecdsa.PublicKey{Curve: elliptic.P256(), X: privKey.X, Y: privKey.Y}
+	if !ecdsa.Verify(&pubKey, hash[:], r, s) {
+		t.Fatal("signature rejected by crypto/ecdsa")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for name, curve := range map[string]Curve{"secp256k1": Secp256k1(), "p256": P256()} {
+		curve := curve
+		t.Run(name, func(t *testing.T) {
+			privKey, err := GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash := sha256.Sum256([]byte("testing ECDSA"))
+			r, s, err := privKey.Sign(hash[:], rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			witness := newWitness(curve, privKey.X, privKey.Y, r, s, hash[:])
+			if err := test.IsSolved(newCircuit(curve), witness, ecc.BN254, backend.UNKNOWN); err != nil {
+				t.Fatal(err)
+			}
+
+			// wrong message
+			wrongHash := sha256.Sum256([]byte("testing ECDSA!"))
+			witness = newWitness(curve, privKey.X, privKey.Y, r, s, wrongHash[:])
+			if err := test.IsSolved(newCircuit(curve), witness, ecc.BN254, backend.UNKNOWN); err == nil {
+				t.Fatal("signature of another message accepted")
+			}
+
+			// wrong public key
+			other, err := GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			witness = newWitness(curve, other.X, other.Y, r, s, hash[:])
+			if err := test.IsSolved(newCircuit(curve), witness, ecc.BN254, backend.UNKNOWN); err == nil {
+				t.Fatal("signature accepted with another public key")
+			}
+		})
+	}
+}
+
+func TestVerifySolving(t *testing.T) {
+	// the verification is about 3.6M constraints on BN254 with groth16 (3593402 for
+	// secp256k1), and the compilation is checked to be deterministic by compiling twice
+	if testing.Short() {
+		t.Skip("skipping the compilation of the ECDSA verification")
+	}
+
+	// run the emulated arithmetic under the R1CS solver, not only the test engine
+	curve := Secp256k1()
+	assert := test.NewAssert(t)
+
+	privKey, err := GenerateKey(curve, rand.Reader)
+	assert.NoError(err)
+	hash := sha256.Sum256([]byte("testing ECDSA"))
+	r, s, err := privKey.Sign(hash[:], rand.Reader)
+	assert.NoError(err)
+
+	opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16)}
+	assert.SolvingSucceeded(newCircuit(curve), newWitness(curve, privKey.X, privKey.Y, r, s, hash[:]), opts...)
+
+	wrongHash := sha256.Sum256([]byte("testing ECDSA!"))
+	assert.SolvingFailed(newCircuit(curve), newWitness(curve, privKey.X, privKey.Y, r, s, wrongHash[:]), opts...)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecdsa
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// PrivateKey is an ECDSA private key, used outside of a circuit to produce signatures
+// (for instance to build test witnesses)
+type PrivateKey struct {
+	Curve Curve
+	D     *big.Int // secret scalar
+	X, Y  *big.Int // public key [D]G
+}
+
+// GenerateKey returns a new private key on curve, reading randomness from r
+func GenerateKey(curve Curve, r io.Reader) (*PrivateKey, error) {
+	d, err := randomScalar(curve, r)
+	if err != nil {
+		return nil, err
+	}
+	pub := curve.scalarMul(affine{curve.Gx, curve.Gy}, d)
+	return &PrivateKey{Curve: curve, D: d, X: pub.x, Y: pub.y}, nil
+}
+
+// Sign returns the signature (r, s) of the digest hash, reading randomness from rand
+func (privKey *PrivateKey) Sign(hash []byte, rand io.Reader) (r, s *big.Int, err error) {
+	curve := privKey.Curve
+	n := curve.Fr.Modulus()
+	e := HashToInt(curve, hash)
+	for {
+		k, err := randomScalar(curve, rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		// r = x([k]G) mod n
+		p := curve.scalarMul(affine{curve.Gx, curve.Gy}, k)
+		r = new(big.Int).Mod(p.x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		// s = (e + r·d)/k mod n
+		s = new(big.Int).Mul(r, privKey.D)
+		s.Add(s, e)
+		s.Mul(s, k.ModInverse(k, n)).Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// HashToInt converts a digest to the integer signed by ECDSA: its leftmost bits,
+// as many as the bit length of the order of the curve
+func HashToInt(curve Curve, hash []byte) *big.Int {
+	orderBits := curve.Fr.Modulus().BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	res := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		res.Rsh(res, uint(excess))
+	}
+	return res
+}
+
+// randomScalar returns a uniform scalar in [1, n-1]
+func randomScalar(curve Curve, r io.Reader) (*big.Int, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	max := new(big.Int).Sub(curve.Fr.Modulus(), big.NewInt(1))
+	k, err := rand.Int(r, max)
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
@@ -20,6 +20,7 @@ package eddsa
 import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/std/hash/mimc"
 )
 
@@ -40,9 +41,24 @@ type Signature struct {
 	S frontend.Variable
 }
 
-// Verify verifies an eddsa signature
+// Verify verifies an eddsa signature, using MiMC with the seed "seed" as hash function
+// (the configuration of gnark-crypto's eddsa signer with hash.MIMC_XXX.New("seed"))
 // cf https://en.wikipedia.org/wiki/EdDSA
 func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey PublicKey) error {
+	hFunc, err := mimc.NewMiMC("seed", api)
+	if err != nil {
+		return err
+	}
+	return VerifyWithHash(api, sig, []frontend.Variable{msg}, pubKey, &hFunc)
+}
+
+// VerifyWithHash verifies an eddsa signature of a message made of one or more
+// field elements, using hFunc to compute H(R, A, M). hFunc is reset first.
+//
+// With gnark-crypto's eddsa signer, the signed message is the concatenation of the
+// (big endian, fr-sized) encodings of the elements of msg, and the hash function must
+// match hFunc.
+func VerifyWithHash(api frontend.API, sig Signature, msg []frontend.Variable, pubKey PublicKey, hFunc hash.Hash) error {
 
 	// compute H(R, A, M), all parameters in data are in Montgomery form
 	data := []frontend.Variable{
@@ -50,16 +66,12 @@ func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey Publi
 		sig.R.Y,
 		pubKey.A.X,
 		pubKey.A.Y,
-		msg,
 	}
+	data = append(data, msg...)
 
-	hash, err := mimc.NewMiMC("seed", api)
-	if err != nil {
-		return err
-	}
-	hash.Write(data...)
-	//hramConstant := hash.Sum(data...)
-	hramConstant := hash.Sum()
+	hFunc.Reset()
+	hFunc.Write(data...)
+	hramConstant := hFunc.Sum()
 
 	// lhs = [S]G
 	cofactor := pubKey.Curve.Cofactor.Uint64()
@@ -28,6 +28,7 @@ import (
 	eddsabls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards/eddsa"
 	edwardsbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
 	eddsabls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	edwardsbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
@@ -38,6 +39,7 @@ import (
 	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/test"
 )
 
@@ -252,3 +254,70 @@ func TestEddsa(t *testing.T) {
 
 	}
 }
+
+type eddsaWithHashCircuit struct {
+	PublicKey PublicKey            `gnark:",public"`
+	Signature Signature            `gnark:",public"`
+	Message   [2]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *eddsaWithHashCircuit) Define(api frontend.API) error {
+
+	params, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+	circuit.PublicKey.Curve = params
+
+	hFunc, err := mimc.NewMiMC("other seed", api)
+	if err != nil {
+		return err
+	}
+	return VerifyWithHash(api, circuit.Signature, circuit.Message[:], circuit.PublicKey, &hFunc)
+}
+
+func TestEddsaWithHash(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	// sign a message of 2 field elements, with a MiMC of another seed
+	hFunc := hash.MIMC_BN254.New("other seed")
+	privKey, err := eddsabn254.GenerateKey(rand.New(rand.NewSource(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [2]fr.Element
+	msg[0].SetString("44717650746155748460101257525078853138837311576962212923649547644148297035978")
+	msg[1].SetUint64(42)
+	m0, m1 := msg[0].Bytes(), msg[1].Bytes()
+	msgBin := append(m0[:], m1[:]...)
+
+	signature, err := privKey.Sign(msgBin, hFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var witness eddsaWithHashCircuit
+	witness.Message[0] = msg[0].String()
+	witness.Message[1] = msg[1].String()
+	pubkeyAx, pubkeyAy := parsePoint(ecc.BN254, privKey.Public().Bytes())
+	witness.PublicKey.A.X = pubkeyAx
+	witness.PublicKey.A.Y = pubkeyAy
+	sigRx, sigRy, sigS := parseSignature(ecc.BN254, signature)
+	witness.Signature.R.X = sigRx
+	witness.Signature.R.Y = sigRy
+	witness.Signature.S = sigS
+
+	assert.SolvingSucceeded(&eddsaWithHashCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// the last element of the message is signed
+	witness.Message[1] = 43
+	assert.SolvingFailed(&eddsaWithHashCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// the signature doesn't verify with the default configuration
+	var defaultWitness eddsaCircuit
+	defaultWitness.Message = msg[0].String()
+	defaultWitness.PublicKey = witness.PublicKey
+	defaultWitness.Signature = witness.Signature
+	assert.SolvingFailed(&eddsaCircuit{}, &defaultWitness, test.WithCurves(ecc.BN254))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schnorr provides a ZKP-circuit function to verify a Schnorr signature
+// over the twisted Edwards curve whose base field is the field of the circuit.
+package schnorr
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// PublicKey stores a schnorr public key A = [sk]G (to be used in gnark circuit)
+type PublicKey struct {
+	A     twistededwards.Point
+	Curve twistededwards.EdCurve
+}
+
+// Signature stores a signature (to be used in gnark circuit)
+// A Schnorr signature is a tuple (E, S) where E = H(R, A, M) is the challenge of the
+// commitment R = [k]G, and S = k + E·sk mod l, l being the order of G.
+type Signature struct {
+	E, S frontend.Variable
+}
+
+// Verify verifies a schnorr signature of a message made of one or more field
+// elements, using hFunc to compute the challenge H(R, A, M). hFunc is reset first.
+//
+// The commitment R is recovered as [S]G - [E]A, and its challenge must be E.
+// cf https://en.wikipedia.org/wiki/Schnorr_signature
+func Verify(api frontend.API, sig Signature, msg []frontend.Variable, pubKey PublicKey, hFunc hash.Hash) error {
+	curve := pubKey.Curve
+	pubKey.A.MustBeOnCurve(api, curve)
+
+	// R = [S]G - [E]A
+	var r, eA twistededwards.Point
+	r.ScalarMulFixedBase(api, curve.BaseX, curve.BaseY, sig.S, curve)
+	eA.ScalarMulNonFixedBase(api, &pubKey.A, sig.E, curve)
+	eA.Neg(api, &eA)
+	r.AddGeneric(api, &r, &eA, curve)
+
+	// H(R, A, M) == E
+	hFunc.Reset()
+	hFunc.Write(r.X, r.Y, pubKey.A.X, pubKey.A.Y)
+	hFunc.Write(msg...)
+	api.AssertIsEqual(hFunc.Sum(), sig.E)
+
+	return nil
+}
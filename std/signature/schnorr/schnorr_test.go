@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schnorr
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+type schnorrCircuit struct {
+	PublicKey PublicKey `gnark:",public"`
+	Signature Signature `gnark:",public"`
+	Message   frontend.Variable
+}
+
+func (circuit *schnorrCircuit) Define(api frontend.API) error {
+
+	params, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+	circuit.PublicKey.Curve = params
+
+	hFunc, err := mimc.NewMiMC("seed", api)
+	if err != nil {
+		return err
+	}
+	return Verify(api, circuit.Signature, []frontend.Variable{circuit.Message}, circuit.PublicKey, &hFunc)
+}
+
+// sign signs msg with sk on the twisted Edwards curve of BN254, using MiMC(seed) as hash function
+func sign(t *testing.T, sk *big.Int, msg *fr.Element) (pub edwardsbn254.PointAffine, e, s *big.Int) {
+	curve := edwardsbn254.GetEdwardsCurve()
+	pub.ScalarMul(&curve.Base, sk)
+
+	k, err := rand.Int(rand.Reader, &curve.Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r edwardsbn254.PointAffine
+	r.ScalarMul(&curve.Base, k)
+
+	// e = H(R, A, M)
+	hFunc := hash.MIMC_BN254.New("seed")
+	for _, v := range []fr.Element{r.X, r.Y, pub.X, pub.Y, *msg} {
+		b := v.Bytes()
+		hFunc.Write(b[:])
+	}
+	e = new(big.Int).SetBytes(hFunc.Sum(nil))
+
+	// s = k + e·sk mod l
+	s = new(big.Int).Mul(e, sk)
+	s.Add(s, k).Mod(s, &curve.Order)
+	return
+}
+
+func TestSchnorr(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	curve := edwardsbn254.GetEdwardsCurve()
+	sk, err := rand.Int(rand.Reader, &curve.Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg fr.Element
+	msg.SetUint64(42)
+	pub, e, s := sign(t, sk, &msg)
+
+	var witness schnorrCircuit
+	witness.PublicKey.A.X = pub.X.String()
+	witness.PublicKey.A.Y = pub.Y.String()
+	witness.Signature.E = e
+	witness.Signature.S = s
+	witness.Message = msg.String()
+	assert.SolvingSucceeded(&schnorrCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// wrong message
+	witness.Message = 43
+	assert.SolvingFailed(&schnorrCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// wrong public key
+	var other edwardsbn254.PointAffine
+	other.Double(&pub)
+	witness.Message = msg.String()
+	witness.PublicKey.A.X = other.X.String()
+	witness.PublicKey.A.Y = other.Y.String()
+	assert.SolvingFailed(&schnorrCircuit{}, &witness, test.WithCurves(ecc.BN254))
+}
@@ -155,6 +155,24 @@ func (e *engine) Inverse(i1 frontend.Variable) frontend.Variable {
 	return b1
 }
 
+// DivMod returns the quotient and the remainder of the integer division of a by b
+func (e *engine) DivMod(a, b frontend.Variable, bits int) (frontend.Variable, frontend.Variable) {
+	// same bound as the constraint system builders, where q*b + r must not overflow
+	if maxBits := (e.bitLen() - 1) / 2; bits < 0 || bits > maxBits {
+		panic(fmt.Sprintf("divMod: bits must be in [0, %d]", maxBits))
+	}
+	b1, b2 := e.toBigInt(a), e.toBigInt(b)
+	if b1.BitLen() > bits || b2.BitLen() > bits {
+		panic(fmt.Sprintf("[divMod] %s or %s >= 2^%d", b1.String(), b2.String(), bits))
+	}
+	if b2.Sign() == 0 {
+		panic("[divMod] division by zero")
+	}
+	var q, r big.Int
+	q.DivMod(&b1, &b2, &r)
+	return q, r
+}
+
 func (e *engine) ToBinary(i1 frontend.Variable, n ...int) []frontend.Variable {
 	nbBits := e.bitLen()
 	if len(n) == 1 {
@@ -255,6 +273,15 @@ func (e *engine) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 frontend.Varia
 	return e.toBigInt([]frontend.Variable{i0, i1, i2, i3}[lookup.Uint64()])
 }
 
+// Lookup returns table[index]. index must be in [0, len(table)).
+func (e *engine) Lookup(index frontend.Variable, table []frontend.Variable) frontend.Variable {
+	i := e.toBigInt(index)
+	if !i.IsUint64() || i.Uint64() >= uint64(len(table)) {
+		panic(fmt.Sprintf("[lookup] index %s out of range [0, %d)", i.String(), len(table)))
+	}
+	return e.toBigInt(table[i.Uint64()])
+}
+
 // IsZero returns 1 if a is zero, 0 otherwise
 func (e *engine) IsZero(i1 frontend.Variable) frontend.Variable {
 	b1 := e.toBigInt(i1)
@@ -266,6 +293,14 @@ func (e *engine) IsZero(i1 frontend.Variable) frontend.Variable {
 	return (0)
 }
 
+// Cmp returns 1 if i1>i2, 0 if i1=i2, -1 if i1<i2
+func (e *engine) Cmp(i1, i2 frontend.Variable) frontend.Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	res := big.NewInt(int64(b1.Cmp(&b2)))
+	res.Mod(res, e.modulus())
+	return *res
+}
+
 func (e *engine) AssertIsEqual(i1, i2 frontend.Variable) {
 	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
 	if b1.Cmp(&b2) != 0 {
@@ -299,6 +334,17 @@ func (e *engine) AssertIsLessOrEqual(v frontend.Variable, bound frontend.Variabl
 	}
 }
 
+// RangeCheck fails if v >= 2^bits
+func (e *engine) RangeCheck(v frontend.Variable, bits int) {
+	if bits < 0 {
+		panic("[rangeCheck] invalid bit size")
+	}
+	b1 := e.toBigInt(v)
+	if b1.BitLen() > bits {
+		panic(fmt.Sprintf("[rangeCheck] %s >= 2^%d", b1.String(), bits))
+	}
+}
+
 func (e *engine) Println(a ...frontend.Variable) {
 	var sbb strings.Builder
 	sbb.WriteString("(test.engine) ")
@@ -62,3 +62,35 @@ func TestBuiltinHints(t *testing.T) {
 	}
 
 }
+
+type divModBitsCircuit struct {
+	A, B frontend.Variable
+	bits int
+}
+
+func (circuit *divModBitsCircuit) Define(api frontend.API) error {
+	api.DivMod(circuit.A, circuit.B, circuit.bits)
+	return nil
+}
+
+func TestDivModBits(t *testing.T) {
+	for _, curve := range ecc.Implemented() {
+		maxBits := (curve.Info().Fr.Bits - 1) / 2
+		for _, bits := range []int{-1, maxBits, maxBits + 1} {
+			circuit := divModBitsCircuit{bits: bits}
+			witness := divModBitsCircuit{A: 7, B: 2, bits: bits}
+
+			// the test engine and the constraint system builders must reject the same bit sizes
+			errEngine := IsSolved(&circuit, &witness, curve, backend.UNKNOWN)
+			for _, b := range backend.Implemented() {
+				_, errCompile := frontend.Compile(curve, b, &circuit)
+				if (errEngine == nil) != (errCompile == nil) {
+					t.Fatalf("%s, %s, bits=%d: engine error %v, compile error %v", curve, b, bits, errEngine, errCompile)
+				}
+			}
+			if (errEngine == nil) != (bits == maxBits) {
+				t.Fatalf("%s, bits=%d: unexpected engine error %v", curve, bits, errEngine)
+			}
+		}
+	}
+}
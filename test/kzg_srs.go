@@ -21,6 +21,7 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/frontend"
 
 	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
@@ -33,16 +34,13 @@ import (
 
 const srsCachedSize = (1 << 14) + 3
 
-// NewKZGSRS uses ccs nb variables and nb constraints to initialize a kzg srs
-// for sizes < 2^15, returns a pre-computed cached SRS
+// NewKZGSRS uses the size of the fft domain of ccs (see plonk.DomainSize) to initialize
+// a kzg srs for sizes < 2^15, returns a pre-computed cached SRS
 //
 // /!\ warning /!\: this method is here for convenience only: in production, a SRS generated through MPC should be used.
 func NewKZGSRS(ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
 
-	nbConstraints := ccs.GetNbConstraints()
-	_, _, public := ccs.GetNbVariables()
-	sizeSystem := nbConstraints + public
-	kzgSize := ecc.NextPowerOfTwo(uint64(sizeSystem)) + 3
+	kzgSize := plonk.DomainSize(ccs) + 3
 
 	if kzgSize <= srsCachedSize {
 		return getCachedSRS(ccs)
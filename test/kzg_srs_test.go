@@ -0,0 +1,78 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	cs "github.com/consensys/gnark/frontend/cs/plonk"
+)
+
+// lookupPowerCircuit asserts that X is in a lookup table and that Y = X**(n+1)
+type lookupPowerCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+
+	n int
+}
+
+func (circuit *lookupPowerCircuit) Define(api frontend.API) error {
+	l, ok := api.(cs.LookupAPI)
+	if !ok {
+		panic("the circuit must be compiled with the PlonK builder")
+	}
+	l.AssertIsInTable(l.NewLookupTable([]frontend.Variable{2}, []frontend.Variable{3}), circuit.X)
+
+	y := circuit.X
+	for i := 0; i < circuit.n; i++ {
+		y = api.Mul(y, circuit.X)
+	}
+	api.AssertIsEqual(y, circuit.Y)
+	return nil
+}
+
+// TestNewKZGSRSLookup checks that the srs fits a circuit with lookups whose number of rows
+// (constraints + public inputs) is a power of 2: the lookup argument needs an extra row,
+// which doubles the domain
+func TestNewKZGSRSLookup(t *testing.T) {
+	const nbRows = 1 << 14
+	curve := ecc.BN254
+
+	circuit := lookupPowerCircuit{}
+	ccs, err := frontend.Compile(curve, backend.PLONK, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, public := ccs.GetNbVariables()
+	circuit.n = nbRows - (ccs.GetNbConstraints() + public)
+	if ccs, err = frontend.Compile(curve, backend.PLONK, &circuit); err != nil {
+		t.Fatal(err)
+	}
+	if ccs.GetNbConstraints()+public != nbRows {
+		t.Fatalf("expected %d rows, got %d", nbRows, ccs.GetNbConstraints()+public)
+	}
+	if plonk.DomainSize(ccs) != 2*nbRows {
+		t.Fatalf("expected a domain of size %d, got %d", 2*nbRows, plonk.DomainSize(ccs))
+	}
+
+	srs, err := NewKZGSRS(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := plonk.Setup(ccs, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y := new(big.Int).Exp(big.NewInt(3), big.NewInt(int64(circuit.n+1)), curve.Info().Fr.Modulus())
+	proof, err := plonk.Prove(ccs, pk, &lookupPowerCircuit{X: 3, Y: y})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plonk.Verify(proof, vk, &lookupPowerCircuit{Y: y}); err != nil {
+		t.Fatal(err)
+	}
+}